@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/koskimas/norsu/internal/cmd"
 )
@@ -13,9 +16,46 @@ func main() {
 		log.Fatal("failed to determine working directory")
 	}
 
-	err = cmd.Run(cmd.Settings{
+	s := cmd.Settings{
 		WorkingDir: wd,
-	})
+	}
+
+	command := ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "":
+		err = cmd.Run(s)
+	case "check":
+		err = cmd.Check(s)
+	case "schema":
+		flags := flag.NewFlagSet("schema", flag.ExitOnError)
+		format := flags.String("format", string(cmd.SchemaFormatDDL), "output format: ddl or json")
+		_ = flags.Parse(os.Args[2:])
+
+		err = cmd.Schema(s, cmd.SchemaFormat(*format), os.Stdout)
+	case "diff":
+		from := cmd.Settings{
+			ExcludedMigrations: make([]string, 0),
+		}
+
+		flags := flag.NewFlagSet("diff", flag.ExitOnError)
+		fromDir := flags.String("from", wd, "directory of the old inputs, like another worktree")
+		flags.Func("exclude", "migration file left out of the old inputs (can be repeated)", func(path string) error {
+			abs, err := filepath.Abs(path)
+			from.ExcludedMigrations = append(from.ExcludedMigrations, abs)
+			return err
+		})
+		_ = flags.Parse(os.Args[2:])
+
+		if from.WorkingDir, err = filepath.Abs(*fromDir); err == nil {
+			err = cmd.Diff(from, s, os.Stdout)
+		}
+	default:
+		err = fmt.Errorf(`unknown command "%s"`, command)
+	}
 
 	if err != nil {
 		log.Fatal(err.Error())
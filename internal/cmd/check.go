@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/koskimas/norsu/internal/config"
+	"github.com/koskimas/norsu/internal/pg"
+)
+
+// Check verifies that the down migrations revert the schema. The up migration
+// of each migration and then its down migration are applied to a copy of the
+// schema, which is then compared with the schema before the migration.
+// Migrations without a down migration are skipped.
+func Check(s Settings) error {
+	config, err := config.Read(filepath.Join(s.WorkingDir, configFile))
+	if err != nil {
+		return err
+	}
+
+	db, unsupported, err := parseSchema(s, *config)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := readMigrations(s, *config)
+	if err != nil {
+		return err
+	}
+
+	problems := make([]string, 0)
+
+	for _, mig := range migrations {
+		before := db.Clone()
+
+		r, err := pg.ParseMigration(db, mig.Up)
+		if err != nil {
+			return fmt.Errorf(`failed to parse migration file "%s": %w`, mig.Path, err)
+		}
+
+		unsupported = append(unsupported, unsupportedStatements(mig.Path, r.Unsupported, config.Strict)...)
+
+		if !mig.HasDown() {
+			continue
+		}
+
+		reverted := db.Clone()
+
+		r, err = pg.ParseMigration(reverted, mig.Down)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: failed to apply the down migration: %s", mig.DownPath, err))
+			continue
+		}
+
+		unsupported = append(unsupported, unsupportedStatements(mig.DownPath, r.Unsupported, config.Strict)...)
+
+		for _, c := range pg.DiffSchemas(before, reverted) {
+			problems = append(problems, fmt.Sprintf("%s: %s", mig.DownPath, describeUnreverted(c)))
+		}
+	}
+
+	if err := checkUnsupported(unsupported, config.Strict); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in down migrations:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+// describeUnreverted describes a change between the schema before a migration
+// and the schema after its down migration.
+func describeUnreverted(c pg.SchemaChange) string {
+	switch c.Kind {
+	case pg.ChangeKindAdded:
+		return fmt.Sprintf(`%s "%s" is not dropped`, c.Object, c.Name)
+	case pg.ChangeKindRemoved:
+		return fmt.Sprintf(`%s "%s" is not restored`, c.Object, c.Name)
+	}
+
+	return fmt.Sprintf(`%s of %s "%s" is %s instead of %s`, c.Property, c.Object, c.Name, c.To, c.From)
+}
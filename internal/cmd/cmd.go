@@ -5,6 +5,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	// Import here to keep this in the go.mod file. Only
 	// the generated code actually uses this packages.
@@ -14,6 +17,7 @@ import (
 	"github.com/koskimas/norsu/internal/gen"
 	"github.com/koskimas/norsu/internal/maps"
 	"github.com/koskimas/norsu/internal/match"
+	"github.com/koskimas/norsu/internal/migration"
 	"github.com/koskimas/norsu/internal/model"
 	"github.com/koskimas/norsu/internal/model/openapi"
 	"github.com/koskimas/norsu/internal/pg"
@@ -23,6 +27,10 @@ const configFile = "norsu.yaml"
 
 type Settings struct {
 	WorkingDir string
+
+	// ExcludedMigrations holds the absolute paths of migration files that
+	// are left out. This is used to build the schema before a migration.
+	ExcludedMigrations []string
 }
 
 func Run(s Settings) error {
@@ -31,7 +39,7 @@ func Run(s Settings) error {
 		return err
 	}
 
-	db, err := parseMigrations(s, *config)
+	db, warnings, err := parseMigrations(s, *config)
 	if err != nil {
 		return err
 	}
@@ -41,7 +49,18 @@ func Run(s Settings) error {
 		return err
 	}
 
-	queries, err := parseQueries(s, *config, db)
+	// The lint warnings are checked before the queries are analyzed, since
+	// queries that use a renamed column fail the analysis.
+	scanned, err := parseQueries(s, *config, db, pg.ScanQuery)
+	if err != nil {
+		return err
+	}
+
+	if err := checkLint(warnings, scanned, config.Lint); err != nil {
+		return err
+	}
+
+	queries, err := parseQueries(s, *config, db, pg.ParseQuery)
 	if err != nil {
 		return err
 	}
@@ -50,11 +69,72 @@ func Run(s Settings) error {
 		return err
 	}
 
+	if err := omitInputs(models, queries); err != nil {
+		return err
+	}
+
 	return gen.GenerateCode(*config, s.WorkingDir, models, queries)
 }
 
-func parseMigrations(s Settings, config config.Config) (*pg.DB, error) {
+// parseMigrations applies the migrations to the schema. The lint warnings
+// of the migrations are returned so that they can be checked once the
+// queries have been parsed.
+func parseMigrations(s Settings, config config.Config) (*pg.DB, []migrationWarning, error) {
+	db, unsupported, err := parseSchema(s, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrations, err := readMigrations(s, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings := make([]migrationWarning, 0)
+
+	for _, mig := range migrations {
+		r, err := pg.ParseMigration(db, mig.Up)
+		if err != nil {
+			return nil, nil, fmt.Errorf(`failed to parse migration file "%s": %w`, mig.Path, err)
+		}
+
+		unsupported = append(unsupported, unsupportedStatements(mig.Path, r.Unsupported, config.Strict)...)
+		warnings = append(warnings, migrationWarnings(mig.Path, r.Warnings, config.Lint)...)
+	}
+
+	if err := checkUnsupported(unsupported, config.Strict); err != nil {
+		return nil, nil, err
+	}
+
+	return db, warnings, nil
+}
+
+// parseSchema creates the database that the migrations are applied to. The
+// schema dump is loaded into it if one is configured. The unsupported
+// statements of the dump are returned formatted for error messages.
+func parseSchema(s Settings, config config.Config) (*pg.DB, []string, error) {
 	db := pg.NewDB()
+	if len(config.SearchPath) > 0 {
+		db.SearchPath = config.SearchPath
+	}
+
+	if config.Schema == nil {
+		return db, nil, nil
+	}
+
+	unsupported, err := parseSchemaDump(s, *config.Schema, db, config.Strict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, unsupported, nil
+}
+
+// readMigrations reads the migrations of all `config.Migrations` entries in
+// the order they are applied. Migrations included in the schema dump are
+// left out.
+func readMigrations(s Settings, config config.Config) ([]*migration.Migration, error) {
+	all := make([]*migration.Migration, 0)
 
 	for _, m := range config.Migrations {
 		path := filepath.Join(s.WorkingDir, m.Path)
@@ -64,19 +144,86 @@ func parseMigrations(s Settings, config config.Config) (*pg.DB, error) {
 			return nil, fmt.Errorf(`failed to resolve migration files using glob "%s": %w`, m.Path, err)
 		}
 
-		for _, f := range files {
-			sql, err := os.ReadFile(f)
+		files = slices.DeleteFunc(files, func(f string) bool {
+			return slices.Contains(s.ExcludedMigrations, f)
+		})
+
+		migrations, err := migration.Read(migration.Format(m.Format), files)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.Schema != nil && len(config.Schema.Version) > 0 {
+			migrations, err = migration.After(migrations, config.Schema.Version)
 			if err != nil {
-				return nil, fmt.Errorf(`failed to read migration file "%s": %w`, f, err)
+				return nil, fmt.Errorf(`invalid schema version: %w`, err)
 			}
+		}
 
-			if err := pg.ParseMigration(db, string(sql)); err != nil {
-				return nil, err
-			}
+		all = append(all, migrations...)
+	}
+
+	return all, nil
+}
+
+func parseSchemaDump(s Settings, schema config.Schema, db *pg.DB, strict config.Strict) ([]string, error) {
+	path := filepath.Join(s.WorkingDir, schema.Path)
+
+	sql, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read schema file "%s": %w`, schema.Path, err)
+	}
+
+	unsupported, err := pg.ParseSchemaDump(db, string(sql))
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse schema file "%s": %w`, schema.Path, err)
+	}
+
+	return unsupportedStatements(path, unsupported, strict), nil
+}
+
+// checkUnsupported returns an error listing the unsupported statements
+// in strict mode.
+func checkUnsupported(unsupported []string, strict config.Strict) error {
+	if len(unsupported) == 0 || !isStrict(strict) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"found %d unsupported migration statements (add the statements that don't affect types to strict.allow in %s):\n  %s",
+		len(unsupported),
+		configFile,
+		strings.Join(unsupported, "\n  "),
+	)
+}
+
+// isStrict returns true if unsupported migration statements should be
+// reported as errors. The strict mode is on by default in CI.
+func isStrict(strict config.Strict) bool {
+	if strict.Enabled != nil {
+		return *strict.Enabled
+	}
+
+	ci, _ := strconv.ParseBool(os.Getenv("CI"))
+	return ci
+}
+
+// unsupportedStatements formats the unsupported statements of the migration
+// file `path` for error messages leaving out the statements `strict` allows.
+func unsupportedStatements(path string, statements []pg.UnsupportedStatement, strict config.Strict) []string {
+	out := make([]string, 0, len(statements))
+
+	for _, st := range statements {
+		allowed := slices.ContainsFunc(strict.Allow, func(a string) bool {
+			return strings.EqualFold(strings.Join(strings.Fields(a), " "), st.Tag)
+		})
+
+		if !allowed {
+			out = append(out, fmt.Sprintf("%s:%d: %s", path, st.Line, st.Tag))
 		}
 	}
 
-	return db, nil
+	return out
 }
 
 // readModels reads the models from files specified by `cfg.Models`. The keys of the returned
@@ -137,8 +284,35 @@ func getOpenApiFiles(s Settings, cfg config.Config) (map[string]config.Model, er
 	return paths, nil
 }
 
-func parseQueries(s Settings, cfg config.Config, db *pg.DB) ([]pg.Query, error) {
-	queries := make([]pg.Query, 0)
+// parseQueries parses the query files using `parse`.
+func parseQueries(s Settings, cfg config.Config, db *pg.DB, parse func(*pg.DB, string) (*pg.Query, error)) ([]pg.Query, error) {
+	files, err := queryFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]pg.Query, 0, len(files))
+
+	for _, f := range files {
+		sql, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to read query file "%s": %w`, f, err)
+		}
+
+		q, err := parse(db, string(sql))
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse query "%s": %w`, f, err)
+		}
+
+		queries = append(queries, *q)
+	}
+
+	return queries, nil
+}
+
+// queryFiles returns the paths of the query files of all `cfg.Queries` entries.
+func queryFiles(s Settings, cfg config.Config) ([]string, error) {
+	paths := make([]string, 0)
 
 	for _, qc := range cfg.Queries {
 		path := filepath.Join(s.WorkingDir, qc.Path)
@@ -148,40 +322,60 @@ func parseQueries(s Settings, cfg config.Config, db *pg.DB) ([]pg.Query, error)
 			return nil, fmt.Errorf(`failed to resolve sql query files using glob "%s": %w`, qc.Path, err)
 		}
 
-		for _, f := range files {
-			sql, err := os.ReadFile(f)
-			if err != nil {
-				return nil, fmt.Errorf(`failed to read query file "%s": %w`, f, err)
-			}
+		paths = append(paths, files...)
+	}
 
-			q, err := pg.ParseQuery(db, string(sql))
-			if err != nil {
-				return nil, fmt.Errorf(`failed to parse query "%s": %w`, f, err)
-			}
+	return paths, nil
+}
 
-			queries = append(queries, *q)
+func matchModelsAndQueries(models map[string]model.Model, queries []pg.Query) error {
+	for _, q := range queries {
+		if err := matchQuery(models, q); err != nil {
+			return fmt.Errorf("query %s: %w", q.Name, err)
 		}
 	}
 
-	return queries, nil
+	return nil
 }
 
-func matchModelsAndQueries(models map[string]model.Model, queries []pg.Query) error {
-	for _, q := range queries {
-		if q.In != nil {
-			im := models[q.In.Model]
+// omitInputs removes the optional inputs the input models leave out from
+// the queries.
+func omitInputs(models map[string]model.Model, queries []pg.Query) error {
+	for i := range queries {
+		q := &queries[i]
+		if q.In == nil {
+			continue
+		}
 
-			if err := match.Input(*q.In, *im.Schema); err != nil {
-				return fmt.Errorf("query %s: %w", q.Name, err)
-			}
+		omitted := match.OmittedInputs(*q.In, *models[q.In.Model].Schema)
+		if err := q.OmitInputs(omitted); err != nil {
+			return fmt.Errorf("query %s: %w", q.Name, err)
 		}
+	}
 
-		if q.Out != nil {
-			om := models[q.Out.Model]
+	return nil
+}
 
-			if err := match.Output(*q.Out, *om.Schema); err != nil {
-				return fmt.Errorf("query %s: %w", q.Name, err)
-			}
+func matchQuery(models map[string]model.Model, q pg.Query) error {
+	if q.In != nil {
+		im, ok := models[q.In.Model]
+		if !ok {
+			return fmt.Errorf(`unknown model "%s"`, q.In.Model)
+		}
+
+		if err := match.Input(*q.In, *im.Schema); err != nil {
+			return err
+		}
+	}
+
+	if q.Out != nil {
+		om, ok := models[q.Out.Model]
+		if !ok {
+			return fmt.Errorf(`unknown model "%s"`, q.Out.Model)
+		}
+
+		if err := match.Output(*q.Out, *om.Schema); err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/koskimas/norsu/internal/config"
+	"github.com/koskimas/norsu/internal/maps"
+	"github.com/koskimas/norsu/internal/pg"
+)
+
+// analysis holds the schema and the analyzed queries of one set of inputs.
+type analysis struct {
+	db *pg.DB
+
+	// queries holds the results of the query files by their paths
+	// relative to the working directory.
+	queries map[string]queryResult
+}
+
+// queryResult holds an analyzed query or the error that prevented
+// analyzing the query or matching it with its models.
+type queryResult struct {
+	query *pg.Query
+	err   error
+}
+
+// Diff writes the differences between the schemas and the queries of two sets
+// of inputs to `w`. For each query the changes in the output columns, their
+// types and nullability and the query's errors are reported. An error is
+// returned if queries that succeed in `from` fail in `to`.
+func Diff(from Settings, to Settings, w io.Writer) error {
+	before, err := analyze(from)
+	if err != nil {
+		return fmt.Errorf(`failed to analyze "%s": %w`, from.WorkingDir, err)
+	}
+
+	after, err := analyze(to)
+	if err != nil {
+		return fmt.Errorf(`failed to analyze "%s": %w`, to.WorkingDir, err)
+	}
+
+	var out strings.Builder
+	broken := 0
+
+	if changes := pg.DiffSchemas(before.db, after.db); len(changes) > 0 {
+		out.WriteString("schema:\n")
+
+		for _, c := range changes {
+			fmt.Fprintf(&out, "  %s\n", c.String())
+		}
+	}
+
+	paths := append(maps.Keys(before.queries), maps.Keys(after.queries)...)
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	queryChanges := make([]string, 0)
+
+	for _, path := range paths {
+		b, inBefore := before.queries[path]
+		a, inAfter := after.queries[path]
+
+		var changes []string
+
+		switch {
+		case !inAfter:
+			changes = []string{"query was removed"}
+		case !inBefore:
+			changes = []string{"query was added"}
+
+			if a.err != nil {
+				changes = append(changes, fmt.Sprintf("fails: %s", a.err))
+			}
+		case b.err == nil && a.err != nil:
+			broken++
+			changes = []string{fmt.Sprintf("fails: %s", a.err)}
+		case b.err != nil && a.err == nil:
+			changes = []string{fmt.Sprintf("no longer fails: %s", b.err)}
+		case b.err != nil && a.err != nil:
+			if b.err.Error() != a.err.Error() {
+				changes = []string{fmt.Sprintf("fails differently: %s", a.err)}
+			}
+		default:
+			changes = diffQueries(b.query, a.query)
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		queryChanges = append(queryChanges, fmt.Sprintf("  %s:", queryLabel(path, b, a)))
+
+		for _, c := range changes {
+			queryChanges = append(queryChanges, "    "+c)
+		}
+	}
+
+	if len(queryChanges) > 0 {
+		out.WriteString("queries:\n")
+		out.WriteString(strings.Join(queryChanges, "\n"))
+		out.WriteString("\n")
+	}
+
+	if out.Len() == 0 {
+		out.WriteString("no changes\n")
+	}
+
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return err
+	}
+
+	if broken > 0 {
+		return fmt.Errorf("%d queries fail after the changes", broken)
+	}
+
+	return nil
+}
+
+// analyze builds the schema and analyzes the queries of one set of inputs.
+// Errors in the queries are stored in the results. Other errors, like
+// invalid migrations, are returned.
+func analyze(s Settings) (*analysis, error) {
+	config, err := config.Read(filepath.Join(s.WorkingDir, configFile))
+	if err != nil {
+		return nil, err
+	}
+
+	db, _, err := parseMigrations(s, *config)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := readModels(s, *config)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := queryFiles(s, *config)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analysis{
+		db:      db,
+		queries: make(map[string]queryResult, len(files)),
+	}
+
+	for _, f := range files {
+		rel, err := filepath.Rel(s.WorkingDir, f)
+		if err != nil {
+			return nil, err
+		}
+
+		sql, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to read query file "%s": %w`, f, err)
+		}
+
+		q, err := pg.ParseQuery(db, string(sql))
+		if err == nil {
+			err = matchQuery(models, *q)
+		}
+
+		a.queries[rel] = queryResult{query: q, err: err}
+	}
+
+	return a, nil
+}
+
+// diffQueries returns the changes in the output of a query.
+func diffQueries(from *pg.Query, to *pg.Query) []string {
+	changes := make([]string, 0)
+
+	if from.Out == nil && to.Out != nil {
+		return append(changes, "output was added")
+	} else if from.Out != nil && to.Out == nil {
+		return append(changes, "output was removed")
+	} else if from.Out == nil {
+		return changes
+	}
+
+	if from.Out.Model != to.Out.Model {
+		changes = append(changes, fmt.Sprintf("output model changed from %s to %s", from.Out.Model, to.Out.Model))
+	}
+
+	for _, c := range pg.DiffColumns(to.Name, from.Out.Table, to.Out.Table) {
+		changes = append(changes, c.String())
+	}
+
+	return changes
+}
+
+// queryLabel returns the path of a query file with the name of the query
+// if it could be parsed.
+func queryLabel(path string, results ...queryResult) string {
+	for _, r := range results {
+		if r.query != nil {
+			return fmt.Sprintf("%s (%s)", path, r.query.Name)
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/koskimas/norsu/internal/config"
+	"github.com/koskimas/norsu/internal/pg"
+)
+
+// migrationWarning is a lint warning of the migration file `path`.
+type migrationWarning struct {
+	path    string
+	warning pg.LintWarning
+}
+
+// migrationWarnings returns the lint warnings of the migration file `path`
+// leaving out the rules turned off in `lint`.
+func migrationWarnings(path string, warnings []pg.LintWarning, lint config.Lint) []migrationWarning {
+	out := make([]migrationWarning, 0, len(warnings))
+
+	for _, w := range warnings {
+		if enabled, ok := lint.Rules[string(w.Rule)]; ok && !enabled {
+			continue
+		}
+
+		out = append(out, migrationWarning{path: path, warning: w})
+	}
+
+	return out
+}
+
+// checkLint returns an error listing the lint warnings if the linter is on.
+// Renamed columns are only reported if queries still reference them.
+func checkLint(warnings []migrationWarning, queries []pg.Query, lint config.Lint) error {
+	if !lint.Enabled {
+		return nil
+	}
+
+	for name := range lint.Rules {
+		if !slices.Contains(pg.LintRules, pg.LintRule(name)) {
+			return fmt.Errorf(`unknown lint rule "%s" in %s`, name, configFile)
+		}
+	}
+
+	problems := make([]string, 0, len(warnings))
+
+	for _, mw := range warnings {
+		w := mw.warning
+		problem := fmt.Sprintf("%s:%d: %s (%s)", mw.path, w.Line, w.Message, w.Rule)
+
+		if w.Rule == pg.LintRuleRenameColumn {
+			names := make([]string, 0)
+
+			for _, q := range queries {
+				if q.ReferencesColumn(w.Table, w.Column) {
+					names = append(names, q.Name)
+				}
+			}
+
+			if len(names) == 0 {
+				continue
+			}
+
+			problem += fmt.Sprintf(", used by %s", strings.Join(names, ", "))
+		}
+
+		problems = append(problems, problem)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"found %d unsafe migration statements (add a `-- norsu:ignore <rule>` comment before the statements that are safe or turn the rules off in lint.rules in %s):\n  %s",
+		len(problems),
+		configFile,
+		strings.Join(problems, "\n  "),
+	)
+}
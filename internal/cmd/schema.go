@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/koskimas/norsu/internal/config"
+)
+
+type SchemaFormat string
+
+const (
+	SchemaFormatDDL  SchemaFormat = "ddl"
+	SchemaFormatJSON SchemaFormat = "json"
+)
+
+// Schema writes the schema derived from the migrations to `w` in the given
+// format. The output can be compared with the real database or committed
+// as a snapshot.
+func Schema(s Settings, format SchemaFormat, w io.Writer) error {
+	config, err := config.Read(filepath.Join(s.WorkingDir, configFile))
+	if err != nil {
+		return err
+	}
+
+	db, _, err := parseMigrations(s, *config)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+
+	switch format {
+	case SchemaFormatDDL:
+		out = []byte(db.DDL())
+	case SchemaFormatJSON:
+		if out, err = json.MarshalIndent(db, "", "  "); err != nil {
+			return err
+		}
+
+		out = append(out, '\n')
+	default:
+		return fmt.Errorf(`unknown schema format "%s"`, format)
+	}
+
+	_, err = w.Write(out)
+	return err
+}
@@ -10,9 +10,13 @@ import (
 type Config struct {
 	Version    int         `yaml:"version"`
 	Package    Package     `yaml:"package"`
+	SearchPath []string    `yaml:"searchPath"`
+	Schema     *Schema     `yaml:"schema"`
 	Queries    []Query     `yaml:"queries"`
 	Migrations []Migration `yaml:"migrations"`
 	Models     []Model     `yaml:"models"`
+	Strict     Strict      `yaml:"strict"`
+	Lint       Lint        `yaml:"lint"`
 }
 
 type Package struct {
@@ -23,8 +27,50 @@ type Query struct {
 	Path string `yaml:"path"`
 }
 
+// Schema is a schema dump created using `pg_dump --schema-only`. The dump is
+// loaded before the migrations.
+type Schema struct {
+	Path string `yaml:"path"`
+
+	// Version of the latest migration included in the dump. Migrations up to
+	// and including this version are not applied on top of the dump.
+	Version string `yaml:"version"`
+}
+
 type Migration struct {
 	Path string `yaml:"path"`
+
+	// Format is the migration tool the files are written for: "goose",
+	// "golang-migrate", "dbmate", "sql-migrate" or "flyway". The files are
+	// applied in the order of their versions. If empty, the files are applied
+	// in the order of their paths and everything before a `-- +goose Down`
+	// line is the up migration.
+	Format string `yaml:"format"`
+}
+
+// Strict configures the strict mode in which migration statements that norsu
+// doesn't understand are reported as errors instead of being skipped.
+type Strict struct {
+	// Enabled turns the strict mode on or off. If not set, the strict mode
+	// is on when the `CI` environment variable is true.
+	Enabled *bool `yaml:"enabled"`
+
+	// Allow lists the statements that are known to have no effect on types,
+	// like "CREATE INDEX", "GRANT" or "COMMENT". They are written like in the
+	// error messages of the strict mode, ignoring case.
+	Allow []string `yaml:"allow"`
+}
+
+// Lint configures the linter that reports migration statements that lock
+// or rewrite tables, such as adding a not null column without a default.
+type Lint struct {
+	// Enabled turns the linter on. The flagged statements are reported
+	// as errors unless they are preceded by a `-- norsu:ignore` comment.
+	Enabled bool `yaml:"enabled"`
+
+	// Rules turns individual rules on or off by their names, like
+	// `set-not-null: false`. All rules are on by default.
+	Rules map[string]bool `yaml:"rules"`
 }
 
 type Model struct {
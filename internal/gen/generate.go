@@ -87,6 +87,10 @@ func GenerateCode(
 func genQueryInterface(f *jen.File, models map[string]model.Model, queries []pg.Query) {
 	f.Type().Id(idInterfaceQueries).InterfaceFunc(func(g *jen.Group) {
 		for _, q := range queries {
+			if doc := queryDocComment(q); doc != "" {
+				g.Comment(doc)
+			}
+
 			g.Id(q.Name).ParamsFunc(func(g *jen.Group) {
 				g.Id(idParamCtx).Qual("context", "Context")
 
@@ -141,6 +145,10 @@ func genNewFunc(f *jen.File) {
 func genQuery(f *jen.File, q pg.Query, im *model.Model, om *model.Model) {
 	genQuerySqlConstant(f, q)
 
+	if doc := queryDocComment(q); doc != "" {
+		f.Comment(doc)
+	}
+
 	f.Func().Params(
 		jen.Id(idParamQueries).Op("*").Id(idStructQueries),
 	).Id(q.Name).ParamsFunc(func(g *jen.Group) {
@@ -160,6 +168,58 @@ func genQuery(f *jen.File, q pg.Query, im *model.Model, om *model.Model) {
 	})
 }
 
+// queryDocComment returns the doc comment of a query method. The comment lists
+// the tables the query uses and the output columns with their `COMMENT ON`
+// comments. An empty string is returned if none of them have a comment.
+func queryDocComment(q pg.Query) string {
+	hasComments := false
+	lines := []string{q.Name + " uses the tables:"}
+
+	for _, t := range q.Tables {
+		lines = append(lines, docListItem(t.Name.String(), t.Comment)...)
+		hasComments = hasComments || t.Comment != nil
+	}
+
+	if q.Out != nil && slices.ContainsFunc(q.Out.Table.Columns, func(c *pg.Column) bool { return c.Comment != nil }) {
+		lines = append(lines, "", "The output columns are:")
+
+		for _, c := range q.Out.Table.Columns {
+			if c.Comment != nil {
+				lines = append(lines, docListItem(c.Name, c.Comment)...)
+				hasComments = true
+			}
+		}
+	}
+
+	if !hasComments {
+		return ""
+	}
+
+	// Comments that start with `//` are written as is.
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("// "+l, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// docListItem formats a list item of a doc comment. The lines of
+// a multiline description are indented under the item.
+func docListItem(name string, description *string) []string {
+	if description == nil {
+		return []string{"  - " + name}
+	}
+
+	lines := strings.Split(strings.TrimSpace(*description), "\n")
+	lines[0] = "  - " + name + ": " + lines[0]
+
+	for i := 1; i < len(lines); i++ {
+		lines[i] = "    " + lines[i]
+	}
+
+	return lines
+}
+
 func genQuerySqlConstant(f *jen.File, q pg.Query) {
 	f.Const().Id(getSqlConstName(q)).Op("=").Id("`\n" + q.SQL + "`")
 	f.Empty()
@@ -170,7 +230,7 @@ func genQueryBody(g *jen.Group, q pg.Query, im *model.Model, om *model.Model) {
 		genQueryInputVars(g, q, *im)
 	}
 
-	genQueryExecute(g, q, im)
+	genQueryExecute(g, q, im, om)
 	genScanRows(g, q, om)
 
 	g.ReturnFunc(func(g *jen.Group) {
@@ -200,14 +260,14 @@ func genQueryInputVars(g *jen.Group, q pg.Query, im model.Model) {
 	}
 }
 
-func genQueryExecute(g *jen.Group, q pg.Query, im *model.Model) {
+func genQueryExecute(g *jen.Group, q pg.Query, im *model.Model, om *model.Model) {
 	// Execute the query by calling the `Query` method on the `DB`.
 	g.List(jen.Id(idVarRows), jen.Err()).Op(":=").Id(idParamQueries).Dot(idPropDb).Dot("Query").CallFunc(func(g *jen.Group) {
 		genQueryInputParams(g, q, im)
 	})
 
 	// Handle `Query` method error.
-	genHandleError(g, im == nil)
+	genHandleError(g, om == nil)
 
 	// Make sure the query result is eventually closed.
 	g.Defer().Id(idVarRows).Dot("Close").Call()
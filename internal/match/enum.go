@@ -0,0 +1,77 @@
+package match
+
+import (
+	"slices"
+
+	"github.com/koskimas/norsu/internal/model"
+	"github.com/koskimas/norsu/internal/pg"
+)
+
+// doesEnumPopulateModel checks that all labels of an enum typed column are
+// valid values for the string (or string array) property `schema`.
+func doesEnumPopulateModel(column pg.Column, schema model.Schema, schemaPath *SchemaPath) error {
+	enum := column.Type.Enum
+
+	s, err := getEnumValueSchema(column.Type, schema, schemaPath)
+	if err != nil {
+		return err
+	}
+
+	if len(s.Enum) == 0 {
+		// Any string is allowed.
+		return nil
+	}
+
+	for _, l := range enum.Labels {
+		if !slices.Contains(s.Enum, l) {
+			return matchErrorf(schemaPath, `enum label "%s" of type "%s" is not a valid value for output property %s`, l, enum.Name.String(), schemaPath.GoString())
+		}
+	}
+
+	return nil
+}
+
+// doesModelPopulateEnum checks that all values of the string (or string array)
+// property the input path points to are valid labels of an enum type. The
+// property must list its allowed values.
+func doesModelPopulateEnum(dataType pg.DataType, schemaPath *SchemaPath) error {
+	enum := dataType.Enum
+
+	s, err := getEnumValueSchema(dataType, *schemaPath.Schema, schemaPath)
+	if err != nil {
+		return err
+	}
+
+	if len(s.Enum) == 0 {
+		// Any string could be passed to the enum.
+		return matchErrorf(schemaPath, `input property %s allows values that are not labels of enum type "%s"`, schemaPath.GoString(), enum.Name.String())
+	}
+
+	for _, v := range s.Enum {
+		if !enum.HasLabel(v) {
+			return matchErrorf(schemaPath, `value "%s" of input property %s is not a label of enum type "%s"`, v, schemaPath.GoString(), enum.Name.String())
+		}
+	}
+
+	return nil
+}
+
+// getEnumValueSchema returns the schema of the enum values in `schema`. For
+// enum arrays this is the item schema.
+func getEnumValueSchema(dataType pg.DataType, schema model.Schema, schemaPath *SchemaPath) (*model.Schema, error) {
+	s := &schema
+
+	if dataType.Array {
+		if schema.Type != model.TypeArray {
+			return nil, matchErrorf(schemaPath, `invalid type "%s" for a non-array property %s`, dataType.String(), schemaPath.GoString())
+		}
+
+		s = schema.Items
+	}
+
+	if s.Type != model.TypeString {
+		return nil, matchErrorf(schemaPath, `invalid type "%s" for a non-string property %s`, dataType.String(), schemaPath.GoString())
+	}
+
+	return s, nil
+}
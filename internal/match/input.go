@@ -10,7 +10,11 @@ import (
 func Input(input pg.QueryInput, schema model.Schema) error {
 	for _, i := range input.Inputs {
 		r, err := ResolveRef(&schema, i.Ref)
-		if err != nil {
+		if err != nil && i.Optional {
+			// The input model leaves out the optional input and
+			// the default of the column is inserted instead.
+			continue
+		} else if err != nil {
 			return fmt.Errorf("query inputs: %w", err)
 		}
 
@@ -31,8 +35,28 @@ func Input(input pg.QueryInput, schema model.Schema) error {
 			}
 		}
 
+		if i.Type != nil && i.Type.Enum != nil {
+			if err := doesModelPopulateEnum(*i.Type, r); err != nil {
+				return fmt.Errorf("query inputs: %w", err)
+			}
+		}
+
 		// TODO: Check input types once the pg package can output them.
 	}
 
 	return nil
 }
+
+// OmittedInputs returns the references of the optional inputs the input model
+// `schema` leaves out.
+func OmittedInputs(input pg.QueryInput, schema model.Schema) []string {
+	refs := make([]string, 0)
+
+	for _, i := range input.Inputs {
+		if _, err := ResolveRef(&schema, i.Ref); err != nil && i.Optional {
+			refs = append(refs, i.Ref)
+		}
+	}
+
+	return refs
+}
@@ -39,8 +39,15 @@ func doesTablePopulateModel(
 			return matchErrorf(schemaPath, `selection missing for output property %s`, schemaPath.GoString())
 		}
 
+		// Composite type attributes are named like columns but in json
+		// they are named like json properties.
+		recordType := matchTypeJson
+		if column.Type.Composite != nil && aType == matchTypeColumn {
+			recordType = matchTypeColumn
+		}
+
 		if p.Type == model.TypeObject {
-			if !column.Type.Json() {
+			if !column.Type.Json() && column.Type.Composite == nil {
 				return matchErrorf(schemaPath, `invalid selection type "%s" for an object output property %s`, column.Type.String(), schemaPath.GoString())
 			}
 
@@ -49,7 +56,7 @@ func doesTablePopulateModel(
 			}
 
 			if column.Type.Record != nil {
-				if err := doesTablePopulateModel(matchTypeJson, *column.Type.Record, *p, schemaPath); err != nil {
+				if err := doesTablePopulateModel(recordType, *column.Type.Record, *p, schemaPath); err != nil {
 					return err
 				}
 			}
@@ -65,12 +72,18 @@ func doesTablePopulateModel(
 			}
 
 			if column.Type.Record != nil {
-				if err := doesTablePopulateModel(matchTypeJson, *column.Type.Record, *p.Items, schemaPath); err != nil {
+				if err := doesTablePopulateModel(recordType, *column.Type.Record, *p.Items, schemaPath); err != nil {
 					return err
 				}
 			}
 		}
 
+		if column.Type.Enum != nil {
+			if err := doesEnumPopulateModel(*column, *p, schemaPath); err != nil {
+				return err
+			}
+		}
+
 		// TODO: Check column types. The conversion rules are based on database/sql package
 		//       and are really complex.
 
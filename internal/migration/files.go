@@ -0,0 +1,149 @@
+package migration
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	golangMigrateRegex = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.[^.]+$`)
+	flywayRegex        = regexp.MustCompile(`^([VUR])(.*?)__.*$`)
+)
+
+// readGolangMigrate reads golang-migrate style `{version}_{title}.up.sql`
+// and `{version}_{title}.down.sql` file pairs.
+func readGolangMigrate(paths []string, files map[string]string) ([]*Migration, error) {
+	migrationsByVersion := make(map[string]*Migration)
+	migrations := make([]*Migration, 0, len(paths))
+	downs := make([]string, 0)
+
+	for _, p := range paths {
+		match := golangMigrateRegex.FindStringSubmatch(filepath.Base(p))
+		if match == nil {
+			return nil, fmt.Errorf(`migration file "%s" is not named like {version}_{title}.up.sql or {version}_{title}.down.sql`, p)
+		}
+
+		if match[2] == "down" {
+			downs = append(downs, p)
+			continue
+		}
+
+		version, err := parseVersion(match[1], "")
+		if err != nil {
+			return nil, fmt.Errorf(`migration file "%s": %w`, p, err)
+		}
+
+		m := &Migration{
+			Path:    p,
+			Version: match[1],
+			Up:      files[p],
+			version: version,
+		}
+
+		migrations = append(migrations, m)
+		migrationsByVersion[fmt.Sprint(version)] = m
+	}
+
+	if err := checkDuplicateVersions(migrations); err != nil {
+		return nil, err
+	}
+
+	for _, p := range downs {
+		match := golangMigrateRegex.FindStringSubmatch(filepath.Base(p))
+		version, _ := parseVersion(match[1], "")
+
+		m := migrationsByVersion[fmt.Sprint(version)]
+		if m == nil {
+			return nil, fmt.Errorf(`down migration file "%s" has no up migration`, p)
+		}
+
+		if m.HasDown() {
+			return nil, fmt.Errorf(`migration files "%s" and "%s" have the same version %s`, m.DownPath, p, m.Version)
+		}
+
+		m.Down = files[p]
+		m.DownPath = p
+	}
+
+	return migrations, nil
+}
+
+// readFlyway reads Flyway style versioned `V{version}__{description}.sql`,
+// undo `U{version}__{description}.sql` and repeatable `R__{description}.sql`
+// migrations. Repeatable migrations are applied after all versioned
+// migrations in the order of their descriptions.
+func readFlyway(paths []string, files map[string]string) ([]*Migration, error) {
+	migrationsByVersion := make(map[string]*Migration)
+	migrations := make([]*Migration, 0, len(paths))
+	repeatable := make([]*Migration, 0)
+	undos := make([]string, 0)
+
+	for _, p := range paths {
+		match := flywayRegex.FindStringSubmatch(filepath.Base(p))
+		if match == nil {
+			return nil, fmt.Errorf(`migration file "%s" is not named like V{version}__{description}.sql, U{version}__{description}.sql or R__{description}.sql`, p)
+		}
+
+		switch match[1] {
+		case "R":
+			if len(match[2]) != 0 {
+				return nil, fmt.Errorf(`repeatable migration file "%s" can't have a version`, p)
+			}
+
+			repeatable = append(repeatable, &Migration{
+				Path: p,
+				Up:   files[p],
+			})
+		case "U":
+			undos = append(undos, p)
+		case "V":
+			version, err := parseVersion(match[2], "._")
+			if err != nil {
+				return nil, fmt.Errorf(`migration file "%s": %w`, p, err)
+			}
+
+			m := &Migration{
+				Path:    p,
+				Version: strings.ReplaceAll(match[2], "_", "."),
+				Up:      files[p],
+				version: version,
+			}
+
+			migrations = append(migrations, m)
+			migrationsByVersion[fmt.Sprint(version)] = m
+		}
+	}
+
+	if err := checkDuplicateVersions(migrations); err != nil {
+		return nil, err
+	}
+
+	for _, p := range undos {
+		match := flywayRegex.FindStringSubmatch(filepath.Base(p))
+
+		version, err := parseVersion(match[2], "._")
+		if err != nil {
+			return nil, fmt.Errorf(`migration file "%s": %w`, p, err)
+		}
+
+		m := migrationsByVersion[fmt.Sprint(version)]
+		if m == nil {
+			return nil, fmt.Errorf(`undo migration file "%s" has no versioned migration`, p)
+		}
+
+		m.Down = files[p]
+		m.DownPath = p
+	}
+
+	sortByVersion(migrations)
+
+	slices.SortStableFunc(repeatable, func(a, b *Migration) int {
+		return cmp.Compare(filepath.Base(a.Path), filepath.Base(b.Path))
+	})
+
+	return append(migrations, repeatable...), nil
+}
@@ -0,0 +1,208 @@
+package migration
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Format is the migration tool whose file naming and up/down
+// section conventions are used to read migration files.
+type Format string
+
+const (
+	// FormatLegacy reads the files in the order they are given. Everything
+	// before a `-- +goose Down` line is considered to be the up migration.
+	FormatLegacy        Format = ""
+	FormatGoose         Format = "goose"
+	FormatGolangMigrate Format = "golang-migrate"
+	FormatDbmate        Format = "dbmate"
+	FormatSqlMigrate    Format = "sql-migrate"
+	FormatFlyway        Format = "flyway"
+)
+
+// Migration is a single migration read from one or two files.
+type Migration struct {
+	// Path of the file that holds the up migration.
+	Path string
+
+	// Version of the migration as written in the file name.
+	Version string
+
+	// Up holds the SQL of the up migration. Lines of the file that don't
+	// belong to the up migration are replaced by empty lines so that line
+	// numbers match the file.
+	Up string
+
+	// DownPath is the path of the file that holds the down migration. Same
+	// as `Path` for formats that store both migrations in the same file
+	// and empty if there is no down migration.
+	DownPath string
+
+	// Down holds the SQL of the down migration the same way as `Up`.
+	Down string
+
+	version []uint64
+}
+
+func (m *Migration) HasDown() bool {
+	return len(m.DownPath) != 0
+}
+
+// Read reads the migration files `paths` and returns the migrations in the
+// order they should be applied.
+func Read(format Format, paths []string) ([]*Migration, error) {
+	files := make(map[string]string, len(paths))
+
+	for _, p := range paths {
+		sql, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to read migration file "%s": %w`, p, err)
+		}
+
+		files[p] = string(sql)
+	}
+
+	var migrations []*Migration
+	var err error
+
+	switch format {
+	case FormatLegacy:
+		migrations, err = readLegacy(paths, files)
+	case FormatGoose:
+		migrations, err = readSections(paths, files, gooseAnnotations)
+	case FormatSqlMigrate:
+		migrations, err = readSections(paths, files, sqlMigrateAnnotations)
+	case FormatDbmate:
+		migrations, err = readSections(paths, files, dbmateAnnotations)
+	case FormatGolangMigrate:
+		migrations, err = readGolangMigrate(paths, files)
+	case FormatFlyway:
+		migrations, err = readFlyway(paths, files)
+	default:
+		return nil, fmt.Errorf(`unknown migration format "%s"`, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if format != FormatLegacy && format != FormatFlyway {
+		sortByVersion(migrations)
+	}
+
+	return migrations, nil
+}
+
+// After returns the migrations whose version is greater than `version`.
+// Migrations without a version are always included.
+func After(migrations []*Migration, version string) ([]*Migration, error) {
+	v, err := parseVersion(version, "._")
+	if err != nil {
+		return nil, err
+	}
+
+	after := make([]*Migration, 0, len(migrations))
+
+	for _, m := range migrations {
+		if m.version == nil || compareVersions(m.version, v) > 0 {
+			after = append(after, m)
+		}
+	}
+
+	return after, nil
+}
+
+// parseVersion parses a version like `20240101120000` or `1.2.10` into its
+// numeric parts. `separators` are the characters allowed between the parts.
+func parseVersion(version string, separators string) ([]uint64, error) {
+	parts := strings.FieldsFunc(version, func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	})
+
+	if len(parts) == 0 {
+		return nil, fmt.Errorf(`invalid version "%s"`, version)
+	}
+
+	nums := make([]uint64, 0, len(parts))
+
+	for _, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid version "%s"`, version)
+		}
+
+		nums = append(nums, n)
+	}
+
+	return nums, nil
+}
+
+// versionPrefix returns the leading digits of a file name.
+func versionPrefix(path string) string {
+	name := filepath.Base(path)
+	i := strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+
+	if i == -1 {
+		return name
+	}
+
+	return name[:i]
+}
+
+func sortByVersion(migrations []*Migration) {
+	slices.SortStableFunc(migrations, func(a, b *Migration) int {
+		// Files without a version are ordered by name.
+		if a.version != nil && b.version != nil {
+			if c := compareVersions(a.version, b.version); c != 0 {
+				return c
+			}
+		}
+
+		return cmp.Compare(filepath.Base(a.Path), filepath.Base(b.Path))
+	})
+}
+
+func compareVersions(a []uint64, b []uint64) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y uint64
+
+		if i < len(a) {
+			x = a[i]
+		}
+
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func checkDuplicateVersions(migrations []*Migration) error {
+	versions := make(map[string]string, len(migrations))
+
+	for _, m := range migrations {
+		if m.version == nil {
+			continue
+		}
+
+		key := fmt.Sprint(m.version)
+
+		if p, ok := versions[key]; ok {
+			return fmt.Errorf(`migration files "%s" and "%s" have the same version %s`, p, m.Path, m.Version)
+		}
+
+		versions[key] = m.Path
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// annotations describe the comments that split a migration file into up and
+// down sections in tools that store both migrations in the same file.
+type annotations struct {
+	// prefix of all annotation comments, like `-- +goose`.
+	prefix string
+
+	// up is the annotation that starts the up migration.
+	up string
+
+	// statementBlocks is true if the tool supports `StatementBegin` and
+	// `StatementEnd` annotations around statements that contain semicolons.
+	statementBlocks bool
+
+	// optionalVersion is true if file names don't need to start with a
+	// version. Such files are ordered by name.
+	optionalVersion bool
+}
+
+const (
+	annotationUp             = "up"
+	annotationDown           = "down"
+	annotationStatementBegin = "statementbegin"
+	annotationStatementEnd   = "statementend"
+)
+
+var (
+	gooseAnnotations = annotations{
+		prefix:          "-- +goose",
+		up:              "-- +goose Up",
+		statementBlocks: true,
+	}
+
+	sqlMigrateAnnotations = annotations{
+		prefix:          "-- +migrate",
+		up:              "-- +migrate Up",
+		statementBlocks: true,
+		optionalVersion: true,
+	}
+
+	dbmateAnnotations = annotations{
+		prefix: "-- migrate:",
+		up:     "-- migrate:up",
+	}
+)
+
+// parse returns the lower cased annotation on the line `l` if the line
+// is an annotation comment. Options after the annotation are ignored.
+func (a annotations) parse(l string) (string, bool) {
+	l = strings.TrimSpace(l)
+	if !strings.HasPrefix(l, a.prefix) {
+		return "", false
+	}
+
+	fields := strings.Fields(l[len(a.prefix):])
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	return strings.ToLower(fields[0]), true
+}
+
+func readSections(paths []string, files map[string]string, a annotations) ([]*Migration, error) {
+	migrations := make([]*Migration, 0, len(paths))
+
+	for _, p := range paths {
+		m := &Migration{
+			Path:    p,
+			Version: versionPrefix(p),
+		}
+
+		if len(m.Version) == 0 && !a.optionalVersion {
+			return nil, fmt.Errorf(`migration file "%s" doesn't start with a version`, p)
+		} else if len(m.Version) != 0 {
+			// A version prefix consists of digits only so this can't fail.
+			m.version, _ = parseVersion(m.Version, "")
+		}
+
+		up, down, hasDown, err := splitSections(files[p], a)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to read migration file "%s": %w`, p, err)
+		}
+
+		m.Up = up
+		if hasDown {
+			m.Down = down
+			m.DownPath = p
+		}
+
+		migrations = append(migrations, m)
+	}
+
+	if err := checkDuplicateVersions(migrations); err != nil {
+		return nil, err
+	}
+
+	return migrations, nil
+}
+
+// splitSections splits a migration file into the up and down migrations.
+// The lines of the other section and the annotation lines are replaced by
+// empty lines so that line numbers in both sections match the file.
+func splitSections(sql string, a annotations) (string, string, bool, error) {
+	lines := strings.Split(sql, "\n")
+	up := make([]string, len(lines))
+	down := make([]string, len(lines))
+
+	var section string
+	var hasUp, hasDown, inStatement bool
+
+	for i, l := range lines {
+		annotation, ok := a.parse(l)
+		if !ok {
+			switch section {
+			case annotationUp:
+				up[i] = l
+			case annotationDown:
+				down[i] = l
+			}
+
+			continue
+		}
+
+		switch annotation {
+		case annotationUp, annotationDown:
+			if inStatement {
+				return "", "", false, fmt.Errorf(`line %d: missing "%s StatementEnd" annotation`, i+1, a.prefix)
+			}
+
+			section = annotation
+			hasUp = hasUp || annotation == annotationUp
+			hasDown = hasDown || annotation == annotationDown
+		case annotationStatementBegin:
+			if !a.statementBlocks {
+				continue
+			}
+
+			if inStatement {
+				return "", "", false, fmt.Errorf(`line %d: nested "%s StatementBegin" annotation`, i+1, a.prefix)
+			}
+
+			inStatement = true
+		case annotationStatementEnd:
+			if !a.statementBlocks {
+				continue
+			}
+
+			if !inStatement {
+				return "", "", false, fmt.Errorf(`line %d: "%s StatementEnd" without "%s StatementBegin"`, i+1, a.prefix, a.prefix)
+			}
+
+			inStatement = false
+		}
+	}
+
+	if inStatement {
+		return "", "", false, fmt.Errorf(`missing "%s StatementEnd" annotation`, a.prefix)
+	}
+
+	if !hasUp {
+		return "", "", false, fmt.Errorf(`missing "%s" annotation`, a.up)
+	}
+
+	return strings.Join(up, "\n"), strings.Join(down, "\n"), hasDown, nil
+}
+
+// readLegacy reads the files in the given order. Everything before a
+// `-- +goose Down` line is the up migration and the rest is the down
+// migration.
+func readLegacy(paths []string, files map[string]string) ([]*Migration, error) {
+	migrations := make([]*Migration, 0, len(paths))
+
+	for _, p := range paths {
+		m := &Migration{
+			Path:    p,
+			Version: versionPrefix(p),
+		}
+
+		if len(m.Version) != 0 {
+			// The version is only used to leave out the migrations of a
+			// schema dump. The files are still applied in the given order.
+			m.version, _ = parseVersion(m.Version, "")
+		}
+
+		lines := strings.Split(files[p], "\n")
+		i := len(lines)
+
+		for j, l := range lines {
+			if strings.HasPrefix(l, "-- +goose Down") {
+				i = j
+				break
+			}
+		}
+
+		m.Up = strings.Join(lines[:i], "\n")
+
+		if i != len(lines) {
+			m.Down = strings.Repeat("\n", i) + strings.Join(lines[i:], "\n")
+			m.DownPath = p
+		}
+
+		migrations = append(migrations, m)
+	}
+
+	return migrations, nil
+}
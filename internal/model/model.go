@@ -24,6 +24,10 @@ type Schema struct {
 	Properties map[string]*Schema
 	Required   map[string]bool
 	Items      *Schema
+
+	// Enum holds the allowed values of a string schema. Empty if
+	// any value is allowed.
+	Enum []string
 }
 
 type Model struct {
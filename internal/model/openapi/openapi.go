@@ -26,6 +26,7 @@ type Schema struct {
 	Properties map[string]Schema `yaml:"properties"`
 	Items      *Schema           `yaml:"items"`
 	Required   []string          `yaml:"required"`
+	Enum       []string          `yaml:"enum"`
 }
 
 type AbsoluteFilePath = string
@@ -150,6 +151,7 @@ func resolveModel(
 		} else {
 			mod = &model.Schema{
 				Type: *modelType,
+				Enum: schema.Enum,
 			}
 		}
 	}
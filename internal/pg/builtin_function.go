@@ -0,0 +1,380 @@
+package pg
+
+import (
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+)
+
+// builtinNulls describes when a built-in function returns null.
+type builtinNulls int
+
+const (
+	// nullsStrict functions return null only if one of their arguments is
+	// null. Postgres calls these functions strict.
+	nullsStrict builtinNulls = iota
+
+	// nullsNever functions like `count` and `now` never return null.
+	nullsNever
+
+	// nullsAlways functions can return null regardless of their arguments.
+	// Aggregates other than `count` return null for zero rows, for example,
+	// and `substring(x FROM pattern)` if the pattern doesn't match.
+	nullsAlways
+)
+
+// builtinFunction describes the return type of a postgres built-in function.
+type builtinFunction struct {
+	// returns holds the name of the return type of functions that always
+	// return the same type. It's also the fallback of `returnsByArg`.
+	returns string
+
+	// returnsByArg maps the type of the argument `arg` to the return type
+	// for functions like `sum` whose return type depends on the argument.
+	returnsByArg map[string]string
+
+	// returnsArg is true for functions like `max` that return the type
+	// of the argument `arg`. Types found from `returnsByArg` are converted
+	// to the type they are mapped to.
+	returnsArg bool
+
+	// returnsArray is true for functions like `array_agg` that return
+	// an array of the type of the argument `arg`.
+	returnsArray bool
+
+	// returnsCommon is true for functions like `mod` that return the
+	// common type of all arguments like arithmetic operators.
+	returnsCommon bool
+
+	// arg is the index of the argument the return type depends on.
+	arg int
+
+	nulls builtinNulls
+}
+
+var (
+	sumTypes = map[string]string{
+		"int2":     "int8",
+		"int4":     "int8",
+		"int8":     "numeric",
+		"numeric":  "numeric",
+		"float4":   "float4",
+		"float8":   "float8",
+		"money":    "money",
+		"interval": "interval",
+	}
+
+	avgTypes = map[string]string{
+		"int2":     "numeric",
+		"int4":     "numeric",
+		"int8":     "numeric",
+		"numeric":  "numeric",
+		"float4":   "float8",
+		"float8":   "float8",
+		"interval": "interval",
+	}
+
+	// Math functions are defined for `float8` and `numeric`. Other numbers
+	// are converted to `float8`, the preferred numeric type.
+	mathTypes = map[string]string{
+		"numeric": "numeric",
+	}
+)
+
+// builtinFunctionCatalog holds the built-in functions whose return types
+// norsu knows by their names. Functions that postgres calls using a special
+// syntax, like `extract(year FROM x)` or `trim(x)`, are listed by the names
+// of the functions they are converted to.
+var builtinFunctionCatalog = map[string]builtinFunction{
+	// Aggregate functions.
+	"count":            {returns: "int8", nulls: nullsNever},
+	"sum":              {returnsByArg: sumTypes, nulls: nullsAlways},
+	"avg":              {returnsByArg: avgTypes, nulls: nullsAlways},
+	"min":              {returnsArg: true, nulls: nullsAlways},
+	"max":              {returnsArg: true, nulls: nullsAlways},
+	"array_agg":        {returnsArray: true, nulls: nullsAlways},
+	"string_agg":       {returns: "text", returnsByArg: map[string]string{"bytea": "bytea"}, nulls: nullsAlways},
+	"bool_and":         {returns: "bool", nulls: nullsAlways},
+	"bool_or":          {returns: "bool", nulls: nullsAlways},
+	"every":            {returns: "bool", nulls: nullsAlways},
+	"json_object_agg":  {returns: DataTypeJson, nulls: nullsAlways},
+	"jsonb_object_agg": {returns: DataTypeJsonb, nulls: nullsAlways},
+
+	// Window functions.
+	"row_number":   {returns: "int8", nulls: nullsNever},
+	"rank":         {returns: "int8", nulls: nullsNever},
+	"dense_rank":   {returns: "int8", nulls: nullsNever},
+	"percent_rank": {returns: "float8", nulls: nullsNever},
+	"cume_dist":    {returns: "float8", nulls: nullsNever},
+	"ntile":        {returns: "int4"},
+	"lag":          {returnsArg: true, nulls: nullsAlways},
+	"lead":         {returnsArg: true, nulls: nullsAlways},
+	"first_value":  {returnsArg: true, nulls: nullsAlways},
+	"last_value":   {returnsArg: true, nulls: nullsAlways},
+	"nth_value":    {returnsArg: true, nulls: nullsAlways},
+
+	// Date and time functions.
+	"now":                   {returns: "timestamptz", nulls: nullsNever},
+	"statement_timestamp":   {returns: "timestamptz", nulls: nullsNever},
+	"transaction_timestamp": {returns: "timestamptz", nulls: nullsNever},
+	"clock_timestamp":       {returns: "timestamptz", nulls: nullsNever},
+	"extract":               {returns: "numeric"},
+	"date_part":             {returns: "float8"},
+	"date_trunc":            {returnsArg: true, returnsByArg: map[string]string{"date": "timestamptz"}, arg: 1},
+	"age":                   {returns: "interval"},
+	"to_timestamp":          {returns: "timestamptz"},
+	"to_date":               {returns: "date"},
+	"make_date":             {returns: "date"},
+	"make_interval":         {returns: "interval"},
+
+	// String functions.
+	"lower":            {returns: "text"},
+	"upper":            {returns: "text"},
+	"initcap":          {returns: "text"},
+	"btrim":            {returns: "text"},
+	"ltrim":            {returns: "text"},
+	"rtrim":            {returns: "text"},
+	"lpad":             {returns: "text"},
+	"rpad":             {returns: "text"},
+	"left":             {returns: "text"},
+	"right":            {returns: "text"},
+	"substring":        {returns: "text", nulls: nullsAlways},
+	"substr":           {returns: "text"},
+	"replace":          {returns: "text"},
+	"translate":        {returns: "text"},
+	"repeat":           {returns: "text"},
+	"reverse":          {returns: "text"},
+	"split_part":       {returns: "text"},
+	"regexp_replace":   {returns: "text"},
+	"regexp_match":     {returns: "text[]", nulls: nullsAlways},
+	"md5":              {returns: "text"},
+	"to_char":          {returns: "text"},
+	"format":           {returns: "text"},
+	"concat":           {returns: "text", nulls: nullsNever},
+	"concat_ws":        {returns: "text"},
+	"quote_ident":      {returns: "text"},
+	"quote_literal":    {returns: "text"},
+	"quote_nullable":   {returns: "text", nulls: nullsNever},
+	"length":           {returns: "int4"},
+	"char_length":      {returns: "int4"},
+	"character_length": {returns: "int4"},
+	"octet_length":     {returns: "int4"},
+	"position":         {returns: "int4"},
+	"strpos":           {returns: "int4"},
+	"starts_with":      {returns: "bool"},
+
+	// Math functions.
+	"abs":     {returnsArg: true},
+	"mod":     {returnsCommon: true},
+	"round":   {returns: "float8", returnsByArg: mathTypes},
+	"trunc":   {returns: "float8", returnsByArg: mathTypes},
+	"ceil":    {returns: "float8", returnsByArg: mathTypes},
+	"ceiling": {returns: "float8", returnsByArg: mathTypes},
+	"floor":   {returns: "float8", returnsByArg: mathTypes},
+	"sqrt":    {returns: "float8", returnsByArg: mathTypes},
+	"power":   {returns: "float8", returnsByArg: mathTypes},
+	"exp":     {returns: "float8", returnsByArg: mathTypes},
+	"ln":      {returns: "float8", returnsByArg: mathTypes},
+	"random":  {returns: "float8", nulls: nullsNever},
+
+	// Array functions.
+	"array_length":    {returns: "int4", nulls: nullsAlways},
+	"cardinality":     {returns: "int4"},
+	"array_to_string": {returns: "text"},
+	"array_append":    {returnsArg: true},
+	"array_prepend":   {returnsArg: true, arg: 1},
+	"array_cat":       {returnsArg: true},
+	"array_remove":    {returnsArg: true},
+
+	// Json functions.
+	"json_build_array":        {returns: DataTypeJson, nulls: nullsNever},
+	"jsonb_build_array":       {returns: DataTypeJsonb, nulls: nullsNever},
+	"json_array_length":       {returns: "int4"},
+	"jsonb_array_length":      {returns: "int4"},
+	"json_typeof":             {returns: "text"},
+	"jsonb_typeof":            {returns: "text"},
+	"jsonb_set":               {returns: DataTypeJsonb},
+	"jsonb_strip_nulls":       {returns: DataTypeJsonb},
+	"jsonb_pretty":            {returns: "text"},
+	"row_to_json":             {returns: DataTypeJson},
+	"json_extract_path":       {returns: DataTypeJson, nulls: nullsAlways},
+	"jsonb_extract_path":      {returns: DataTypeJsonb, nulls: nullsAlways},
+	"json_extract_path_text":  {returns: "text", nulls: nullsAlways},
+	"jsonb_extract_path_text": {returns: "text", nulls: nullsAlways},
+
+	// Other functions.
+	"gen_random_uuid": {returns: "uuid", nulls: nullsNever},
+	"current_setting": {returns: "text", nulls: nullsAlways},
+	"version":         {returns: "text", nulls: nullsNever},
+}
+
+// sqlValueFunctions holds the names and the types of the functions called
+// without parentheses, like `current_timestamp`.
+var sqlValueFunctions = map[pg_query.SQLValueFunctionOp]Column{
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_DATE:        {Name: "current_date", Type: DataType{Name: "date"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_TIME:        {Name: "current_time", Type: DataType{Name: "timetz"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_TIME_N:      {Name: "current_time", Type: DataType{Name: "timetz"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_TIMESTAMP:   {Name: "current_timestamp", Type: DataType{Name: "timestamptz"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_TIMESTAMP_N: {Name: "current_timestamp", Type: DataType{Name: "timestamptz"}},
+	pg_query.SQLValueFunctionOp_SVFOP_LOCALTIME:           {Name: "localtime", Type: DataType{Name: "time"}},
+	pg_query.SQLValueFunctionOp_SVFOP_LOCALTIME_N:         {Name: "localtime", Type: DataType{Name: "time"}},
+	pg_query.SQLValueFunctionOp_SVFOP_LOCALTIMESTAMP:      {Name: "localtimestamp", Type: DataType{Name: "timestamp"}},
+	pg_query.SQLValueFunctionOp_SVFOP_LOCALTIMESTAMP_N:    {Name: "localtimestamp", Type: DataType{Name: "timestamp"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_ROLE:        {Name: "current_role", Type: DataType{Name: "name"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_USER:        {Name: "current_user", Type: DataType{Name: "name"}},
+	pg_query.SQLValueFunctionOp_SVFOP_USER:                {Name: "user", Type: DataType{Name: "name"}},
+	pg_query.SQLValueFunctionOp_SVFOP_SESSION_USER:        {Name: "session_user", Type: DataType{Name: "name"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_CATALOG:     {Name: "current_catalog", Type: DataType{Name: "name"}},
+	pg_query.SQLValueFunctionOp_SVFOP_CURRENT_SCHEMA:      {Name: "current_schema", Type: DataType{Name: "name"}},
+}
+
+// findBuiltinFunction returns the catalog entry of a called built-in function
+// and the name of the function. Nil is returned for other functions.
+func findBuiltinFunction(fc *pg_query.FuncCall) (*builtinFunction, string) {
+	name, err := parseTypeNameParts(fc.GetFuncname())
+	if err != nil || (name.HasSchema() && name.Schema != "pg_catalog") {
+		return nil, ""
+	}
+
+	if f, ok := builtinFunctionCatalog[name.Name]; ok {
+		return &f, name.Name
+	}
+
+	return nil, ""
+}
+
+// parseBuiltinFunctionSelection parses a call to a function found from
+// `builtinFunctionCatalog`. Like postgres, the selection is named after
+// the function.
+func parseBuiltinFunctionSelection(ctx *QueryParseContext, call *pg_query.FuncCall, f *builtinFunction, name string) (*selection, error) {
+	col := &Column{Name: name}
+
+	// The arguments are only needed if the result depends on them.
+	var args []*Column
+	if f.nulls == nullsStrict || f.returnsByArg != nil || f.returnsArg || f.returnsArray || f.returnsCommon {
+		var err error
+		if args, err = parseOperands(ctx, call.GetArgs()...); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.returnsCommon {
+		if len(args) == 0 {
+			return nil, ctx.Errorf(`function "%s" expects at least 1 arguments`, name)
+		}
+
+		col.Type = args[0].Type.Clone()
+
+		for _, a := range args[1:] {
+			t, ok := commonType(col.Type, a.Type)
+			if !ok {
+				return nil, ctx.Errorf(`unsupported argument types "%s" and "%s" for function "%s"`, typeString(col.Type), typeString(a.Type), name)
+			}
+
+			col.Type = t
+		}
+
+		col.Type.unknown = false
+		col.Type.Domain = nil
+		col.Type.Modifiers = nil
+	} else if f.returnsByArg != nil || f.returnsArg || f.returnsArray {
+		if f.arg >= len(args) {
+			return nil, ctx.Errorf(`function "%s" expects at least %d arguments`, name, f.arg+1)
+		}
+
+		arg := args[f.arg].Type
+		arg.unknown = false
+
+		switch {
+		case f.returnsByArg[arg.Name] != "" && !arg.Array:
+			col.Type = DataType{Name: f.returnsByArg[arg.Name]}
+		case f.returnsArg || f.returnsArray:
+			col.Type = arg.Clone()
+
+			// Postgres resolves domains to their underlying types
+			// in functions that accept any type.
+			col.Type.Domain = nil
+			col.Type.Modifiers = nil
+
+			if f.returnsArray {
+				col.Type.Array = true
+				col.Type.ArrayDims = max(col.Type.ArrayDims, 0) + 1
+			}
+		case f.returns != "":
+			col.Type = builtinReturnType(f.returns)
+		default:
+			return nil, ctx.Errorf(`unsupported argument type "%s" for function "%s" (hint: add an explicit type cast for the selected expression)`, typeString(arg), name)
+		}
+	} else {
+		col.Type = builtinReturnType(f.returns)
+	}
+
+	switch f.nulls {
+	case nullsNever:
+		col.Type.NotNull = true
+	case nullsAlways:
+		col.Type.NotNull = false
+	case nullsStrict:
+		col.Type.NotNull = true
+
+		for _, a := range args {
+			col.Type.NotNull = col.Type.NotNull && a.Type.NotNull
+		}
+	}
+
+	return &selection{Column: col}, nil
+}
+
+// builtinReturnType returns the data type of a return type name
+// of `builtinFunctionCatalog`.
+func builtinReturnType(name string) DataType {
+	if elem, ok := strings.CutSuffix(name, "[]"); ok {
+		return DataType{Name: elem, Array: true, ArrayDims: 1}
+	}
+
+	return DataType{Name: name}
+}
+
+// parseSQLValueFunctionSelection parses a function that is called without
+// parentheses, like `current_timestamp`. They never return null.
+func parseSQLValueFunctionSelection(ctx *QueryParseContext, fn *pg_query.SQLValueFunction) (*selection, error) {
+	c, ok := sqlValueFunctions[fn.GetOp()]
+	if !ok {
+		return nil, ctx.Errorf(`unsupported function "%s"`, fn.GetOp())
+	}
+
+	c.Type.NotNull = true
+	return &selection{Column: &c}, nil
+}
+
+// parseMinMaxSelection parses a `GREATEST` or `LEAST` expression. Null
+// arguments are ignored, so the result is only null if all arguments are.
+func parseMinMaxSelection(ctx *QueryParseContext, expr *pg_query.MinMaxExpr) (*selection, error) {
+	args, err := parseOperands(ctx, expr.GetArgs()...)
+	if err != nil {
+		return nil, err
+	}
+
+	col := &Column{Name: "greatest"}
+	if expr.GetOp() == pg_query.MinMaxOp_IS_LEAST {
+		col.Name = "least"
+	}
+
+	for i, a := range args {
+		if i == 0 {
+			col.Type = a.Type.Clone()
+			continue
+		}
+
+		t, ok := commonType(col.Type, a.Type)
+		if !ok {
+			return nil, ctx.Errorf(`%s types "%s" and "%s" cannot be matched`, strings.ToUpper(col.Name), typeString(col.Type), typeString(a.Type))
+		}
+
+		t.NotNull = col.Type.NotNull || a.Type.NotNull
+		col.Type = t
+	}
+
+	col.Type.unknown = false
+	return &selection{Column: col}, nil
+}
@@ -1,23 +1,101 @@
 package pg
 
+import (
+	"strings"
+
+	"github.com/koskimas/norsu/internal/ptr"
+)
+
 // Column represents a table column or any named property
 // that has a type, such as a selection.
 type Column struct {
 	Name string
 	Type DataType
+
+	// Default holds the SQL of the column's default expression if it has one.
+	// Serial columns get a `nextval(...)` default.
+	Default *string
+
+	// Identity is set for `GENERATED ... AS IDENTITY` columns.
+	Identity ColumnIdentity
+
+	// Generated holds the SQL of the expression of a
+	// `GENERATED ALWAYS AS (...) STORED` column.
+	Generated *string
+
+	// Comment holds the comment set using `COMMENT ON COLUMN`.
+	Comment *string
+}
+
+type ColumnIdentity string
+
+const (
+	ColumnIdentityAlways    ColumnIdentity = "always"
+	ColumnIdentityByDefault ColumnIdentity = "by default"
+)
+
+// HasDefault returns true if postgres fills the column with some value
+// when it's omitted from an insert.
+func (c *Column) HasDefault() bool {
+	return c.Default != nil || c.Identity != "" || c.Generated != nil ||
+		(c.Type.valueDomain() != nil && c.Type.Domain.Default != nil)
+}
+
+// IsGenerated returns true if the column can't be written to explicitly.
+// Identity columns generated always can still be written using
+// `OVERRIDING SYSTEM VALUE`.
+func (c *Column) IsGenerated() bool {
+	return c.Generated != nil || c.Identity == ColumnIdentityAlways
+}
+
+// IsRequired returns true if the column must be given a value in an insert.
+func (c *Column) IsRequired() bool {
+	return c.Type.NotNull && !c.HasDefault()
 }
 
 func (c *Column) Clone() *Column {
-	return &Column{
-		Name: c.Name,
-		Type: c.Type.Clone(),
+	col := &Column{
+		Name:     c.Name,
+		Type:     c.Type.Clone(),
+		Identity: c.Identity,
 	}
+
+	if c.Default != nil {
+		col.Default = ptr.V(*c.Default)
+	}
+
+	if c.Generated != nil {
+		col.Generated = ptr.V(*c.Generated)
+	}
+
+	if c.Comment != nil {
+		col.Comment = ptr.V(*c.Comment)
+	}
+
+	return col
 }
 
 func (c *Column) writeString(s *stringBuilder) {
 	s.WriteString(c.Name)
 	s.WriteString(" ")
 	c.Type.writeString(s)
+
+	if c.Default != nil {
+		s.WriteString(" DEFAULT ")
+		s.WriteString(*c.Default)
+	}
+
+	if c.Identity != "" {
+		s.WriteString(" GENERATED ")
+		s.WriteString(strings.ToUpper(string(c.Identity)))
+		s.WriteString(" AS IDENTITY")
+	}
+
+	if c.Generated != nil {
+		s.WriteString(" GENERATED ALWAYS AS (")
+		s.WriteString(*c.Generated)
+		s.WriteString(") STORED")
+	}
 }
 
 func (c *Column) String() string {
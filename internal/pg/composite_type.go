@@ -0,0 +1,34 @@
+package pg
+
+// CompositeType represents a composite type created using `CREATE TYPE ... AS (...)`.
+// The attributes of the type are stored as the columns of `Attributes`.
+type CompositeType struct {
+	Name       TypeName
+	Attributes *Table
+}
+
+func NewCompositeType(name TypeName, attributes *Table) *CompositeType {
+	return &CompositeType{
+		Name:       name,
+		Attributes: attributes,
+	}
+}
+
+func (c *CompositeType) Clone() *CompositeType {
+	return &CompositeType{
+		Name:       c.Name,
+		Attributes: c.Attributes.Clone(),
+	}
+}
+
+func (c *CompositeType) writeString(s *stringBuilder) {
+	c.Name.string(s)
+	s.WriteString(" ")
+	c.Attributes.writeString(s, true)
+}
+
+func (c *CompositeType) String() string {
+	var s stringBuilder
+	c.writeString(&s)
+	return s.String()
+}
@@ -0,0 +1,111 @@
+package pg
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/koskimas/norsu/internal/ptr"
+)
+
+// Constraint represents a table constraint created either inline in a column
+// definition, as a table constraint in `CREATE TABLE` or using
+// `ALTER TABLE ... ADD CONSTRAINT`.
+type Constraint struct {
+	Name string
+	Type ConstraintType
+
+	// Columns holds the constrained columns. For check constraints these
+	// are the columns referenced in the check expression.
+	Columns []string
+
+	// References holds the referenced table of a foreign key constraint.
+	References *TableName
+
+	// ReferencedColumns holds the referenced columns of a foreign key constraint.
+	ReferencedColumns []string
+
+	// Check holds the SQL of the expression of a check constraint.
+	Check *string
+}
+
+type ConstraintType string
+
+const (
+	ConstraintTypePrimaryKey ConstraintType = "primary key"
+	ConstraintTypeUnique     ConstraintType = "unique"
+	ConstraintTypeForeignKey ConstraintType = "foreign key"
+	ConstraintTypeCheck      ConstraintType = "check"
+)
+
+// HasColumn returns true if the constraint constrains the column `name`.
+func (c *Constraint) HasColumn(name string) bool {
+	return slices.Contains(c.Columns, name)
+}
+
+// RenameColumn renames the constrained column `name`.
+func (c *Constraint) RenameColumn(name string, newName string) {
+	for i := range c.Columns {
+		if c.Columns[i] == name {
+			c.Columns[i] = newName
+		}
+	}
+}
+
+// RenameReferencedColumn renames the referenced column `name` of a foreign key.
+func (c *Constraint) RenameReferencedColumn(name string, newName string) {
+	for i := range c.ReferencedColumns {
+		if c.ReferencedColumns[i] == name {
+			c.ReferencedColumns[i] = newName
+		}
+	}
+}
+
+func (c *Constraint) Clone() *Constraint {
+	clone := &Constraint{
+		Name:              c.Name,
+		Type:              c.Type,
+		Columns:           slices.Clone(c.Columns),
+		ReferencedColumns: slices.Clone(c.ReferencedColumns),
+	}
+
+	if c.References != nil {
+		clone.References = c.References.Clone()
+	}
+
+	if c.Check != nil {
+		clone.Check = ptr.V(*c.Check)
+	}
+
+	return clone
+}
+
+func (c *Constraint) writeString(s *stringBuilder) {
+	s.WriteString("CONSTRAINT ")
+	s.WriteString(c.Name)
+	s.WriteString(" ")
+	s.WriteString(strings.ToUpper(string(c.Type)))
+
+	if c.Check != nil {
+		s.WriteString(" (")
+		s.WriteString(*c.Check)
+		s.WriteString(")")
+	} else {
+		s.WriteString(" (")
+		s.WriteString(strings.Join(c.Columns, ", "))
+		s.WriteString(")")
+	}
+
+	if c.References != nil {
+		s.WriteString(" REFERENCES ")
+		c.References.string(s)
+		s.WriteString(" (")
+		s.WriteString(strings.Join(c.ReferencedColumns, ", "))
+		s.WriteString(")")
+	}
+}
+
+func (c *Constraint) String() string {
+	var s stringBuilder
+	c.writeString(&s)
+	return s.String()
+}
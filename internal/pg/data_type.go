@@ -1,5 +1,10 @@
 package pg
 
+import (
+	"slices"
+	"strconv"
+)
+
 const (
 	DataTypeJson   = "json"
 	DataTypeJsonb  = "jsonb"
@@ -54,6 +59,17 @@ var DataTypes = map[string]bool{
 	DataTypeRecord:                true,
 }
 
+// serialTypes maps the serial pseudo types to the integer types of the
+// columns they create.
+var serialTypes = map[string]string{
+	"smallserial": "int2",
+	"serial2":     "int2",
+	"serial":      "int4",
+	"serial4":     "int4",
+	"bigserial":   "int8",
+	"serial8":     "int8",
+}
+
 // DataType represents a postgres data type. Nested record and json types
 // are stored in the `Record` property.
 type DataType struct {
@@ -62,9 +78,19 @@ type DataType struct {
 	NotNull bool
 
 	// Array is true if the type is a postgres array. For example `INT[]`
-	// would produce a DataType `{ Name: "int", Array: true }`.
+	// would produce a DataType `{ Name: "int", Array: true, ArrayDims: 1 }`.
 	Array bool
 
+	// ArrayDims is the number of array dimensions written in the type. For
+	// example `INT[][]` has two dimensions. Postgres doesn't enforce the
+	// dimensions but they are kept so that the type can be written back.
+	ArrayDims int
+
+	// Modifiers holds the type modifiers like the length of `VARCHAR(64)` or
+	// the precision and scale of `NUMERIC(12, 2)`. Nil if the type has no
+	// modifiers or if they are not integers.
+	Modifiers []int
+
 	// Record holds the nested record type in case of a record,
 	// json or jsonb type.
 	Record *Table
@@ -72,19 +98,154 @@ type DataType struct {
 	// RecordArray is true if there's an array of records instead
 	// of a single record.
 	RecordArray bool
+
+	// Enum holds the enum type in case the type is an enum created
+	// using `CREATE TYPE ... AS ENUM`. The enum is shared with `DB`
+	// so that labels added later are visible through all columns.
+	Enum *Enum
+
+	// Domain holds the domain type in case the type is a domain created
+	// using `CREATE DOMAIN`. The other fields describe the underlying type
+	// of the domain in that case.
+	Domain *Domain
+
+	// Composite holds the composite type in case the type is a composite
+	// type created using `CREATE TYPE ... AS (...)`. `Record` holds the
+	// attributes of the type in that case.
+	Composite *CompositeType
+
+	// unknown is true for the types of `NULL` and string literals. Postgres
+	// infers their types from the context, for example from the other
+	// branches of a `UNION`.
+	unknown bool
+}
+
+// TypeName is the name of a user defined type such as an enum.
+type TypeName struct {
+	Name string
+	// Schema holds the schema of the type. An empty value means that
+	// the schema wasn't given. This value is not a pointer so that we
+	// can use `TypeName` as a map key.
+	Schema string
 }
 
 func (d *DataType) Json() bool {
 	return d.Name == DataTypeJson || d.Name == DataTypeJsonb
 }
 
+// valueDomain returns the domain of the type unless the type is an array of
+// the domain. The constraints and the default of a domain don't apply to an
+// array of it, only to its elements.
+func (d *DataType) valueDomain() *Domain {
+	if d.Domain == nil || d.ArrayDims > d.Domain.Type.ArrayDims {
+		return nil
+	}
+
+	return d.Domain
+}
+
+// TypeName returns the name of the data type as a `TypeName`.
+func (d *DataType) TypeName() TypeName {
+	if d.Schema != nil {
+		return NewTypeName(d.Name, *d.Schema)
+	}
+
+	return NewTypeName(d.Name)
+}
+
+// Length returns the maximum length of a character or bit string type like
+// `VARCHAR(64)`. For arrays the length of the elements is returned. The second
+// return value is false if the length is unlimited.
+func (d *DataType) Length() (int, bool) {
+	switch d.Name {
+	case "varchar", "bpchar", "bit", "varbit":
+		if len(d.Modifiers) == 1 {
+			return d.Modifiers[0], true
+		}
+	}
+
+	return 0, false
+}
+
+// HasSameType returns true if the types have the same name, modifiers and
+// array dimensions. Nullability is ignored.
+func (d *DataType) HasSameType(other *DataType) bool {
+	return d.Name == other.Name &&
+		d.Array == other.Array &&
+		d.ArrayDims == other.ArrayDims &&
+		slices.Equal(d.Modifiers, other.Modifiers)
+}
+
+// numericTypes holds the numeric types in the order in which postgres
+// promotes them when values of different numeric types are combined.
+var numericTypes = []string{"int2", "int4", "int8", "numeric", "float4", "float8"}
+
+// stringTypes holds the string types. Values of different string types
+// are combined as `text`.
+var stringTypes = []string{"text", "varchar", "bpchar"}
+
+// commonType returns the type postgres resolves for values of the types `a`
+// and `b` combined into one column, like in the branches of a `UNION`. Numeric
+// types are promoted to the wider type and different string types to `text`.
+// The second return value is false if the types can't be combined. The
+// nullability of the result is left for the caller.
+func commonType(a DataType, b DataType) (DataType, bool) {
+	if b.unknown {
+		return a.Clone(), true
+	} else if a.unknown {
+		return b.Clone(), true
+	}
+
+	if a.Name == b.Name && a.Array == b.Array && a.ArrayDims == b.ArrayDims && a.Enum == b.Enum && a.Composite == b.Composite {
+		t := a.Clone()
+
+		if a.Domain != b.Domain {
+			// Different domains are combined as their underlying type.
+			t.Domain = nil
+		}
+
+		if !slices.Equal(a.Modifiers, b.Modifiers) {
+			t.Modifiers = nil
+		}
+
+		return t, true
+	}
+
+	if a.Array || b.Array {
+		return DataType{}, false
+	}
+
+	if i, j := slices.Index(numericTypes, a.Name), slices.Index(numericTypes, b.Name); i != -1 && j != -1 {
+		t := a.Clone()
+		if j > i {
+			t = b.Clone()
+		}
+
+		t.Domain = nil
+		t.Modifiers = nil
+		return t, true
+	}
+
+	if slices.Contains(stringTypes, a.Name) && slices.Contains(stringTypes, b.Name) {
+		return DataType{Name: "text"}, true
+	}
+
+	return DataType{}, false
+}
+
 func (d *DataType) Clone() DataType {
 	clone := DataType{
 		Name:        d.Name,
 		NotNull:     d.NotNull,
 		Array:       d.Array,
+		ArrayDims:   d.ArrayDims,
+		Modifiers:   slices.Clone(d.Modifiers),
 		Schema:      d.Schema,
 		RecordArray: d.RecordArray,
+		Enum:        d.Enum,
+		Domain:      d.Domain,
+		Composite:   d.Composite,
+		unknown:     d.unknown,
 	}
 
 	if d.Record != nil {
@@ -95,14 +256,38 @@ func (d *DataType) Clone() DataType {
 }
 
 func (d *DataType) writeString(s *stringBuilder) {
-	if d.Schema != nil {
-		s.WriteString(*d.Schema)
-		s.WriteByte('.')
+	if d.Domain != nil {
+		d.Domain.Name.string(s)
+	} else {
+		if d.Schema != nil {
+			s.WriteString(*d.Schema)
+			s.WriteByte('.')
+		}
+
+		s.WriteString(d.Name)
+
+		if len(d.Modifiers) > 0 {
+			s.WriteByte('(')
+
+			for i, m := range d.Modifiers {
+				if i > 0 {
+					s.WriteString(", ")
+				}
+
+				s.WriteString(strconv.Itoa(m))
+			}
+
+			s.WriteByte(')')
+		}
 	}
 
-	s.WriteString(d.Name)
+	dims := d.ArrayDims
+	if d.Domain != nil {
+		// The dimensions of the domain are part of its name.
+		dims -= d.Domain.Type.ArrayDims
+	}
 
-	if d.Array {
+	for i := 0; i < dims; i++ {
 		s.WriteString("[]")
 	}
 
@@ -110,7 +295,8 @@ func (d *DataType) writeString(s *stringBuilder) {
 		s.WriteString(" not null")
 	}
 
-	if d.Record != nil {
+	// Composite types are written using their name only.
+	if d.Record != nil && d.Composite == nil {
 		s.WriteString(" ")
 		d.Record.writeString(s, true)
 	}
@@ -121,3 +307,33 @@ func (d *DataType) String() string {
 	d.writeString(&s)
 	return s.String()
 }
+
+func NewTypeName(name string, schema ...string) TypeName {
+	var t TypeName
+
+	t.Name = name
+	if len(schema) > 0 {
+		t.Schema = schema[0]
+	}
+
+	return t
+}
+
+func (n *TypeName) HasSchema() bool {
+	return len(n.Schema) != 0
+}
+
+func (n *TypeName) string(s *stringBuilder) {
+	if n.HasSchema() {
+		s.WriteString(n.Schema)
+		s.WriteByte('.')
+	}
+
+	s.WriteString(n.Name)
+}
+
+func (n *TypeName) String() string {
+	var s stringBuilder
+	n.string(&s)
+	return s.String()
+}
@@ -1,34 +1,234 @@
 package pg
 
 import (
+	"errors"
+	"fmt"
 	"slices"
+
+	"github.com/koskimas/norsu/internal/ptr"
 )
 
+const DefaultSchema = "public"
+
 type DB struct {
-	Tables       []*Table
-	TablesByName map[TableName]*Table
+	// Schemas holds the names of the schemas that exist in the database.
+	Schemas []string
+
+	// SearchPath holds the schemas in which unqualified names of tables
+	// and types are looked up like postgres's `search_path` setting.
+	// New objects are created in the first existing schema of the path.
+	SearchPath []string
+
+	Tables        []*Table
+	TablesByName  map[TableName]*Table
+	Enums         []*Enum
+	EnumsByName   map[TypeName]*Enum
+	Domains       []*Domain
+	DomainsByName map[TypeName]*Domain
+
+	CompositeTypes       []*CompositeType
+	CompositeTypesByName map[TypeName]*CompositeType
+
+	// Functions holds the user defined functions. FunctionsByName
+	// holds all overloads of each function name.
+	Functions       []*Function
+	FunctionsByName map[TypeName][]*Function
 }
 
 func NewDB() *DB {
 	return &DB{
-		Tables:       make([]*Table, 0),
-		TablesByName: make(map[TableName]*Table),
+		Schemas:    []string{DefaultSchema},
+		SearchPath: []string{DefaultSchema},
+
+		Tables:        make([]*Table, 0),
+		TablesByName:  make(map[TableName]*Table),
+		Enums:         make([]*Enum, 0),
+		EnumsByName:   make(map[TypeName]*Enum),
+		Domains:       make([]*Domain, 0),
+		DomainsByName: make(map[TypeName]*Domain),
+
+		CompositeTypes:       make([]*CompositeType, 0),
+		CompositeTypesByName: make(map[TypeName]*CompositeType),
+
+		Functions:       make([]*Function, 0),
+		FunctionsByName: make(map[TypeName][]*Function),
 	}
 }
 
 func (db *DB) Clone() *DB {
 	clone := &DB{
-		Tables:       make([]*Table, 0, len(db.Tables)),
-		TablesByName: make(map[TableName]*Table, len(db.Tables)),
+		Schemas:    slices.Clone(db.Schemas),
+		SearchPath: slices.Clone(db.SearchPath),
+
+		Tables:        make([]*Table, 0, len(db.Tables)),
+		TablesByName:  make(map[TableName]*Table, len(db.Tables)),
+		Enums:         make([]*Enum, 0, len(db.Enums)),
+		EnumsByName:   make(map[TypeName]*Enum, len(db.Enums)),
+		Domains:       make([]*Domain, 0, len(db.Domains)),
+		DomainsByName: make(map[TypeName]*Domain, len(db.Domains)),
+
+		CompositeTypes:       make([]*CompositeType, 0, len(db.CompositeTypes)),
+		CompositeTypesByName: make(map[TypeName]*CompositeType, len(db.CompositeTypes)),
+
+		Functions:       make([]*Function, 0, len(db.Functions)),
+		FunctionsByName: make(map[TypeName][]*Function, len(db.FunctionsByName)),
+	}
+
+	for _, e := range db.Enums {
+		clone.AddEnum(e.Clone())
+	}
+
+	for _, d := range db.Domains {
+		clone.AddDomain(d.Clone())
+	}
+
+	for _, c := range db.CompositeTypes {
+		clone.AddCompositeType(c.Clone())
 	}
 
 	for _, t := range db.Tables {
 		clone.AddTable(t.Clone())
 	}
 
+	for _, f := range db.Functions {
+		clone.AddFunction(f.Clone())
+	}
+
+	// Point the user defined types of the cloned columns to the cloned
+	// types so that altering a type of the clone doesn't affect this DB.
+	clone.ForEachDataType(clone.linkDataType)
 	return clone
 }
 
+// linkDataType points the user defined types of `d` to the types of this DB
+// with the same names.
+func (db *DB) linkDataType(d *DataType) {
+	if d.Enum != nil {
+		d.Enum = db.EnumsByName[d.Enum.Name]
+	}
+
+	if d.Domain != nil {
+		d.Domain = db.DomainsByName[d.Domain.Name]
+	}
+
+	if d.Composite != nil {
+		d.Composite = db.CompositeTypesByName[d.Composite.Name]
+		d.Record = d.Composite.Attributes
+	}
+}
+
+func (db *DB) HasSchema(schema string) bool {
+	return slices.Contains(db.Schemas, schema)
+}
+
+func (db *DB) AddSchema(schema string) {
+	db.Schemas = append(db.Schemas, schema)
+}
+
+func (db *DB) RemoveSchema(schema string) {
+	db.Schemas = slices.DeleteFunc(db.Schemas, func(s string) bool { return s == schema })
+}
+
+// CreationSchema returns the schema in which new objects with unqualified
+// names are created. This is the first existing schema of the search path.
+func (db *DB) CreationSchema() (string, error) {
+	for _, s := range db.SearchPath {
+		if db.HasSchema(s) {
+			return s, nil
+		}
+	}
+
+	return "", errors.New("no schema has been selected to create in")
+}
+
+// QualifyTableName returns the name of a new table or view. Unqualified names
+// are placed in the creation schema.
+func (db *DB) QualifyTableName(name TableName) (TableName, error) {
+	if !name.HasSchema() {
+		s, err := db.CreationSchema()
+		if err != nil {
+			return name, err
+		}
+
+		name.Schema = s
+	}
+
+	if !db.HasSchema(name.Schema) {
+		return name, fmt.Errorf(`schema "%s" does not exist`, name.Schema)
+	}
+
+	return name, nil
+}
+
+// QualifyTypeName returns the name of a new user defined type. Unqualified
+// names are placed in the creation schema.
+func (db *DB) QualifyTypeName(name TypeName) (TypeName, error) {
+	n, err := db.QualifyTableName(TableName(name))
+	return TypeName(n), err
+}
+
+// FindTable finds a table or a view. Unqualified names are first matched
+// against tables without a schema, like CTEs and subqueries, and then looked
+// up from the schemas of the search path.
+func (db *DB) FindTable(name TableName) *Table {
+	for _, n := range db.searchNames(name) {
+		if t := db.TablesByName[n]; t != nil {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// FindEnum finds an enum type using the search path like `FindTable`.
+func (db *DB) FindEnum(name TypeName) *Enum {
+	for _, n := range db.searchNames(TableName(name)) {
+		if e := db.EnumsByName[TypeName(n)]; e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// FindDomain finds a domain type using the search path like `FindTable`.
+func (db *DB) FindDomain(name TypeName) *Domain {
+	for _, n := range db.searchNames(TableName(name)) {
+		if d := db.DomainsByName[TypeName(n)]; d != nil {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// FindCompositeType finds a composite type using the search path like `FindTable`.
+func (db *DB) FindCompositeType(name TypeName) *CompositeType {
+	for _, n := range db.searchNames(TableName(name)) {
+		if c := db.CompositeTypesByName[TypeName(n)]; c != nil {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// searchNames returns the names to try in order when looking up an object.
+func (db *DB) searchNames(name TableName) []TableName {
+	if name.HasSchema() {
+		return []TableName{name}
+	}
+
+	names := make([]TableName, 0, len(db.SearchPath)+1)
+	names = append(names, name)
+
+	for _, s := range db.SearchPath {
+		names = append(names, NewTableName(name.Name, s))
+	}
+
+	return names
+}
+
 func (db *DB) AddTable(table *Table) {
 	db.TablesByName[*table.Name] = table
 	db.Tables = append(db.Tables, table)
@@ -39,15 +239,321 @@ func (db *DB) AddTableToFront(table *Table) {
 	db.Tables = append([]*Table{table}, db.Tables...)
 }
 
+// ReplaceTable replaces the table with the same name keeping its position.
+func (db *DB) ReplaceTable(table *Table) {
+	i := slices.IndexFunc(db.Tables, func(t *Table) bool { return *t.Name == *table.Name })
+	if i == -1 {
+		db.AddTable(table)
+		return
+	}
+
+	db.TablesByName[*table.Name] = table
+	db.Tables[i] = table
+}
+
 func (db *DB) RemoveTable(name TableName) {
 	delete(db.TablesByName, name)
 	db.Tables = slices.DeleteFunc(db.Tables, func(t *Table) bool { return *t.Name == name })
 }
 
+// RenameTable renames a table or a view and updates the dependencies
+// of views that select from it, the foreign keys that reference it and
+// the functions that return its rows.
 func (db *DB) RenameTable(name TableName, newName TableName) {
 	t := db.TablesByName[name]
 	delete(db.TablesByName, name)
 
 	t.Name = &newName
 	db.TablesByName[newName] = t
+
+	for _, v := range db.Tables {
+		for i := range v.DependsOn {
+			if v.DependsOn[i] == name {
+				v.DependsOn[i] = newName
+			}
+		}
+	}
+
+	for _, fk := range db.ForeignKeysTo(name) {
+		fk.Constraint.References = newName.Clone()
+	}
+
+	for _, c := range db.Children(name) {
+		for i := range c.Inherits {
+			if c.Inherits[i] == name {
+				c.Inherits[i] = newName
+			}
+		}
+
+		if c.PartitionOf != nil && *c.PartitionOf == name {
+			c.PartitionOf = newName.Clone()
+		}
+	}
+
+	for _, f := range db.Functions {
+		if f.Returns.Record == nil && f.Returns.TypeName() == TypeName(name) {
+			f.Returns.Name = newName.Name
+			f.Returns.Schema = ptr.V(newName.Schema)
+		}
+	}
+}
+
+// Children returns the tables that inherit from the table `name`
+// and the partitions of it.
+func (db *DB) Children(name TableName) []*Table {
+	children := make([]*Table, 0)
+
+	for _, t := range db.Tables {
+		if t.IsChildOf(name) {
+			children = append(children, t)
+		}
+	}
+
+	return children
+}
+
+// ForeignKey is a foreign key constraint together with the table it belongs to.
+type ForeignKey struct {
+	Table      *Table
+	Constraint *Constraint
+}
+
+// ForeignKeysTo returns the foreign key constraints that reference the
+// table `name`, including the ones of the table itself.
+func (db *DB) ForeignKeysTo(name TableName) []ForeignKey {
+	fks := make([]ForeignKey, 0)
+
+	for _, t := range db.Tables {
+		for _, c := range t.Constraints {
+			if c.References != nil && *c.References == name {
+				fks = append(fks, ForeignKey{Table: t, Constraint: c})
+			}
+		}
+	}
+
+	return fks
+}
+
+// Dependents returns the views that select from the relation `name`.
+func (db *DB) Dependents(name TableName) []*Table {
+	dependents := make([]*Table, 0)
+
+	for _, t := range db.Tables {
+		if t.DependsOnTable(name) {
+			dependents = append(dependents, t)
+		}
+	}
+
+	return dependents
+}
+
+func (db *DB) AddEnum(enum *Enum) {
+	db.EnumsByName[enum.Name] = enum
+	db.Enums = append(db.Enums, enum)
+}
+
+func (db *DB) RemoveEnum(name TypeName) {
+	delete(db.EnumsByName, name)
+	db.Enums = slices.DeleteFunc(db.Enums, func(e *Enum) bool { return e.Name == name })
+}
+
+// RenameEnum renames an enum and all column types that refer to it.
+func (db *DB) RenameEnum(name TypeName, newName TypeName) {
+	e := db.EnumsByName[name]
+	delete(db.EnumsByName, name)
+
+	e.Name = newName
+	db.EnumsByName[newName] = e
+
+	db.ForEachDataType(func(d *DataType) {
+		if d.Enum == e {
+			d.Name = newName.Name
+
+			if d.Schema != nil {
+				d.Schema = ptr.V(newName.Schema)
+			}
+		}
+	})
+}
+
+func (db *DB) AddDomain(domain *Domain) {
+	db.DomainsByName[domain.Name] = domain
+	db.Domains = append(db.Domains, domain)
+}
+
+func (db *DB) RemoveDomain(name TypeName) {
+	delete(db.DomainsByName, name)
+	db.Domains = slices.DeleteFunc(db.Domains, func(d *Domain) bool { return d.Name == name })
+}
+
+func (db *DB) RenameDomain(name TypeName, newName TypeName) {
+	d := db.DomainsByName[name]
+	delete(db.DomainsByName, name)
+
+	d.Name = newName
+	db.DomainsByName[newName] = d
+}
+
+func (db *DB) AddCompositeType(compositeType *CompositeType) {
+	db.CompositeTypesByName[compositeType.Name] = compositeType
+	db.CompositeTypes = append(db.CompositeTypes, compositeType)
+}
+
+func (db *DB) RemoveCompositeType(name TypeName) {
+	delete(db.CompositeTypesByName, name)
+	db.CompositeTypes = slices.DeleteFunc(db.CompositeTypes, func(c *CompositeType) bool { return c.Name == name })
+}
+
+// RenameCompositeType renames a composite type and all column types that refer to it.
+func (db *DB) RenameCompositeType(name TypeName, newName TypeName) {
+	c := db.CompositeTypesByName[name]
+	delete(db.CompositeTypesByName, name)
+
+	c.Name = newName
+	db.CompositeTypesByName[newName] = c
+
+	db.ForEachDataType(func(d *DataType) {
+		if d.Composite == c {
+			d.Name = newName.Name
+
+			if d.Schema != nil {
+				d.Schema = ptr.V(newName.Schema)
+			}
+		}
+	})
+}
+
+func (db *DB) AddFunction(function *Function) {
+	db.FunctionsByName[function.Name] = append(db.FunctionsByName[function.Name], function)
+	db.Functions = append(db.Functions, function)
+}
+
+// ReplaceFunction replaces the overload of `function` that has the same
+// argument types keeping its position.
+func (db *DB) ReplaceFunction(function *Function) {
+	overloads := db.FunctionsByName[function.Name]
+
+	i := slices.IndexFunc(overloads, function.HasSameArgs)
+	if i == -1 {
+		db.AddFunction(function)
+		return
+	}
+
+	j := slices.Index(db.Functions, overloads[i])
+	overloads[i] = function
+	db.Functions[j] = function
+}
+
+func (db *DB) RemoveFunction(function *Function) {
+	overloads := slices.DeleteFunc(db.FunctionsByName[function.Name], func(f *Function) bool { return f == function })
+
+	if len(overloads) == 0 {
+		delete(db.FunctionsByName, function.Name)
+	} else {
+		db.FunctionsByName[function.Name] = overloads
+	}
+
+	db.Functions = slices.DeleteFunc(db.Functions, func(f *Function) bool { return f == function })
+}
+
+func (db *DB) RenameFunction(function *Function, newName TypeName) {
+	db.RemoveFunction(function)
+	function.Name = newName
+	db.AddFunction(function)
+}
+
+// FindFunctions finds the overloads of a function using the search path. Like
+// postgres, the overloads of the first schema that has a function with the
+// name are returned.
+func (db *DB) FindFunctions(name TypeName) []*Function {
+	for _, n := range db.searchNames(TableName(name)) {
+		if f := db.FunctionsByName[TypeName(n)]; len(f) > 0 {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// FindFunction finds the overload of a function that can be called with `args`
+// arguments. Nil is returned if there is no such overload. The first matching
+// overload is returned if there are more than one since argument types are not
+// known.
+func (db *DB) FindFunction(name TypeName, args int) *Function {
+	for _, f := range db.FindFunctions(name) {
+		if f.Accepts(args) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// UserTypeNames returns the names of all user defined types.
+func (db *DB) UserTypeNames() []TypeName {
+	names := make([]TypeName, 0, len(db.Enums)+len(db.Domains)+len(db.CompositeTypes))
+
+	for _, e := range db.Enums {
+		names = append(names, e.Name)
+	}
+
+	for _, d := range db.Domains {
+		names = append(names, d.Name)
+	}
+
+	for _, c := range db.CompositeTypes {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
+// resolveDataType resolves the user defined type `t` refers to. Domains are
+// replaced by their underlying type. The attributes of composite types are
+// shared with the column types so that altering the type is visible through
+// all columns.
+func (db *DB) resolveDataType(t *DataType) {
+	name := t.TypeName()
+
+	if d := db.FindDomain(name); d != nil {
+		resolved := d.Type.Clone()
+		resolved.Domain = d
+		// An array of a domain can be null even if its elements can't.
+		resolved.NotNull = t.NotNull || (!t.Array && d.Type.NotNull)
+		resolved.Array = t.Array || d.Type.Array
+		resolved.ArrayDims = t.ArrayDims + d.Type.ArrayDims
+
+		*t = resolved
+		return
+	}
+
+	if c := db.FindCompositeType(name); c != nil {
+		t.Composite = c
+		t.Record = c.Attributes
+		t.RecordArray = t.Array
+	}
+
+	t.Enum = db.FindEnum(name)
+}
+
+// ForEachDataType calls `f` for each data type in the database. This includes
+// the underlying types of domains, the attribute types of composite types,
+// the column types of tables including the columns of nested records and
+// the argument and return types of functions.
+func (db *DB) ForEachDataType(f func(*DataType)) {
+	for _, d := range db.Domains {
+		f(&d.Type)
+	}
+
+	for _, c := range db.CompositeTypes {
+		c.Attributes.ForEachDataType(f)
+	}
+
+	for _, t := range db.Tables {
+		t.ForEachDataType(f)
+	}
+
+	for _, fn := range db.Functions {
+		fn.ForEachDataType(f)
+	}
 }
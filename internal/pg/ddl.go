@@ -0,0 +1,323 @@
+package pg
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/koskimas/norsu/internal/ptr"
+)
+
+// DDL returns the schema as normalized `CREATE` statements. The objects are
+// ordered by their names so that the output can be compared with other
+// schemas. Only the parts of the schema norsu knows about are included, which
+// means that function bodies and view queries are left out and views are
+// written with their derived columns like tables.
+func (db *DB) DDL() string {
+	var s stringBuilder
+
+	schemas := slices.Clone(db.Schemas)
+	slices.Sort(schemas)
+
+	for _, schema := range schemas {
+		if schema != DefaultSchema {
+			s.WriteString("CREATE SCHEMA ")
+			s.WriteString(schema)
+			s.WriteString(";")
+			s.WriteNewLine()
+			s.WriteNewLine()
+		}
+	}
+
+	for _, e := range sortedByName(db.Enums, func(e *Enum) string { return e.Name.String() }) {
+		e.writeDDL(&s)
+	}
+
+	for _, d := range sortedByName(db.Domains, func(d *Domain) string { return d.Name.String() }) {
+		d.writeDDL(&s)
+	}
+
+	for _, c := range sortedByName(db.CompositeTypes, func(c *CompositeType) string { return c.Name.String() }) {
+		c.writeDDL(&s)
+	}
+
+	for _, t := range sortedByName(db.Tables, func(t *Table) string { return t.Name.String() }) {
+		t.writeDDL(&s)
+	}
+
+	for _, f := range sortedByName(db.Functions, (*Function).Signature) {
+		f.writeDDL(&s)
+	}
+
+	// Drop the empty line after the last statement.
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+func sortedByName[T any](items []T, name func(T) string) []T {
+	sorted := slices.Clone(items)
+
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		return cmp.Compare(name(a), name(b))
+	})
+
+	return sorted
+}
+
+func (e *Enum) writeDDL(s *stringBuilder) {
+	s.WriteString("CREATE TYPE ")
+	e.Name.string(s)
+	s.WriteString(" AS ENUM (")
+
+	for i, l := range e.Labels {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+
+		s.WriteString(quoteLiteral(l))
+	}
+
+	s.WriteString(");")
+	s.WriteNewLine()
+	s.WriteNewLine()
+}
+
+func (d *Domain) writeDDL(s *stringBuilder) {
+	s.WriteString("CREATE DOMAIN ")
+	d.Name.string(s)
+	s.WriteString(" AS ")
+	s.WriteString(ddlTypeString(d.Type))
+
+	if d.Type.NotNull {
+		s.WriteString(" NOT NULL")
+	}
+
+	if d.Default != nil {
+		s.WriteString(" DEFAULT ")
+		s.WriteString(*d.Default)
+	}
+
+	s.WriteString(";")
+	s.WriteNewLine()
+	s.WriteNewLine()
+}
+
+func (c *CompositeType) writeDDL(s *stringBuilder) {
+	s.WriteString("CREATE TYPE ")
+	c.Name.string(s)
+	s.WriteString(" AS ")
+	c.Attributes.writeDDLColumns(s)
+	s.WriteString(";")
+	s.WriteNewLine()
+	c.Attributes.writeDDLComments(s, "TYPE", c.Name.String())
+	s.WriteNewLine()
+}
+
+func (t *Table) writeDDL(s *stringBuilder) {
+	s.WriteString("CREATE ")
+	s.WriteString(strings.ToUpper(string(t.Kind)))
+	s.WriteString(" ")
+	t.Name.string(s)
+	s.WriteString(" ")
+	t.writeDDLColumns(s)
+
+	if t.PartitionOf != nil {
+		s.WriteString(" PARTITION OF ")
+		t.PartitionOf.string(s)
+	}
+
+	if len(t.Inherits) > 0 {
+		s.WriteString(" INHERITS (")
+
+		for i, p := range t.Inherits {
+			if i > 0 {
+				s.WriteString(", ")
+			}
+
+			p.string(s)
+		}
+
+		s.WriteString(")")
+	}
+
+	s.WriteString(";")
+	s.WriteNewLine()
+	t.writeDDLComments(s, strings.ToUpper(string(t.Kind)), t.Name.String())
+	s.WriteNewLine()
+}
+
+// writeDDLComments writes the `COMMENT ON` statements of a table or
+// a composite type. `object` is the object type used for the comment
+// of the table itself.
+func (t *Table) writeDDLComments(s *stringBuilder, object string, name string) {
+	if t.Comment != nil {
+		s.WriteString("COMMENT ON ")
+		s.WriteString(object)
+		s.WriteString(" ")
+		s.WriteString(name)
+		s.WriteString(" IS ")
+		s.WriteString(quoteLiteral(*t.Comment))
+		s.WriteString(";")
+		s.WriteNewLine()
+	}
+
+	for _, c := range t.Columns {
+		if c.Comment != nil {
+			s.WriteString("COMMENT ON COLUMN ")
+			s.WriteString(name)
+			s.WriteString(".")
+			s.WriteString(c.Name)
+			s.WriteString(" IS ")
+			s.WriteString(quoteLiteral(*c.Comment))
+			s.WriteString(";")
+			s.WriteNewLine()
+		}
+	}
+}
+
+// writeDDLColumns writes the column and constraint list of a table
+// or a composite type.
+func (t *Table) writeDDLColumns(s *stringBuilder) {
+	s.WriteString("(")
+	s.WriteNewLine()
+	s.Indent()
+
+	for i, c := range t.Columns {
+		c.writeDDL(s)
+
+		if i != len(t.Columns)-1 || len(t.Constraints) > 0 {
+			s.WriteString(",")
+		}
+
+		s.WriteNewLine()
+	}
+
+	for i, c := range t.Constraints {
+		c.writeString(s)
+
+		if i != len(t.Constraints)-1 {
+			s.WriteString(",")
+		}
+
+		s.WriteNewLine()
+	}
+
+	s.DeIndent()
+	s.WriteString(")")
+}
+
+func (c *Column) writeDDL(s *stringBuilder) {
+	s.WriteString(c.Name)
+	s.WriteString(" ")
+	s.WriteString(ddlTypeString(c.Type))
+
+	if c.Type.NotNull {
+		s.WriteString(" NOT NULL")
+	}
+
+	if c.Default != nil {
+		s.WriteString(" DEFAULT ")
+		s.WriteString(*c.Default)
+	}
+
+	if c.Identity != "" {
+		s.WriteString(" GENERATED ")
+		s.WriteString(strings.ToUpper(string(c.Identity)))
+		s.WriteString(" AS IDENTITY")
+	}
+
+	if c.Generated != nil {
+		s.WriteString(" GENERATED ALWAYS AS (")
+		s.WriteString(*c.Generated)
+		s.WriteString(") STORED")
+	}
+}
+
+func (f *Function) writeDDL(s *stringBuilder) {
+	s.WriteString("CREATE FUNCTION ")
+	f.Name.string(s)
+	s.WriteString("(")
+
+	args := make([]string, 0, len(f.Args))
+
+	for i, a := range f.Args {
+		arg := ddlTypeString(a.Type)
+
+		if len(a.Name) != 0 {
+			arg = a.Name + " " + arg
+		}
+
+		if f.Variadic && i == len(f.Args)-1 {
+			arg = "VARIADIC " + arg
+		}
+
+		if a.Default != nil {
+			arg += " DEFAULT " + *a.Default
+		}
+
+		args = append(args, arg)
+	}
+
+	record := f.Returns.Record != nil && f.Returns.Composite == nil
+
+	if record && !f.ReturnsSet {
+		// Functions that return a single record use `OUT` arguments.
+		for _, c := range f.Returns.Record.Columns {
+			args = append(args, "OUT "+c.Name+" "+ddlTypeString(c.Type))
+		}
+	}
+
+	s.WriteString(strings.Join(args, ", "))
+	s.WriteString(") RETURNS ")
+
+	if record && f.ReturnsSet {
+		s.WriteString("TABLE (")
+
+		for i, c := range f.Returns.Record.Columns {
+			if i > 0 {
+				s.WriteString(", ")
+			}
+
+			s.WriteString(c.Name)
+			s.WriteString(" ")
+			s.WriteString(ddlTypeString(c.Type))
+		}
+
+		s.WriteString(")")
+	} else {
+		if f.ReturnsSet {
+			s.WriteString("SETOF ")
+		}
+
+		s.WriteString(ddlTypeString(f.Returns))
+	}
+
+	s.WriteString(";")
+	s.WriteNewLine()
+	s.WriteNewLine()
+}
+
+// ddlTypeString returns the name of a data type as it's written in DDL.
+// Nullability and nested records are left out and the names of user
+// defined types are qualified.
+func ddlTypeString(t DataType) string {
+	var name *TypeName
+
+	if t.Enum != nil {
+		name = &t.Enum.Name
+	} else if t.Composite != nil {
+		name = &t.Composite.Name
+	}
+
+	if name != nil {
+		t.Name = name.Name
+		t.Schema = ptr.V(name.Schema)
+	}
+
+	t.NotNull = false
+	t.Record = nil
+	return t.String()
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,251 @@
+package pg
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaChange is a difference between two schemas found by `DiffSchemas`.
+type SchemaChange struct {
+	Kind ChangeKind
+
+	// Object is the kind of the changed object like "table", "column" or "enum".
+	Object string
+
+	// Name is the qualified name of the object. Columns are named like
+	// `schema.table.column`.
+	Name string
+
+	// Property is the changed property of the object for changes of
+	// the kind `ChangeKindChanged`. For example "type" or "nullability".
+	Property string
+
+	// From and To hold the old and the new value of the changed property.
+	From string
+	To   string
+}
+
+type ChangeKind string
+
+const (
+	ChangeKindAdded   ChangeKind = "added"
+	ChangeKindRemoved ChangeKind = "removed"
+	ChangeKindChanged ChangeKind = "changed"
+)
+
+func (c *SchemaChange) String() string {
+	if c.Kind == ChangeKindChanged {
+		return fmt.Sprintf(`%s of %s "%s" changed from %s to %s`, c.Property, c.Object, c.Name, c.From, c.To)
+	}
+
+	return fmt.Sprintf(`%s "%s" was %s`, c.Object, c.Name, c.Kind)
+}
+
+// DiffSchemas returns the differences between the schemas `from` and `to` that
+// affect types: schemas, tables and views, columns and their types and
+// nullability, user defined types and functions.
+func DiffSchemas(from *DB, to *DB) []SchemaChange {
+	var d schemaDiff
+
+	d.diffSchemas(from, to)
+	d.diffTables(from, to)
+	d.diffEnums(from, to)
+	d.diffDomains(from, to)
+	d.diffCompositeTypes(from, to)
+	d.diffFunctions(from, to)
+
+	return d.changes
+}
+
+// DiffColumns returns the differences between the columns of `from` and `to`,
+// such as the output columns of two versions of a query. The changed columns
+// are named like `name.column`.
+func DiffColumns(name string, from *Table, to *Table) []SchemaChange {
+	var d schemaDiff
+	d.diffColumns("column", name, from, to)
+	return d.changes
+}
+
+type schemaDiff struct {
+	changes []SchemaChange
+}
+
+func (d *schemaDiff) added(object string, name string) {
+	d.changes = append(d.changes, SchemaChange{Kind: ChangeKindAdded, Object: object, Name: name})
+}
+
+func (d *schemaDiff) removed(object string, name string) {
+	d.changes = append(d.changes, SchemaChange{Kind: ChangeKindRemoved, Object: object, Name: name})
+}
+
+func (d *schemaDiff) changed(object string, name string, property string, from string, to string) {
+	if from == to {
+		return
+	}
+
+	d.changes = append(d.changes, SchemaChange{
+		Kind:     ChangeKindChanged,
+		Object:   object,
+		Name:     name,
+		Property: property,
+		From:     from,
+		To:       to,
+	})
+}
+
+func (d *schemaDiff) diffSchemas(from *DB, to *DB) {
+	for _, s := range from.Schemas {
+		if !to.HasSchema(s) {
+			d.removed("schema", s)
+		}
+	}
+
+	for _, s := range to.Schemas {
+		if !from.HasSchema(s) {
+			d.added("schema", s)
+		}
+	}
+}
+
+func (d *schemaDiff) diffTables(from *DB, to *DB) {
+	for _, t := range from.Tables {
+		other := to.TablesByName[*t.Name]
+		if other == nil {
+			d.removed(string(t.Kind), t.Name.String())
+			continue
+		}
+
+		d.changed(string(t.Kind), t.Name.String(), "kind", string(t.Kind), string(other.Kind))
+		d.diffColumns("column", t.Name.String(), t, other)
+	}
+
+	for _, t := range to.Tables {
+		if from.TablesByName[*t.Name] == nil {
+			d.added(string(t.Kind), t.Name.String())
+		}
+	}
+}
+
+// diffColumns compares the columns of tables or the attributes of composite
+// types. `object` is the kind of the compared columns.
+func (d *schemaDiff) diffColumns(object string, tableName string, from *Table, to *Table) {
+	for _, c := range from.Columns {
+		name := tableName + "." + c.Name
+
+		other := to.ColumnsByName[c.Name]
+		if other == nil {
+			d.removed(object, name)
+			continue
+		}
+
+		d.changed(object, name, "type", typeString(c.Type), typeString(other.Type))
+		d.changed(object, name, "nullability", nullabilityString(c.Type), nullabilityString(other.Type))
+	}
+
+	for _, c := range to.Columns {
+		if from.ColumnsByName[c.Name] == nil {
+			d.added(object, tableName+"."+c.Name)
+		}
+	}
+}
+
+func (d *schemaDiff) diffEnums(from *DB, to *DB) {
+	for _, e := range from.Enums {
+		other := to.EnumsByName[e.Name]
+		if other == nil {
+			d.removed("enum", e.Name.String())
+			continue
+		}
+
+		d.changed("enum", e.Name.String(), "labels", labelsString(e.Labels), labelsString(other.Labels))
+	}
+
+	for _, e := range to.Enums {
+		if from.EnumsByName[e.Name] == nil {
+			d.added("enum", e.Name.String())
+		}
+	}
+}
+
+func (d *schemaDiff) diffDomains(from *DB, to *DB) {
+	for _, dom := range from.Domains {
+		other := to.DomainsByName[dom.Name]
+		if other == nil {
+			d.removed("domain", dom.Name.String())
+			continue
+		}
+
+		d.changed("domain", dom.Name.String(), "type", typeString(dom.Type), typeString(other.Type))
+		d.changed("domain", dom.Name.String(), "nullability", nullabilityString(dom.Type), nullabilityString(other.Type))
+	}
+
+	for _, dom := range to.Domains {
+		if from.DomainsByName[dom.Name] == nil {
+			d.added("domain", dom.Name.String())
+		}
+	}
+}
+
+func (d *schemaDiff) diffCompositeTypes(from *DB, to *DB) {
+	for _, c := range from.CompositeTypes {
+		other := to.CompositeTypesByName[c.Name]
+		if other == nil {
+			d.removed("composite type", c.Name.String())
+			continue
+		}
+
+		d.diffColumns("attribute", c.Name.String(), c.Attributes, other.Attributes)
+	}
+
+	for _, c := range to.CompositeTypes {
+		if from.CompositeTypesByName[c.Name] == nil {
+			d.added("composite type", c.Name.String())
+		}
+	}
+}
+
+func (d *schemaDiff) diffFunctions(from *DB, to *DB) {
+	for _, f := range from.Functions {
+		i := slices.IndexFunc(to.FunctionsByName[f.Name], f.HasSameArgs)
+		if i == -1 {
+			d.removed("function", f.Signature())
+			continue
+		}
+
+		other := to.FunctionsByName[f.Name][i]
+		d.changed("function", f.Signature(), "return type", returnTypeString(f), returnTypeString(other))
+	}
+
+	for _, f := range to.Functions {
+		if !slices.ContainsFunc(from.FunctionsByName[f.Name], f.HasSameArgs) {
+			d.added("function", f.Signature())
+		}
+	}
+}
+
+// typeString returns the name of a data type without its nullability.
+func typeString(t DataType) string {
+	t.NotNull = false
+	return t.String()
+}
+
+func nullabilityString(t DataType) string {
+	if t.NotNull {
+		return "not null"
+	}
+
+	return "null"
+}
+
+func labelsString(labels []string) string {
+	return "(" + strings.Join(labels, ", ") + ")"
+}
+
+func returnTypeString(f *Function) string {
+	if f.ReturnsSet {
+		return "setof " + typeString(f.Returns)
+	}
+
+	return typeString(f.Returns)
+}
@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"github.com/koskimas/norsu/internal/ptr"
+)
+
+// Domain represents a domain type created using `CREATE DOMAIN`. `Type` holds
+// the underlying data type of the domain. `Type.NotNull` is true if the domain
+// has a `NOT NULL` constraint.
+type Domain struct {
+	Name TypeName
+	Type DataType
+
+	// Default holds the SQL of the domain's default expression if it has one.
+	Default *string
+}
+
+func NewDomain(name TypeName, dataType DataType) *Domain {
+	return &Domain{
+		Name: name,
+		Type: dataType,
+	}
+}
+
+func (d *Domain) Clone() *Domain {
+	clone := &Domain{
+		Name: d.Name,
+		Type: d.Type.Clone(),
+	}
+
+	if d.Default != nil {
+		clone.Default = ptr.V(*d.Default)
+	}
+
+	return clone
+}
+
+func (d *Domain) writeString(s *stringBuilder) {
+	d.Name.string(s)
+	s.WriteString(" ")
+	d.Type.writeString(s)
+}
+
+func (d *Domain) String() string {
+	var s stringBuilder
+	d.writeString(&s)
+	return s.String()
+}
@@ -0,0 +1,72 @@
+package pg
+
+import (
+	"slices"
+)
+
+// Enum represents an enum type created using `CREATE TYPE ... AS ENUM`.
+type Enum struct {
+	Name   TypeName
+	Labels []string
+}
+
+func NewEnum(name TypeName, labels ...string) *Enum {
+	return &Enum{
+		Name:   name,
+		Labels: labels,
+	}
+}
+
+func (e *Enum) HasLabel(label string) bool {
+	return slices.Contains(e.Labels, label)
+}
+
+// AddLabel adds a new label to the enum. If `neighbor` is not empty, the label is
+// added before or after it depending on `after`. Otherwise the label is appended.
+func (e *Enum) AddLabel(label string, neighbor string, after bool) {
+	i := slices.Index(e.Labels, neighbor)
+
+	if len(neighbor) == 0 || i == -1 {
+		e.Labels = append(e.Labels, label)
+	} else if after {
+		e.Labels = slices.Insert(e.Labels, i+1, label)
+	} else {
+		e.Labels = slices.Insert(e.Labels, i, label)
+	}
+}
+
+func (e *Enum) RenameLabel(label string, newLabel string) {
+	if i := slices.Index(e.Labels, label); i != -1 {
+		e.Labels[i] = newLabel
+	}
+}
+
+func (e *Enum) Clone() *Enum {
+	return &Enum{
+		Name:   e.Name,
+		Labels: slices.Clone(e.Labels),
+	}
+}
+
+func (e *Enum) writeString(s *stringBuilder) {
+	e.Name.string(s)
+	s.WriteString(" (")
+
+	for i, l := range e.Labels {
+		s.WriteString("'")
+		s.WriteString(l)
+		s.WriteString("'")
+
+		if i != len(e.Labels)-1 {
+			s.WriteString(", ")
+		}
+	}
+
+	s.WriteString(")")
+}
+
+func (e *Enum) String() string {
+	var s stringBuilder
+	e.writeString(&s)
+	return s.String()
+}
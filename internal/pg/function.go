@@ -0,0 +1,166 @@
+package pg
+
+// Function represents a function created using `CREATE FUNCTION`. Functions
+// can be overloaded so there can be multiple functions with the same name.
+type Function struct {
+	Name TypeName
+
+	// Args holds the input arguments of the function. Arguments that have
+	// a default value have a non-nil `Default`. Unnamed arguments have an
+	// empty name.
+	Args []*Column
+
+	// Variadic is true if the last argument is a `VARIADIC` argument.
+	Variadic bool
+
+	// Returns holds the return type of the function. `Record` holds the
+	// columns of functions that use `RETURNS TABLE (...)` or `OUT` arguments.
+	// Functions that return the row type of a table are resolved when they
+	// are called so that later changes to the table are visible.
+	Returns DataType
+
+	// ReturnsSet is true for set returning functions that use `RETURNS SETOF`
+	// or `RETURNS TABLE (...)`.
+	ReturnsSet bool
+}
+
+func NewFunction(name TypeName) *Function {
+	return &Function{
+		Name: name,
+		Args: make([]*Column, 0),
+	}
+}
+
+// RequiredArgs returns the number of arguments that don't have a default value.
+func (f *Function) RequiredArgs() int {
+	n := 0
+
+	for _, a := range f.Args {
+		if a.Default == nil {
+			n++
+		}
+	}
+
+	if f.Variadic && n == len(f.Args) {
+		// A variadic argument can be left out.
+		n--
+	}
+
+	return n
+}
+
+// Accepts returns true if the function can be called with `n` arguments.
+func (f *Function) Accepts(n int) bool {
+	return n >= f.RequiredArgs() && (f.Variadic || n <= len(f.Args))
+}
+
+// HasSameArgs returns true if the functions have the same argument types.
+// Postgres doesn't allow two such functions with the same name.
+func (f *Function) HasSameArgs(other *Function) bool {
+	if len(f.Args) != len(other.Args) {
+		return false
+	}
+
+	for i := range f.Args {
+		a := &f.Args[i].Type
+		b := &other.Args[i].Type
+
+		if a.TypeName() != b.TypeName() || a.Array != b.Array || a.Domain != b.Domain {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Signature returns the name and the argument types of the function like
+// `public.search_people(text)`, which identify the function.
+func (f *Function) Signature() string {
+	var s stringBuilder
+
+	f.Name.string(&s)
+	s.WriteString("(")
+
+	for i, a := range f.Args {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+
+		t := a.Type
+		t.NotNull = false
+		t.writeString(&s)
+	}
+
+	s.WriteString(")")
+	return s.String()
+}
+
+func (f *Function) Clone() *Function {
+	clone := &Function{
+		Name:       f.Name,
+		Args:       make([]*Column, 0, len(f.Args)),
+		Variadic:   f.Variadic,
+		Returns:    f.Returns.Clone(),
+		ReturnsSet: f.ReturnsSet,
+	}
+
+	for _, a := range f.Args {
+		clone.Args = append(clone.Args, a.Clone())
+	}
+
+	return clone
+}
+
+// ForEachDataType calls `f` for the argument types and the return type.
+func (f *Function) ForEachDataType(fn func(*DataType)) {
+	for _, a := range f.Args {
+		fn(&a.Type)
+	}
+
+	fn(&f.Returns)
+
+	if f.Returns.Record != nil {
+		f.Returns.Record.ForEachDataType(fn)
+	}
+}
+
+func (f *Function) writeString(s *stringBuilder) {
+	f.Name.string(s)
+	s.WriteString("(")
+
+	for i, a := range f.Args {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+
+		if f.Variadic && i == len(f.Args)-1 {
+			s.WriteString("VARIADIC ")
+		}
+
+		if len(a.Name) != 0 {
+			s.WriteString(a.Name)
+			s.WriteString(" ")
+		}
+
+		a.Type.writeString(s)
+
+		if a.Default != nil {
+			s.WriteString(" DEFAULT ")
+			s.WriteString(*a.Default)
+		}
+	}
+
+	s.WriteString(") RETURNS ")
+
+	if f.ReturnsSet {
+		s.WriteString("SETOF ")
+	}
+
+	f.Returns.writeString(s)
+}
+
+func (f *Function) String() string {
+	var s stringBuilder
+	f.writeString(&s)
+	return s.String()
+}
@@ -0,0 +1,199 @@
+package pg
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/koskimas/norsu/internal/ptr"
+)
+
+// The JSON representation of a `DB`. The objects are ordered the same way
+// as in `DDL`. Data types are written as their names.
+type (
+	dbJson struct {
+		Schemas        []string            `json:"schemas"`
+		Enums          []enumJson          `json:"enums"`
+		Domains        []domainJson        `json:"domains"`
+		CompositeTypes []compositeTypeJson `json:"compositeTypes"`
+		Tables         []tableJson         `json:"tables"`
+		Functions      []functionJson      `json:"functions"`
+	}
+
+	enumJson struct {
+		Name   string   `json:"name"`
+		Labels []string `json:"labels"`
+	}
+
+	domainJson struct {
+		Name    string  `json:"name"`
+		Type    string  `json:"type"`
+		NotNull bool    `json:"notNull"`
+		Default *string `json:"default,omitempty"`
+	}
+
+	compositeTypeJson struct {
+		Name       string       `json:"name"`
+		Attributes []columnJson `json:"attributes"`
+	}
+
+	tableJson struct {
+		Name        string           `json:"name"`
+		Kind        TableKind        `json:"kind"`
+		Columns     []columnJson     `json:"columns"`
+		Constraints []constraintJson `json:"constraints"`
+		Inherits    []string         `json:"inherits,omitempty"`
+		PartitionOf *string          `json:"partitionOf,omitempty"`
+		Partitioned bool             `json:"partitioned,omitempty"`
+		Comment     *string          `json:"comment,omitempty"`
+	}
+
+	columnJson struct {
+		Name      string         `json:"name"`
+		Type      string         `json:"type"`
+		NotNull   bool           `json:"notNull"`
+		Default   *string        `json:"default,omitempty"`
+		Identity  ColumnIdentity `json:"identity,omitempty"`
+		Generated *string        `json:"generated,omitempty"`
+		Comment   *string        `json:"comment,omitempty"`
+	}
+
+	constraintJson struct {
+		Name              string         `json:"name"`
+		Type              ConstraintType `json:"type"`
+		Columns           []string       `json:"columns"`
+		References        *string        `json:"references,omitempty"`
+		ReferencedColumns []string       `json:"referencedColumns,omitempty"`
+		Check             *string        `json:"check,omitempty"`
+	}
+
+	functionJson struct {
+		Name       string       `json:"name"`
+		Signature  string       `json:"signature"`
+		Args       []columnJson `json:"args"`
+		Variadic   bool         `json:"variadic,omitempty"`
+		Returns    string       `json:"returns"`
+		ReturnsSet bool         `json:"returnsSet,omitempty"`
+
+		// Columns holds the columns of functions that return a record
+		// using `RETURNS TABLE (...)` or `OUT` arguments.
+		Columns []columnJson `json:"columns,omitempty"`
+	}
+)
+
+func (db *DB) MarshalJSON() ([]byte, error) {
+	out := dbJson{
+		Schemas:        slices.Clone(db.Schemas),
+		Enums:          make([]enumJson, 0, len(db.Enums)),
+		Domains:        make([]domainJson, 0, len(db.Domains)),
+		CompositeTypes: make([]compositeTypeJson, 0, len(db.CompositeTypes)),
+		Tables:         make([]tableJson, 0, len(db.Tables)),
+		Functions:      make([]functionJson, 0, len(db.Functions)),
+	}
+
+	slices.Sort(out.Schemas)
+
+	for _, e := range sortedByName(db.Enums, func(e *Enum) string { return e.Name.String() }) {
+		out.Enums = append(out.Enums, enumJson{
+			Name:   e.Name.String(),
+			Labels: e.Labels,
+		})
+	}
+
+	for _, d := range sortedByName(db.Domains, func(d *Domain) string { return d.Name.String() }) {
+		out.Domains = append(out.Domains, domainJson{
+			Name:    d.Name.String(),
+			Type:    ddlTypeString(d.Type),
+			NotNull: d.Type.NotNull,
+			Default: d.Default,
+		})
+	}
+
+	for _, c := range sortedByName(db.CompositeTypes, func(c *CompositeType) string { return c.Name.String() }) {
+		out.CompositeTypes = append(out.CompositeTypes, compositeTypeJson{
+			Name:       c.Name.String(),
+			Attributes: columnsJson(c.Attributes.Columns),
+		})
+	}
+
+	for _, t := range sortedByName(db.Tables, func(t *Table) string { return t.Name.String() }) {
+		out.Tables = append(out.Tables, t.json())
+	}
+
+	for _, f := range sortedByName(db.Functions, (*Function).Signature) {
+		out.Functions = append(out.Functions, f.json())
+	}
+
+	return json.Marshal(out)
+}
+
+func (t *Table) json() tableJson {
+	out := tableJson{
+		Name:        t.Name.String(),
+		Kind:        t.Kind,
+		Columns:     columnsJson(t.Columns),
+		Constraints: make([]constraintJson, 0, len(t.Constraints)),
+		Partitioned: t.Partitioned,
+		Comment:     t.Comment,
+	}
+
+	for _, c := range t.Constraints {
+		constraint := constraintJson{
+			Name:              c.Name,
+			Type:              c.Type,
+			Columns:           c.Columns,
+			ReferencedColumns: c.ReferencedColumns,
+			Check:             c.Check,
+		}
+
+		if c.References != nil {
+			constraint.References = ptr.V(c.References.String())
+		}
+
+		out.Constraints = append(out.Constraints, constraint)
+	}
+
+	for _, p := range t.Inherits {
+		out.Inherits = append(out.Inherits, p.String())
+	}
+
+	if t.PartitionOf != nil {
+		out.PartitionOf = ptr.V(t.PartitionOf.String())
+	}
+
+	return out
+}
+
+func (f *Function) json() functionJson {
+	out := functionJson{
+		Name:       f.Name.String(),
+		Signature:  f.Signature(),
+		Args:       columnsJson(f.Args),
+		Variadic:   f.Variadic,
+		Returns:    ddlTypeString(f.Returns),
+		ReturnsSet: f.ReturnsSet,
+	}
+
+	if f.Returns.Record != nil && f.Returns.Composite == nil {
+		out.Columns = columnsJson(f.Returns.Record.Columns)
+	}
+
+	return out
+}
+
+func columnsJson(columns []*Column) []columnJson {
+	out := make([]columnJson, 0, len(columns))
+
+	for _, c := range columns {
+		out = append(out, columnJson{
+			Name:      c.Name,
+			Type:      ddlTypeString(c.Type),
+			NotNull:   c.Type.NotNull,
+			Default:   c.Default,
+			Identity:  c.Identity,
+			Generated: c.Generated,
+			Comment:   c.Comment,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,276 @@
+package pg
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+)
+
+// LintRule identifies a migration safety rule. The rules flag statements
+// that lock or rewrite tables that may be large in production.
+type LintRule string
+
+const (
+	LintRuleAddColumnNotNull LintRule = "add-column-not-null"
+	LintRuleSetNotNull       LintRule = "set-not-null"
+	LintRuleAlterColumnType  LintRule = "alter-column-type"
+	LintRuleCreateIndex      LintRule = "create-index-non-concurrently"
+
+	// LintRuleRenameColumn flags renamed columns. Whether queries still
+	// reference a renamed column is only known after the queries have been
+	// parsed, so the caller needs to check it using `Query.ReferencesColumn`.
+	LintRuleRenameColumn LintRule = "rename-column"
+)
+
+var LintRules = []LintRule{
+	LintRuleAddColumnNotNull,
+	LintRuleSetNotNull,
+	LintRuleAlterColumnType,
+	LintRuleCreateIndex,
+	LintRuleRenameColumn,
+}
+
+// LintWarning is a migration statement flagged by a lint rule.
+type LintWarning struct {
+	Rule    LintRule
+	Message string
+
+	// Line is the line of the migration the statement starts on.
+	Line int
+
+	// Table and Column hold the table and the column the statement
+	// affects. `Column` holds the old name of a renamed column.
+	Table  TableName
+	Column string
+}
+
+func (w *LintWarning) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", w.Line, w.Message, w.Rule)
+}
+
+// ignoreRegex matches the comments that suppress lint warnings of the
+// statement that follows them, like `-- norsu:ignore set-not-null`. All
+// rules are suppressed if no rules are listed.
+var ignoreRegex = regexp.MustCompile(`norsu:ignore\b([ \t\w,-]*)`)
+
+// linter collects lint warnings of a single migration.
+type linter struct {
+	warnings []LintWarning
+
+	// createdTables holds the tables created in the migration. Locking
+	// them is harmless because they can't have rows yet.
+	createdTables map[TableName]bool
+}
+
+func newLinter() *linter {
+	return &linter{
+		warnings:      make([]LintWarning, 0),
+		createdTables: make(map[TableName]bool),
+	}
+}
+
+// lintStatement checks the statement `stmt` that starts at the byte offset
+// `loc` of the migration `sql`. It must be called before the statement
+// is applied to `db`.
+func (l *linter) lintStatement(db *DB, sql string, stmt *pg_query.Node, loc int) {
+	warnings := make([]LintWarning, 0)
+
+	switch node := stmt.GetNode().(type) {
+	case *pg_query.Node_CreateStmt:
+		l.addCreatedTable(db, node.CreateStmt.GetRelation())
+	case *pg_query.Node_CreateTableAsStmt:
+		l.addCreatedTable(db, node.CreateTableAsStmt.GetInto().GetRel())
+	case *pg_query.Node_IndexStmt:
+		if table := l.findTable(db, node.IndexStmt.GetRelation()); table != nil && !node.IndexStmt.GetConcurrent() {
+			warnings = append(warnings, LintWarning{
+				Rule:    LintRuleCreateIndex,
+				Message: fmt.Sprintf(`creating an index on table "%s" without CONCURRENTLY blocks writes to the table`, table.Name.String()),
+				Table:   *table.Name,
+			})
+		}
+	case *pg_query.Node_AlterTableStmt:
+		if node.AlterTableStmt.GetObjtype() != pg_query.ObjectType_OBJECT_TABLE {
+			break
+		}
+
+		if table := l.findTable(db, node.AlterTableStmt.GetRelation()); table != nil {
+			for _, c := range node.AlterTableStmt.GetCmds() {
+				if w := lintAlterTableCmd(db, table, c.GetAlterTableCmd()); w != nil {
+					warnings = append(warnings, *w)
+				}
+			}
+		}
+	case *pg_query.Node_RenameStmt:
+		rename := node.RenameStmt
+
+		if rename.GetRenameType() == pg_query.ObjectType_OBJECT_TABLE {
+			l.renameCreatedTable(db, rename.GetRelation(), rename.GetNewname())
+			break
+		}
+
+		if rename.GetRenameType() != pg_query.ObjectType_OBJECT_COLUMN || rename.GetRelationType() != pg_query.ObjectType_OBJECT_TABLE {
+			break
+		}
+
+		if table := l.findTable(db, rename.GetRelation()); table != nil {
+			warnings = append(warnings, LintWarning{
+				Rule:    LintRuleRenameColumn,
+				Message: fmt.Sprintf(`renaming column "%s" of table "%s" to "%s" breaks queries that still use the old name`, rename.GetSubname(), table.Name.String(), rename.GetNewname()),
+				Table:   *table.Name,
+				Column:  rename.GetSubname(),
+			})
+		}
+	}
+
+	if len(warnings) == 0 {
+		return
+	}
+
+	ignored, ignoreAll := ignoredRules(sql, loc)
+	line := statementLine(sql, loc)
+
+	for _, w := range warnings {
+		if ignoreAll || slices.Contains(ignored, w.Rule) {
+			continue
+		}
+
+		w.Line = line
+		l.warnings = append(l.warnings, w)
+	}
+}
+
+func lintAlterTableCmd(db *DB, table *Table, cmd *pg_query.AlterTableCmd) *LintWarning {
+	w := &LintWarning{
+		Table:  *table.Name,
+		Column: cmd.GetName(),
+	}
+
+	switch cmd.GetSubtype() {
+	case pg_query.AlterTableType_AT_AddColumn:
+		def := cmd.GetDef().GetColumnDef()
+		if !isNotNullWithoutDefault(db, def) {
+			return nil
+		}
+
+		w.Rule = LintRuleAddColumnNotNull
+		w.Column = def.GetColname()
+		w.Message = fmt.Sprintf(`adding column "%s" to table "%s" as NOT NULL without a default fails if the table has rows`, w.Column, table.Name.String())
+	case pg_query.AlterTableType_AT_SetNotNull:
+		w.Rule = LintRuleSetNotNull
+		w.Message = fmt.Sprintf(`setting column "%s" of table "%s" NOT NULL scans the table while holding an exclusive lock`, w.Column, table.Name.String())
+	case pg_query.AlterTableType_AT_AlterColumnType:
+		if col := table.ColumnsByName[cmd.GetName()]; col != nil && isSafeTypeChange(db, col, cmd.GetDef().GetColumnDef()) {
+			return nil
+		}
+
+		w.Rule = LintRuleAlterColumnType
+		w.Message = fmt.Sprintf(`changing the type of column "%s" of table "%s" rewrites the table while holding an exclusive lock`, w.Column, table.Name.String())
+	default:
+		return nil
+	}
+
+	return w
+}
+
+// isNotNullWithoutDefault returns true if postgres has no value to fill
+// a new column with in existing rows even though the column is not null.
+func isNotNullWithoutDefault(db *DB, def *pg_query.ColumnDef) bool {
+	t, err := parseColumnType(db, def)
+	if err != nil || !t.NotNull {
+		return false
+	}
+
+	if _, ok := serialTypes[t.Name]; ok || (t.valueDomain() != nil && t.Domain.Default != nil) {
+		return false
+	}
+
+	for _, c := range def.GetConstraints() {
+		switch c.GetConstraint().GetContype() {
+		case pg_query.ConstrType_CONSTR_DEFAULT,
+			pg_query.ConstrType_CONSTR_IDENTITY,
+			pg_query.ConstrType_CONSTR_GENERATED:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSafeTypeChange returns true for type changes that postgres does without
+// rewriting the table: making a varchar longer or removing its limit.
+func isSafeTypeChange(db *DB, col *Column, def *pg_query.ColumnDef) bool {
+	t, err := parseColumnType(db, def)
+	if err != nil || col.Type.Name != "varchar" || col.Type.Array || t.Array || col.Type.Domain != nil || t.Domain != nil {
+		return false
+	}
+
+	oldLength, limited := col.Type.Length()
+	if !limited {
+		return (t.Name == "varchar" && len(t.Modifiers) == 0) || t.Name == "text"
+	}
+
+	newLength, ok := t.Length()
+	return t.Name == "text" || (t.Name == "varchar" && (!ok || newLength >= oldLength))
+}
+
+func (l *linter) addCreatedTable(db *DB, rel *pg_query.RangeVar) {
+	name, err := parseRangeVarName(rel)
+	if err != nil {
+		return
+	}
+
+	// `CREATE TABLE IF NOT EXISTS` doesn't create an existing table.
+	if name, err = db.QualifyTableName(name); err == nil && db.TablesByName[name] == nil {
+		l.createdTables[name] = true
+	}
+}
+
+// renameCreatedTable keeps track of a table created in the migration when
+// it's renamed to `newName`.
+func (l *linter) renameCreatedTable(db *DB, rel *pg_query.RangeVar, newName string) {
+	table, err := findRangeVarTable(db, rel)
+	if err != nil || !l.createdTables[*table.Name] {
+		return
+	}
+
+	delete(l.createdTables, *table.Name)
+	l.createdTables[NewTableName(newName, table.Name.Schema)] = true
+}
+
+// findTable returns the table `rel` unless it was created in the migration.
+// Nil is also returned for unknown tables, which fail when the statement
+// is applied.
+func (l *linter) findTable(db *DB, rel *pg_query.RangeVar) *Table {
+	table, err := findRangeVarTable(db, rel)
+	if err != nil || table.Kind != TableKindTable || l.createdTables[*table.Name] {
+		return nil
+	}
+
+	return table
+}
+
+// ignoredRules returns the rules suppressed by the comments before the
+// statement that starts at the byte offset `loc`. The second return value
+// is true if all rules are suppressed.
+func ignoredRules(sql string, loc int) ([]LintRule, bool) {
+	rules := make([]LintRule, 0)
+
+	for _, m := range ignoreRegex.FindAllStringSubmatch(sql[loc:statementStart(sql, loc)], -1) {
+		names := strings.FieldsFunc(m[1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+
+		if len(names) == 0 {
+			return nil, true
+		}
+
+		for _, n := range names {
+			rules = append(rules, LintRule(n))
+		}
+	}
+
+	return rules, false
+}
@@ -0,0 +1,315 @@
+package pg
+
+import (
+	"slices"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+)
+
+// comparisonOperators holds the operators that return a boolean.
+var comparisonOperators = []string{
+	"=", "<>", "!=", "<", ">", "<=", ">=",
+	"~~", "!~~", "~~*", "!~~*", "~", "!~", "~*", "!~*",
+	"@>", "<@", "&&",
+}
+
+// arithmeticOperators holds the operators that combine numbers into
+// a number of the wider type.
+var arithmeticOperators = []string{"+", "-", "*", "/", "%", "^"}
+
+// dateTimeOperation is an operator applied to a date, time or interval.
+// Integer operands are written as `int` and other numbers as `number`.
+type dateTimeOperation struct {
+	left  string
+	op    string
+	right string
+}
+
+// dateTimeOperators maps the date and time operations to their result types.
+var dateTimeOperators = map[dateTimeOperation]string{
+	{"date", "+", "int"}:                "date",
+	{"int", "+", "date"}:                "date",
+	{"date", "-", "int"}:                "date",
+	{"date", "-", "date"}:               "int4",
+	{"date", "+", "interval"}:           "timestamp",
+	{"interval", "+", "date"}:           "timestamp",
+	{"date", "-", "interval"}:           "timestamp",
+	{"date", "+", "time"}:               "timestamp",
+	{"time", "+", "date"}:               "timestamp",
+	{"date", "+", "timetz"}:             "timestamptz",
+	{"timetz", "+", "date"}:             "timestamptz",
+	{"timestamp", "+", "interval"}:      "timestamp",
+	{"interval", "+", "timestamp"}:      "timestamp",
+	{"timestamp", "-", "interval"}:      "timestamp",
+	{"timestamp", "-", "timestamp"}:     "interval",
+	{"timestamptz", "+", "interval"}:    "timestamptz",
+	{"interval", "+", "timestamptz"}:    "timestamptz",
+	{"timestamptz", "-", "interval"}:    "timestamptz",
+	{"timestamptz", "-", "timestamptz"}: "interval",
+	{"time", "+", "interval"}:           "time",
+	{"interval", "+", "time"}:           "time",
+	{"time", "-", "interval"}:           "time",
+	{"time", "-", "time"}:               "interval",
+	{"timetz", "+", "interval"}:         "timetz",
+	{"interval", "+", "timetz"}:         "timetz",
+	{"timetz", "-", "interval"}:         "timetz",
+	{"interval", "+", "interval"}:       "interval",
+	{"interval", "-", "interval"}:       "interval",
+	{"interval", "*", "int"}:            "interval",
+	{"interval", "*", "number"}:         "interval",
+	{"int", "*", "interval"}:            "interval",
+	{"number", "*", "interval"}:         "interval",
+	{"interval", "/", "int"}:            "interval",
+	{"interval", "/", "number"}:         "interval",
+}
+
+// parseOperatorSelection parses an operator expression like `a + b`, `a = b`
+// or `a BETWEEN b AND c`. Like postgres, the result is left unnamed.
+func parseOperatorSelection(ctx *QueryParseContext, expr *pg_query.A_Expr) (*selection, error) {
+	switch expr.GetKind() {
+	case pg_query.A_Expr_Kind_AEXPR_OP:
+		return parseOperatorCall(ctx, expr)
+	case pg_query.A_Expr_Kind_AEXPR_DISTINCT, pg_query.A_Expr_Kind_AEXPR_NOT_DISTINCT:
+		if _, err := parseOperands(ctx, expr.GetLexpr(), expr.GetRexpr()); err != nil {
+			return nil, err
+		}
+
+		// `IS [NOT] DISTINCT FROM` treats nulls as values and never returns null.
+		return boolSelection(true), nil
+	case pg_query.A_Expr_Kind_AEXPR_NULLIF:
+		operands, err := parseOperands(ctx, expr.GetLexpr(), expr.GetRexpr())
+		if err != nil {
+			return nil, err
+		}
+
+		col := operands[0]
+		col.Name = ""
+		col.Type.NotNull = false
+		return &selection{Column: col}, nil
+	case pg_query.A_Expr_Kind_AEXPR_SIMILAR:
+		pattern := expr.GetRexpr()
+
+		// `SIMILAR TO` patterns are converted to regular expressions
+		// by calling `similar_to_escape`.
+		if fc := pattern.GetFuncCall(); fc != nil && len(fc.GetArgs()) > 0 {
+			pattern = fc.GetArgs()[0]
+		}
+
+		return parsePredicate(ctx, expr.GetLexpr(), pattern)
+	case pg_query.A_Expr_Kind_AEXPR_OP_ANY,
+		pg_query.A_Expr_Kind_AEXPR_OP_ALL,
+		pg_query.A_Expr_Kind_AEXPR_LIKE,
+		pg_query.A_Expr_Kind_AEXPR_ILIKE:
+		return parsePredicate(ctx, expr.GetLexpr(), expr.GetRexpr())
+	case pg_query.A_Expr_Kind_AEXPR_IN,
+		pg_query.A_Expr_Kind_AEXPR_BETWEEN,
+		pg_query.A_Expr_Kind_AEXPR_NOT_BETWEEN,
+		pg_query.A_Expr_Kind_AEXPR_BETWEEN_SYM,
+		pg_query.A_Expr_Kind_AEXPR_NOT_BETWEEN_SYM:
+		// The right side is a list of values.
+		return parsePredicate(ctx, prepend(expr.GetRexpr().GetList().GetItems(), expr.GetLexpr())...)
+	}
+
+	return nil, ctx.Errorf(`unsupported operator expression "%s" (hint: add an explicit type cast for the selected expression)`, expr.GetKind())
+}
+
+func parseOperatorCall(ctx *QueryParseContext, expr *pg_query.A_Expr) (*selection, error) {
+	names := expr.GetName()
+	op := getString(names[len(names)-1])
+
+	if slices.Contains(comparisonOperators, op) {
+		return parsePredicate(ctx, expr.GetLexpr(), expr.GetRexpr())
+	}
+
+	right, err := parseValue(ctx, expr.GetRexpr())
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.GetLexpr() == nil {
+		// A prefix operator like `-price`.
+		if (op == "-" || op == "+") && !right.Type.Array && (isNumericType(right.Type) || right.Type.Name == "interval") {
+			right.Name = ""
+			right.Type.Domain = nil
+			right.Type.Modifiers = nil
+			return &selection{Column: right}, nil
+		}
+
+		return nil, ctx.Errorf(`unsupported prefix operator "%s" for type "%s" (hint: add an explicit type cast for the selected expression)`, op, typeString(right.Type))
+	}
+
+	left, err := parseValue(ctx, expr.GetLexpr())
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := operatorType(op, left.Type, right.Type)
+	if !ok {
+		return nil, ctx.Errorf(`unsupported operator "%s" for types "%s" and "%s" (hint: add an explicit type cast for the selected expression)`, op, typeString(left.Type), typeString(right.Type))
+	}
+
+	t.NotNull = left.Type.NotNull && right.Type.NotNull
+	return &selection{Column: &Column{Type: t}}, nil
+}
+
+// parsePredicate parses an expression that returns a boolean computed from
+// the values `operands`. The result is null if any of the operands is null.
+func parsePredicate(ctx *QueryParseContext, operands ...*pg_query.Node) (*selection, error) {
+	cols, err := parseOperands(ctx, operands...)
+	if err != nil {
+		return nil, err
+	}
+
+	notNull := true
+
+	for _, c := range cols {
+		notNull = notNull && c.Type.NotNull
+	}
+
+	return boolSelection(notNull), nil
+}
+
+func parseBoolExprSelection(ctx *QueryParseContext, expr *pg_query.BoolExpr) (*selection, error) {
+	return parsePredicate(ctx, expr.GetArgs()...)
+}
+
+func parseNullTestSelection(ctx *QueryParseContext, expr *pg_query.NullTest) (*selection, error) {
+	if _, err := parseSelectionNode(ctx, expr.GetArg()); err != nil {
+		return nil, err
+	}
+
+	return boolSelection(true), nil
+}
+
+func parseBooleanTestSelection(ctx *QueryParseContext, expr *pg_query.BooleanTest) (*selection, error) {
+	if _, err := parseOperands(ctx, expr.GetArg()); err != nil {
+		return nil, err
+	}
+
+	// `IS [NOT] TRUE`, `IS [NOT] FALSE` and `IS [NOT] UNKNOWN` never return null.
+	return boolSelection(true), nil
+}
+
+func parseOperands(ctx *QueryParseContext, operands ...*pg_query.Node) ([]*Column, error) {
+	cols := make([]*Column, 0, len(operands))
+
+	for _, o := range operands {
+		col, err := parseValue(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+
+		cols = append(cols, col)
+	}
+
+	return cols, nil
+}
+
+func boolSelection(notNull bool) *selection {
+	return &selection{
+		Column: &Column{
+			Type: DataType{Name: "bool", NotNull: notNull},
+		},
+	}
+}
+
+// operatorType returns the result type of the binary operator `op` applied
+// to values of the types `left` and `right`. The second return value is false
+// if the operator isn't supported for the types. The nullability of the result
+// is left for the caller.
+func operatorType(op string, left DataType, right DataType) (DataType, bool) {
+	if op == "||" {
+		return concatType(left, right)
+	}
+
+	// Postgres infers the type of a literal from the other operand.
+	if left.unknown && !right.unknown {
+		left = right.Clone()
+	} else if right.unknown && !left.unknown {
+		right = left.Clone()
+	}
+
+	if !slices.Contains(arithmeticOperators, op) || left.Array || right.Array {
+		return DataType{}, false
+	}
+
+	if name, ok := dateTimeOperators[dateTimeOperation{dateTimeOperand(left), op, dateTimeOperand(right)}]; ok {
+		return DataType{Name: name}, true
+	}
+
+	if !isNumericType(left) || !isNumericType(right) {
+		return DataType{}, false
+	}
+
+	t, ok := commonType(left, right)
+	if !ok {
+		return DataType{}, false
+	}
+
+	// Unlike in a `UNION`, postgres has no arithmetic operators for `float4`
+	// and other types. The other operand is converted to `float8` instead.
+	if (left.Name == "float4") != (right.Name == "float4") {
+		t = DataType{Name: "float8"}
+	}
+
+	// There is no modulo operator for floating point numbers.
+	if op == "%" && (t.Name == "float4" || t.Name == "float8") {
+		return DataType{}, false
+	}
+
+	if op == "^" && t.Name != "numeric" {
+		// Exponentiation is only defined for `float8` and `numeric`.
+		return DataType{Name: "float8"}, true
+	}
+
+	// The results of arithmetic don't keep the precision or the domain.
+	t.Domain = nil
+	t.Modifiers = nil
+	t.unknown = false
+	return t, true
+}
+
+// concatType returns the result type of `left || right`. Arrays are
+// concatenated with arrays and their elements and jsonb values with jsonb
+// values. Other values are concatenated as text.
+func concatType(left DataType, right DataType) (DataType, bool) {
+	switch {
+	case left.Array && right.Array:
+		return commonType(left, right)
+	case left.Array || right.Array:
+		t := left
+		if right.Array {
+			t = right
+		}
+
+		return t.Clone(), true
+	case left.Name == DataTypeJsonb && (right.Name == DataTypeJsonb || right.unknown),
+		right.Name == DataTypeJsonb && left.unknown:
+		return DataType{Name: DataTypeJsonb}, true
+	case isStringType(left) || isStringType(right):
+		return DataType{Name: "text"}, true
+	}
+
+	return DataType{}, false
+}
+
+// dateTimeOperand returns the name of the type `t` used in the keys
+// of `dateTimeOperators`.
+func dateTimeOperand(t DataType) string {
+	switch t.Name {
+	case "int2", "int4", "int8":
+		return "int"
+	case "numeric", "float4", "float8":
+		return "number"
+	}
+
+	return t.Name
+}
+
+func isNumericType(t DataType) bool {
+	return slices.Contains(numericTypes, t.Name)
+}
+
+func isStringType(t DataType) bool {
+	return t.unknown || slices.Contains(stringTypes, t.Name)
+}
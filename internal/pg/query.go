@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	pg_query "github.com/pganalyze/pg_query_go/v5"
@@ -25,12 +27,35 @@ const (
 	selectionStar = "*"
 )
 
+// builtinFunctions are the built-in functions handled by norsu. Unqualified
+// calls to them and to the functions of `builtinFunctionCatalog` never refer
+// to user defined functions because postgres searches `pg_catalog` first.
+var builtinFunctions = []string{
+	funcJsonAgg,
+	funcJsonbAgg,
+	funcToJson,
+	funcToJsonb,
+	funcJsonToRecord,
+	funcJsonbToRecord,
+	funcJsonToRecordSet,
+	funcJsonbToRecordSet,
+	funcJsonBuildObject,
+	funcJsonbBuildObject,
+}
+
 type Query struct {
 	Name string
 	SQL  string
 
 	In  *QueryInput
 	Out *QueryOutput
+
+	// Tables holds the tables and views the query and its subqueries use
+	// ordered by their names.
+	Tables []*Table
+
+	// columnRefs holds the names of all columns the query references.
+	columnRefs []string
 }
 
 type QueryOutput struct {
@@ -44,12 +69,64 @@ type QueryParseContext struct {
 	SQL          string
 	In           *QueryInput
 	locations    []int32
+
+	// referencedTables collects the names of all tables referenced in the
+	// query and its subqueries if not nil. The map is shared between the
+	// contexts of subqueries.
+	referencedTables map[TableName]bool
+
+	// unnamedSelections is true in the branches of set operations other
+	// than the first one and in `EXISTS` and `IN` subqueries. The names of
+	// their columns are not used.
+	unnamedSelections bool
 }
 
 type JoinedTable struct {
 	Table         TableName
 	Alias         TableName
 	SubQueryDepth int
+
+	// JoinType holds the type of the innermost join expression the table
+	// is a part of. Empty for tables that are not joined using `JOIN`.
+	JoinType JoinType
+
+	// Nullable is true if the table is on the nullable side of an outer
+	// join. Its columns can be null even if they are not null in the table.
+	Nullable bool
+}
+
+type JoinType string
+
+const (
+	JoinTypeInner JoinType = "inner"
+	JoinTypeLeft  JoinType = "left"
+	JoinTypeRight JoinType = "right"
+	JoinTypeFull  JoinType = "full"
+)
+
+var joinTypes = map[pg_query.JoinType]JoinType{
+	pg_query.JoinType_JOIN_INNER: JoinTypeInner,
+	pg_query.JoinType_JOIN_LEFT:  JoinTypeLeft,
+	pg_query.JoinType_JOIN_RIGHT: JoinTypeRight,
+	pg_query.JoinType_JOIN_FULL:  JoinTypeFull,
+}
+
+// table returns a copy of the joined table.
+func (jt *JoinedTable) table(db *DB) *Table {
+	table := db.TablesByName[jt.Table].Clone()
+
+	for _, c := range table.Columns {
+		c.Type.NotNull = c.Type.NotNull && !jt.Nullable
+	}
+
+	return table
+}
+
+// column returns a copy of the column `c` of the joined table.
+func (jt *JoinedTable) column(c *Column) *Column {
+	clone := c.Clone()
+	clone.Type.NotNull = clone.Type.NotNull && !jt.Nullable
+	return clone
 }
 
 type parseError struct {
@@ -98,7 +175,7 @@ func ParseQuery(db *DB, sql string) (*Query, error) {
 	}
 
 	// Parametrize inputs so that postgres is able to parse the query.
-	if s, err := parametrizeInputs(sql, q.In); err != nil {
+	if s, err := parametrizeInputs(db, sql, q.In); err != nil {
 		return nil, err
 	} else {
 		sql = s
@@ -106,11 +183,12 @@ func ParseQuery(db *DB, sql string) (*Query, error) {
 	}
 
 	ctx := &QueryParseContext{
-		DB:           db,
-		JoinedTables: make([]JoinedTable, 0),
-		SQL:          q.SQL,
-		In:           q.In,
-		locations:    make([]int32, 0),
+		DB:               db,
+		JoinedTables:     make([]JoinedTable, 0),
+		SQL:              q.SQL,
+		In:               q.In,
+		locations:        make([]int32, 0),
+		referencedTables: make(map[TableName]bool),
 	}
 
 	ast, err := parseSql(sql)
@@ -131,6 +209,117 @@ func ParseQuery(db *DB, sql string) (*Query, error) {
 		q.Out.Table = o
 	}
 
+	q.Tables = existingTables(db, ctx.referencedTables)
+	q.columnRefs = columnRefNames(ast.GetStmts()[0].GetStmt())
+
+	return &q, nil
+}
+
+// ReferencesColumn returns true if the query may reference the column
+// `column` of the table `table`. Column references are not resolved, so
+// any reference to a column with the same name in a query that selects
+// from the table counts.
+func (q *Query) ReferencesColumn(table TableName, column string) bool {
+	uses := slices.ContainsFunc(q.Tables, func(t *Table) bool { return *t.Name == table })
+	return uses && slices.Contains(q.columnRefs, column)
+}
+
+// OmitInputs removes the optional inputs `refs` from the query. Their
+// placeholders are replaced by `DEFAULT` and the placeholders of the
+// other inputs are renumbered.
+func (q *Query) OmitInputs(refs []string) error {
+	if q.In == nil || len(refs) == 0 {
+		return nil
+	}
+
+	ast, err := parseSql(q.SQL)
+	if err != nil {
+		return handleParseError(q.SQL, err)
+	}
+
+	params := make([]*pg_query.ParamRef, 0)
+	walkNodes(ast, func(n *pg_query.Node) bool {
+		if p := n.GetParamRef(); p != nil {
+			params = append(params, p)
+		}
+
+		return true
+	})
+
+	inputs := make([]QueryInputInfo, 0, len(q.In.Inputs))
+	placeholders := make(map[int]string, len(q.In.Inputs))
+
+	for _, in := range q.In.Inputs {
+		if slices.Contains(refs, in.Ref) {
+			if !in.Optional {
+				return fmt.Errorf(`input "%s" can't be left out`, in.Ref)
+			}
+
+			placeholders[in.PlaceholderIndex] = "DEFAULT"
+			continue
+		}
+
+		placeholders[in.PlaceholderIndex] = fmt.Sprintf("$%d", len(inputs)+1)
+		in.PlaceholderIndex = len(inputs) + 1
+		inputs = append(inputs, in)
+	}
+
+	// Replace the placeholders starting from the end so that the locations
+	// of the earlier ones stay valid.
+	slices.SortFunc(params, func(a, b *pg_query.ParamRef) int { return int(b.GetLocation() - a.GetLocation()) })
+
+	sql := q.SQL
+	for _, p := range params {
+		start := int(p.GetLocation())
+		end := start + len(fmt.Sprintf("$%d", p.GetNumber()))
+		sql = sql[:start] + placeholders[int(p.GetNumber())] + sql[end:]
+	}
+
+	q.SQL = sql
+	q.In.Inputs = inputs
+
+	return nil
+}
+
+// ScanQuery parses the query SQL without analyzing it against the schema.
+// Only the name of the query and the tables and the columns it references
+// are filled, which is enough for `ReferencesColumn`. Unlike `ParseQuery`,
+// it succeeds for queries that use columns missing from the schema.
+func ScanQuery(db *DB, sql string) (*Query, error) {
+	var q Query
+	if err := parseHeader(sql, &q); err != nil {
+		return nil, err
+	}
+
+	sql, err := parametrizeInputs(db, sql, q.In)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, err := parseSql(sql)
+	if err != nil {
+		return nil, handleParseError(sql, err)
+	}
+
+	tables := make(map[TableName]bool)
+
+	for _, stmt := range ast.GetStmts() {
+		walkNodes(stmt, func(n *pg_query.Node) bool {
+			if rel := n.GetRangeVar(); rel != nil {
+				if t, err := findRangeVarTable(db, rel); err == nil {
+					tables[*t.Name] = true
+				}
+			}
+
+			return true
+		})
+
+		q.columnRefs = append(q.columnRefs, columnRefNames(stmt.GetStmt())...)
+	}
+
+	q.SQL = sql
+	q.Tables = existingTables(db, tables)
+
 	return &q, nil
 }
 
@@ -201,6 +390,14 @@ func parseSelectStmt(ctx *QueryParseContext, stmt *pg_query.SelectStmt) (*Table,
 		}
 	}
 
+	if stmt.GetOp() != pg_query.SetOperation_SETOP_NONE {
+		return parseSetOperation(ctx, stmt)
+	}
+
+	if len(stmt.GetValuesLists()) > 0 {
+		return parseValuesLists(ctx, stmt.GetValuesLists())
+	}
+
 	// Add from statements and joins as tables to ctx.DB and to ctx.JoinedTables.
 	for _, f := range stmt.GetFromClause() {
 		if err := addTablesFromFromNode(ctx, f); err != nil {
@@ -211,6 +408,146 @@ func parseSelectStmt(ctx *QueryParseContext, stmt *pg_query.SelectStmt) (*Table,
 	return parseSelections(ctx, stmt.GetTargetList())
 }
 
+// parseSetOperation analyzes the branches of a `UNION`, `INTERSECT` or `EXCEPT`
+// query. The branches must have the same number of columns with compatible
+// types. The columns are named after the first branch like in postgres.
+func parseSetOperation(ctx *QueryParseContext, stmt *pg_query.SelectStmt) (*Table, error) {
+	left, err := parseSelectStmt(ctx, stmt.GetLarg())
+	if err != nil {
+		return nil, err
+	}
+
+	rctx := *ctx
+	rctx.unnamedSelections = true
+
+	right, err := parseSelectStmt(&rctx, stmt.GetRarg())
+	if err != nil {
+		return nil, err
+	}
+
+	op := strings.TrimPrefix(stmt.GetOp().String(), "SETOP_")
+
+	if len(left.Columns) != len(right.Columns) {
+		return nil, ctx.Errorf("each %s query must have the same number of columns", op)
+	}
+
+	table := NewTable()
+
+	for i, l := range left.Columns {
+		r := right.Columns[i]
+
+		t, ok := commonType(l.Type, r.Type)
+		if !ok {
+			return nil, ctx.Errorf(`%s types "%s" and "%s" of column "%s" cannot be matched`, op, typeString(l.Type), typeString(r.Type), l.Name)
+		}
+
+		switch stmt.GetOp() {
+		case pg_query.SetOperation_SETOP_UNION:
+			t.NotNull = l.Type.NotNull && r.Type.NotNull
+		case pg_query.SetOperation_SETOP_INTERSECT:
+			// Only rows found in both branches are returned.
+			t.NotNull = l.Type.NotNull || r.Type.NotNull
+		case pg_query.SetOperation_SETOP_EXCEPT:
+			// Only rows of the first branch are returned.
+			t.NotNull = l.Type.NotNull
+		}
+
+		table.AddColumn(&Column{Name: l.Name, Type: t})
+	}
+
+	return table, nil
+}
+
+// parseValuesLists analyzes a `VALUES` list. The columns are named `column1`,
+// `column2` and so on like in postgres. Each column gets the common type of
+// its values and is not null if all of its values are. Inputs without a type
+// get the type of their column.
+func parseValuesLists(ctx *QueryParseContext, lists []*pg_query.Node) (*Table, error) {
+	table := NewTable()
+
+	for i, l := range lists {
+		items := l.GetList().GetItems()
+
+		if i > 0 && len(items) != len(table.Columns) {
+			return nil, ctx.Errorf("VALUES lists must all be the same length")
+		}
+
+		for j, item := range items {
+			col, err := parseValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+
+			if i == 0 {
+				col.Name = fmt.Sprintf("column%d", j+1)
+				table.AddColumn(col)
+				continue
+			}
+
+			c := table.Columns[j]
+
+			t, ok := commonType(c.Type, col.Type)
+			if !ok {
+				return nil, ctx.Errorf(`VALUES types "%s" and "%s" of column "%s" cannot be matched`, typeString(c.Type), typeString(col.Type), c.Name)
+			}
+
+			t.NotNull = c.Type.NotNull && col.Type.NotNull
+			c.Type = t
+		}
+	}
+
+	if ctx.In != nil {
+		for _, l := range lists {
+			for j, item := range l.GetList().GetItems() {
+				p := item.GetParamRef()
+				if p == nil {
+					continue
+				}
+
+				if in := ctx.In.placeholderInput(int(p.GetNumber())); in != nil && in.Type == nil {
+					// Postgres resolves the type of a column of unknown values to text.
+					t := table.Columns[j].Type.Clone()
+					t.unknown = false
+					in.Type = &t
+				}
+			}
+		}
+	}
+
+	return table, nil
+}
+
+// parseValue analyzes a single value like an item of a `VALUES` list or an
+// operand of an operator. Inputs without a type are of unknown type so that
+// the type can be inferred from the other values.
+func parseValue(ctx *QueryParseContext, node *pg_query.Node) (*Column, error) {
+	if p := node.GetParamRef(); p != nil {
+		col := &Column{Type: DataType{Name: "text", unknown: true}}
+
+		if ctx.In == nil {
+			return col, nil
+		}
+
+		if in := ctx.In.placeholderInput(int(p.GetNumber())); in != nil && in.Type != nil {
+			col.Type = in.Type.Clone()
+			col.Type.NotNull = false
+		}
+
+		return col, nil
+	}
+
+	sel, err := parseSelectionNode(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+
+	if sel.Column == nil {
+		return nil, ctx.Errorf("only single column values are supported, got %s", sel.String())
+	}
+
+	return sel.Column, nil
+}
+
 func parseSelections(ctx *QueryParseContext, targets []*pg_query.Node) (*Table, error) {
 	table := NewTable()
 
@@ -239,12 +576,26 @@ func addTableFromCTE(ctx *QueryParseContext, cte *pg_query.CommonTableExpr) erro
 		return err
 	}
 
+	if err := setColumnNames(t, cte.GetAliascolnames()); err != nil {
+		return ctx.Errorf(`failed to name the columns of "%s": %w`, cte.GetCtename(), err)
+	}
+
+	resolveUnknownTypes(t)
 	t.Name = NewTableNamePtr(cte.GetCtename())
 	ctx.DB.AddTable(t)
 
 	return nil
 }
 
+// resolveUnknownTypes resolves the types of the literals selected by a CTE,
+// a subquery or a view to `text` like postgres does. Only the branches of
+// set operations infer the types of literals from each other.
+func resolveUnknownTypes(t *Table) {
+	for _, c := range t.Columns {
+		c.Type.unknown = false
+	}
+}
+
 func addTablesFromFromNode(ctx *QueryParseContext, node *pg_query.Node) error {
 	switch n := node.GetNode().(type) {
 	case *pg_query.Node_RangeVar:
@@ -264,11 +615,17 @@ func addTablesFromRangeVar(ctx *QueryParseContext, r *pg_query.RangeVar) error {
 	ctx.pushLocation(r.GetLocation())
 	defer ctx.popLocation()
 
-	name := NewTableName(r.GetRelname(), r.GetSchemaname())
+	ref := NewTableName(r.GetRelname(), r.GetSchemaname())
 
-	t := ctx.DB.TablesByName[name]
+	t := ctx.DB.FindTable(ref)
 	if t == nil {
-		return ctx.Errorf(`could not find table "%s"`, name.String())
+		return ctx.Errorf(`could not find table "%s"`, ref.String())
+	}
+
+	name := *t.Name
+
+	if ctx.referencedTables != nil {
+		ctx.referencedTables[name] = true
 	}
 
 	jt := JoinedTable{Table: name, Alias: name}
@@ -282,17 +639,43 @@ func addTablesFromRangeVar(ctx *QueryParseContext, r *pg_query.RangeVar) error {
 }
 
 func addTablesFromJoinExpr(ctx *QueryParseContext, j *pg_query.JoinExpr) error {
+	n := len(ctx.JoinedTables)
+
 	if err := addTablesFromFromNode(ctx, j.GetLarg()); err != nil {
 		return err
 	}
 
-	return addTablesFromFromNode(ctx, j.GetRarg())
+	left := len(ctx.JoinedTables) - n
+
+	if err := addTablesFromFromNode(ctx, j.GetRarg()); err != nil {
+		return err
+	}
+
+	right := len(ctx.JoinedTables) - n - left
+	joinType := joinTypes[j.GetJointype()]
+
+	// The tables are prepended so the tables of the right side come first.
+	for i := range ctx.JoinedTables[:right+left] {
+		jt := &ctx.JoinedTables[i]
+
+		if jt.JoinType == "" {
+			jt.JoinType = joinType
+		}
+
+		if i < right {
+			jt.Nullable = jt.Nullable || joinType == JoinTypeLeft || joinType == JoinTypeFull
+		} else {
+			jt.Nullable = jt.Nullable || joinType == JoinTypeRight || joinType == JoinTypeFull
+		}
+	}
+
+	return nil
 }
 
 func addTablesFromSubSelect(ctx *QueryParseContext, subSelect *pg_query.RangeSubselect) error {
 	stmt := subSelect.GetSubquery().GetSelectStmt()
 
-	if len(stmt.GetTargetList()) > 0 {
+	if len(stmt.GetTargetList()) > 0 || len(stmt.GetValuesLists()) > 0 || stmt.GetOp() != pg_query.SetOperation_SETOP_NONE {
 		return addTablesFromSubSelectWithSelectClause(ctx, subSelect)
 	}
 
@@ -309,6 +692,11 @@ func addTablesFromSubSelectWithSelectClause(ctx *QueryParseContext, subSelect *p
 		return ctx.Errorf("subquery must have an alias")
 	}
 
+	if err := setColumnNames(t, subSelect.GetAlias().GetColnames()); err != nil {
+		return ctx.Errorf(`failed to name the columns of "%s": %w`, subSelect.GetAlias().GetAliasname(), err)
+	}
+
+	resolveUnknownTypes(t)
 	t.Name = NewTableNamePtr(subSelect.GetAlias().GetAliasname())
 	ctx.DB.AddTableToFront(t)
 	ctx.JoinedTables = prepend(ctx.JoinedTables, JoinedTable{Table: *t.Name, Alias: *t.Name})
@@ -325,6 +713,10 @@ func addTablesFromFunction(ctx *QueryParseContext, f *pg_query.RangeFunction) er
 	ctx.pushLocation(fc.GetLocation())
 	defer ctx.popLocation()
 
+	if fn, name := findCalledFunction(ctx, fc); fn != nil {
+		return addTablesFromUserFunction(ctx, f, fc, fn, name)
+	}
+
 	name, err := getFunctionName(ctx, fc)
 	if err != nil {
 		return err
@@ -342,7 +734,7 @@ func addTablesFromFunction(ctx *QueryParseContext, f *pg_query.RangeFunction) er
 		return ctx.Errorf(`range function "%s" didn't have column defintions`, name)
 	}
 
-	t, err := parseColumnDefList(f.GetColdeflist())
+	t, err := parseColumnDefList(ctx.DB, f.GetColdeflist())
 	if err != nil {
 		return err
 	}
@@ -360,6 +752,142 @@ func addTablesFromFunction(ctx *QueryParseContext, f *pg_query.RangeFunction) er
 	return nil
 }
 
+// addTablesFromUserFunction adds the rows returned by a user defined function
+// as a table. A function that returns a scalar produces a single column named
+// after the alias or the function.
+func addTablesFromUserFunction(ctx *QueryParseContext, rf *pg_query.RangeFunction, fc *pg_query.FuncCall, f *Function, name string) error {
+	setInputTypesFromFunctionArgs(ctx, fc, f)
+
+	if rf.GetAlias() != nil {
+		name = rf.GetAlias().GetAliasname()
+	}
+
+	var t *Table
+	if len(rf.GetColdeflist()) > 0 {
+		var err error
+		if t, err = parseColumnDefList(ctx.DB, rf.GetColdeflist()); err != nil {
+			return err
+		}
+	} else if t = functionRow(ctx.DB, f); t == nil {
+		if f.Returns.Name == DataTypeRecord {
+			return ctx.Errorf(`function "%s" returns a record and needs column definitions`, f.Name.String())
+		}
+
+		t = NewTable()
+		t.AddColumn(nullableColumn(name, f.Returns))
+	}
+
+	if rf.GetAlias() != nil {
+		if err := setColumnNames(t, rf.GetAlias().GetColnames()); err != nil {
+			return ctx.Errorf(`failed to add function "%s": %w`, f.Name.String(), err)
+		}
+	}
+
+	if rf.GetOrdinality() {
+		t.AddColumn(&Column{Name: "ordinality", Type: DataType{Name: "int8", NotNull: true}})
+	}
+
+	t.Name = NewTableNamePtr(name)
+	ctx.DB.AddTableToFront(t)
+	ctx.JoinedTables = prepend(ctx.JoinedTables, JoinedTable{Table: *t.Name, Alias: *t.Name})
+
+	return nil
+}
+
+// findCalledFunction finds the user defined function called in `fc` and
+// returns it along with the unqualified name of the function. Nil is
+// returned if there's no such function.
+func findCalledFunction(ctx *QueryParseContext, fc *pg_query.FuncCall) (*Function, string) {
+	name, err := parseTypeNameParts(fc.GetFuncname())
+	if err != nil {
+		return nil, ""
+	}
+
+	_, cataloged := builtinFunctionCatalog[name.Name]
+	if !name.HasSchema() && (cataloged || slices.Contains(builtinFunctions, name.Name)) {
+		return nil, ""
+	}
+
+	return ctx.DB.FindFunction(name, len(fc.GetArgs())), name.Name
+}
+
+// functionRow returns the columns of the rows returned by the function `f`
+// or nil if the function returns a scalar value.
+func functionRow(db *DB, f *Function) *Table {
+	var row *Table
+
+	if f.Returns.Record != nil {
+		row = f.Returns.Record
+	} else if f.Returns.Enum == nil && f.Returns.Domain == nil {
+		row = db.FindTable(TableName(f.Returns.TypeName()))
+	}
+
+	if row == nil {
+		return nil
+	}
+
+	t := NewTable()
+	for _, c := range row.Columns {
+		t.AddColumn(nullableColumn(c.Name, c.Type))
+	}
+
+	return t
+}
+
+// nullableColumn creates a column for a value returned by a function. Functions
+// can return nulls regardless of the declared types.
+func nullableColumn(name string, t DataType) *Column {
+	c := &Column{Name: name, Type: t.Clone()}
+	c.Type.NotNull = false
+	return c
+}
+
+// setInputTypesFromFunctionArgs sets the types of the inputs that are passed
+// as arguments to the user defined function `f` without a type cast.
+func setInputTypesFromFunctionArgs(ctx *QueryParseContext, fc *pg_query.FuncCall, f *Function) {
+	if ctx.In == nil {
+		return
+	}
+
+	for i, a := range fc.GetArgs() {
+		var arg *Column
+
+		if named := a.GetNamedArgExpr(); named != nil {
+			j := slices.IndexFunc(f.Args, func(c *Column) bool { return c.Name == named.GetName() })
+			if j == -1 {
+				continue
+			}
+
+			arg = f.Args[j]
+			a = named.GetArg()
+		} else if i < len(f.Args) {
+			arg = f.Args[i]
+		} else {
+			arg = f.Args[len(f.Args)-1]
+		}
+
+		p := a.GetParamRef()
+		if p == nil {
+			continue
+		}
+
+		in := ctx.In.placeholderInput(int(p.GetNumber()))
+		if in == nil || in.Type != nil {
+			continue
+		}
+
+		t := arg.Type.Clone()
+		if f.Variadic && arg == f.Args[len(f.Args)-1] && !fc.GetFuncVariadic() {
+			// The values of a variadic argument are passed as separate arguments.
+			t.Array = false
+			t.ArrayDims = 0
+			t.RecordArray = false
+		}
+
+		in.Type = &t
+	}
+}
+
 func tryParseInputTypeFromJsonToRecordFunction(ctx *QueryParseContext, fc *pg_query.FuncCall, t *Table) error {
 	name, err := getFunctionName(ctx, fc)
 	if err != nil {
@@ -376,14 +904,7 @@ func tryParseInputTypeFromJsonToRecordFunction(ctx *QueryParseContext, fc *pg_qu
 		return nil
 	}
 
-	var in *QueryInputInfo
-	for i := range ctx.In.Inputs {
-		if ctx.In.Inputs[i].PlaceholderIndex == int(p.GetNumber()) {
-			in = &ctx.In.Inputs[i]
-			break
-		}
-	}
-
+	in := ctx.In.placeholderInput(int(p.GetNumber()))
 	if in == nil {
 		return ctx.Errorf("failed to find input for parameter %d", p.GetNumber())
 	}
@@ -431,13 +952,13 @@ func getFunctionName(ctx *QueryParseContext, fc *pg_query.FuncCall) (string, err
 	return strings.ToLower(getString(fc.GetFuncname()[0])), nil
 }
 
-func parseColumnDefList(list []*pg_query.Node) (*Table, error) {
+func parseColumnDefList(db *DB, list []*pg_query.Node) (*Table, error) {
 	t := NewTable()
 
 	for _, cd := range list {
 		switch n := cd.GetNode().(type) {
 		case *pg_query.Node_ColumnDef:
-			if c, err := parseColumnDef(n.ColumnDef); err != nil {
+			if c, err := parseColumnDef(db, n.ColumnDef); err != nil {
 				return nil, err
 			} else {
 				t.AddColumn(c)
@@ -486,7 +1007,12 @@ func parseSelection(ctx *QueryParseContext, res *pg_query.ResTarget) (*selection
 	}
 
 	if sel.Column != nil && len(sel.Column.Name) == 0 {
-		return nil, ctx.Errorf("failed to determine name for selection")
+		if !ctx.unnamedSelections {
+			return nil, ctx.Errorf("failed to determine name for selection")
+		}
+
+		// Postgres names the column like this.
+		sel.Column.Name = "?column?"
 	}
 
 	return sel, nil
@@ -508,8 +1034,20 @@ func parseSelectionNode(ctx *QueryParseContext, node *pg_query.Node) (*selection
 		return parseConstantSelection(ctx, n.AConst)
 	case *pg_query.Node_CaseExpr:
 		return parseCaseSelection(ctx, n.CaseExpr)
+	case *pg_query.Node_AIndirection:
+		return parseIndirectionSelection(ctx, n.AIndirection)
 	case *pg_query.Node_AExpr:
-		return nil, ctx.Errorf("expression selections need an explicit type cast %s", n.AExpr.String())
+		return parseOperatorSelection(ctx, n.AExpr)
+	case *pg_query.Node_BoolExpr:
+		return parseBoolExprSelection(ctx, n.BoolExpr)
+	case *pg_query.Node_NullTest:
+		return parseNullTestSelection(ctx, n.NullTest)
+	case *pg_query.Node_BooleanTest:
+		return parseBooleanTestSelection(ctx, n.BooleanTest)
+	case *pg_query.Node_SqlvalueFunction:
+		return parseSQLValueFunctionSelection(ctx, n.SqlvalueFunction)
+	case *pg_query.Node_MinMaxExpr:
+		return parseMinMaxSelection(ctx, n.MinMaxExpr)
 	}
 
 	return nil, ctx.Errorf(`unhandled selection "%+T"`, node.GetNode())
@@ -544,7 +1082,7 @@ func parseColumnRefSelectionOnePart(ctx *QueryParseContext, ref string) (*select
 
 				for _, c := range table.Columns {
 					if _, ok := allColumns.ColumnsByName[c.Name]; !ok {
-						allColumns.AddColumn(c.Clone())
+						allColumns.AddColumn(jt.column(c))
 					}
 				}
 			}
@@ -558,15 +1096,13 @@ func parseColumnRefSelectionOnePart(ctx *QueryParseContext, ref string) (*select
 		table := ctx.DB.TablesByName[jt.Table]
 
 		if c, ok := table.ColumnsByName[ref]; ok {
-			return &selection{Column: c.Clone()}, nil
+			return &selection{Column: jt.column(c)}, nil
 		}
 	}
 
 	// If we got here, check for a table selection.
 	for _, jt := range ctx.JoinedTables {
 		if jt.Alias.Name == ref {
-			table := ctx.DB.TablesByName[jt.Table]
-
 			// If a table is selected using a table name, it results in a
 			// record selection. The record's underlying type is the table's
 			// type.
@@ -575,9 +1111,9 @@ func parseColumnRefSelectionOnePart(ctx *QueryParseContext, ref string) (*select
 					Name: ref,
 					Type: DataType{
 						Name:        DataTypeRecord,
-						NotNull:     true,
+						NotNull:     !jt.Nullable,
 						RecordArray: true,
-						Record:      table.Clone(),
+						Record:      jt.table(ctx.DB),
 					},
 				},
 			}, nil
@@ -591,8 +1127,7 @@ func parseColumnRefSelectionTwoParts(ctx *QueryParseContext, ref1 string, ref2 s
 	if ref2 == selectionStar {
 		for _, jt := range ctx.JoinedTables {
 			if jt.Alias.Name == ref1 && jt.SubQueryDepth == 0 {
-				table := ctx.DB.TablesByName[jt.Table]
-				return &selection{Table: table.Clone()}, nil
+				return &selection{Table: jt.table(ctx.DB)}, nil
 			}
 		}
 	} else {
@@ -601,7 +1136,7 @@ func parseColumnRefSelectionTwoParts(ctx *QueryParseContext, ref1 string, ref2 s
 				table := ctx.DB.TablesByName[jt.Table]
 
 				if c, ok := table.ColumnsByName[ref2]; ok {
-					return &selection{Column: c.Clone()}, nil
+					return &selection{Column: jt.column(c)}, nil
 				}
 			}
 		}
@@ -616,8 +1151,7 @@ func parseColumnRefSelectionThreeParts(ctx *QueryParseContext, ref1 string, ref2
 	if ref3 == selectionStar {
 		for _, jt := range ctx.JoinedTables {
 			if jt.Alias == tableRef && jt.SubQueryDepth == 0 {
-				table := ctx.DB.TablesByName[jt.Table]
-				return &selection{Table: table.Clone()}, nil
+				return &selection{Table: jt.table(ctx.DB)}, nil
 			}
 		}
 	} else {
@@ -626,7 +1160,7 @@ func parseColumnRefSelectionThreeParts(ctx *QueryParseContext, ref1 string, ref2
 				table := ctx.DB.TablesByName[jt.Table]
 
 				if c, ok := table.ColumnsByName[ref3]; ok {
-					return &selection{Column: c.Clone()}, nil
+					return &selection{Column: jt.column(c)}, nil
 				}
 			}
 		}
@@ -643,16 +1177,96 @@ func columnRefPartToString(f *pg_query.Node) string {
 	return getString(f)
 }
 
+// parseIndirectionSelection parses field selections of composite values such
+// as `(address).street` and `(address).*`.
+func parseIndirectionSelection(ctx *QueryParseContext, ind *pg_query.A_Indirection) (*selection, error) {
+	sel, err := parseSelectionNode(ctx, ind.GetArg())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range ind.GetIndirection() {
+		if sel.Column == nil || !isRowValue(sel.Column.Type) {
+			return nil, ctx.Errorf("field selection from a value that is not a composite type or a row")
+		}
+
+		record := sel.Column.Type.Record
+		notNull := sel.Column.Type.NotNull
+
+		if i.GetAStar() != nil {
+			t := record.Clone()
+
+			// Fields of a null value are null too.
+			for _, c := range t.Columns {
+				c.Type.NotNull = c.Type.NotNull && notNull
+			}
+
+			sel = &selection{Table: t}
+		} else if i.GetString_() != nil {
+			field := getString(i)
+
+			c, ok := record.ColumnsByName[field]
+			if !ok {
+				return nil, ctx.Errorf(`could not find field "%s" in type "%s"`, field, sel.Column.Type.String())
+			}
+
+			c = c.Clone()
+			c.Type.NotNull = c.Type.NotNull && notNull
+
+			sel = &selection{Column: c}
+		} else {
+			return nil, ctx.Errorf(`unhandled indirection "%+T"`, i.GetNode())
+		}
+	}
+
+	return sel, nil
+}
+
+// isRowValue returns true if fields can be selected from a value of type `t`.
+// This is the case for composite types and rows of tables.
+func isRowValue(t DataType) bool {
+	if t.Record == nil || t.Json() {
+		return false
+	}
+
+	return (t.Composite != nil && !t.Array) || t.Name == DataTypeRecord
+}
+
 func parseSubQuerySelection(ctx *QueryParseContext, subLink *pg_query.SubLink) (*selection, error) {
-	subTable, err := parseSelectStmt(ctx, subLink.GetSubselect().GetSelectStmt())
+	linkType := subLink.GetSubLinkType()
+
+	sctx := *ctx
+	switch linkType {
+	case pg_query.SubLinkType_EXISTS_SUBLINK, pg_query.SubLinkType_ANY_SUBLINK, pg_query.SubLinkType_ALL_SUBLINK:
+		// Only the rows of `EXISTS`, `IN`, `ANY` and `ALL` subqueries are used.
+		sctx.unnamedSelections = true
+	}
+
+	subTable, err := parseSelectStmt(&sctx, subLink.GetSubselect().GetSelectStmt())
 	if err != nil {
 		return nil, err
 	}
 
+	if linkType == pg_query.SubLinkType_EXISTS_SUBLINK {
+		return boolSelection(true), nil
+	}
+
 	if len(subTable.Columns) != 1 {
 		return nil, ctx.Errorf("subqueries must only select one column")
 	}
 
+	if linkType == pg_query.SubLinkType_ANY_SUBLINK || linkType == pg_query.SubLinkType_ALL_SUBLINK {
+		// `IN (SELECT ...)` and `op ANY|ALL (SELECT ...)` compare the test
+		// expression with the rows of the subquery.
+		sel, err := parsePredicate(ctx, subLink.GetTestexpr())
+		if err != nil {
+			return nil, err
+		}
+
+		sel.Column.Type.NotNull = sel.Column.Type.NotNull && subTable.Columns[0].Type.NotNull
+		return sel, nil
+	}
+
 	return &selection{
 		Column: subTable.Columns[0],
 	}, nil
@@ -667,13 +1281,30 @@ func parseTypeCastSelection(ctx *QueryParseContext, cast *pg_query.TypeCast) (*s
 		sel = s
 	}
 
-	dataType, err := parseTypeName(cast.GetTypeName())
+	dataType, err := parseTypeName(ctx.DB, cast.GetTypeName())
 	if err != nil {
 		return nil, err
 	}
 
 	if sel.Column != nil {
 		sel.Column.Type.Name = dataType.Name
+		sel.Column.Type.Schema = dataType.Schema
+		sel.Column.Type.Array = dataType.Array
+		sel.Column.Type.ArrayDims = dataType.ArrayDims
+		sel.Column.Type.Modifiers = dataType.Modifiers
+		sel.Column.Type.Enum = dataType.Enum
+		sel.Column.Type.Domain = dataType.Domain
+		sel.Column.Type.Composite = dataType.Composite
+
+		// A json value keeps the record it was built from. Other values
+		// get the attributes of the target type if it's a composite type.
+		if dataType.Composite != nil || !dataType.Json() {
+			sel.Column.Type.Record = dataType.Record
+			sel.Column.Type.RecordArray = dataType.RecordArray
+		}
+
+		sel.Column.Type.NotNull = sel.Column.Type.NotNull || dataType.NotNull
+		sel.Column.Type.unknown = false
 	} else {
 		return nil, ctx.Errorf("can't cast a star selection")
 	}
@@ -698,9 +1329,41 @@ func parseFuncCallSelection(ctx *QueryParseContext, call *pg_query.FuncCall) (*s
 		}
 	}
 
+	if f, name := findCalledFunction(ctx, call); f != nil {
+		return parseUserFunctionSelection(ctx, call, f, name), nil
+	}
+
+	if f, name := findBuiltinFunction(call); f != nil {
+		return parseBuiltinFunctionSelection(ctx, call, f, name)
+	}
+
 	return nil, ctx.Errorf(`failed to parse function "%s" (hint: add an explicit type cast for the selected expression)`, funcName)
 }
 
+// parseUserFunctionSelection parses a call to a user defined function in the
+// select list. Like postgres, the selection is named after the function.
+func parseUserFunctionSelection(ctx *QueryParseContext, call *pg_query.FuncCall, f *Function, name string) *selection {
+	setInputTypesFromFunctionArgs(ctx, call, f)
+
+	row := functionRow(ctx.DB, f)
+	if row == nil || f.Returns.Composite != nil {
+		return &selection{Column: nullableColumn(name, f.Returns)}
+	}
+
+	if len(row.Columns) == 1 && f.Returns.Record != nil {
+		// A function with a single `OUT` argument returns the type of the argument.
+		row.Columns[0].Name = name
+		return &selection{Column: row.Columns[0]}
+	}
+
+	return &selection{
+		Column: &Column{
+			Name: name,
+			Type: DataType{Name: DataTypeRecord, Record: row},
+		},
+	}
+}
+
 func parseJsonSelection(ctx *QueryParseContext, call *pg_query.FuncCall) (*selection, error) {
 	funcName := getString(call.GetFuncname()[0])
 
@@ -835,14 +1498,22 @@ func parseConstantSelection(ctx *QueryParseContext, expr *pg_query.A_Const) (*se
 	switch expr.GetVal().(type) {
 	case *pg_query.A_Const_Sval:
 		sel.Column.Type.Name = "text"
+		sel.Column.Type.unknown = true
 	case *pg_query.A_Const_Boolval:
 		sel.Column.Type.Name = "bool"
 	case *pg_query.A_Const_Ival:
-		sel.Column.Type.Name = "int8"
+		sel.Column.Type.Name = "int4"
 	case *pg_query.A_Const_Fval:
-		sel.Column.Type.Name = "float8"
+		// Integers that don't fit `int4` are parsed as floats. Like postgres,
+		// they are typed as `int8` if they fit it. Other numbers are `numeric`.
+		if _, err := strconv.ParseInt(expr.GetFval().GetFval(), 10, 64); err == nil {
+			sel.Column.Type.Name = "int8"
+		} else {
+			sel.Column.Type.Name = "numeric"
+		}
 	default:
 		sel.Column.Type.Name = "text"
+		sel.Column.Type.unknown = expr.GetIsnull()
 	}
 
 	return sel, nil
@@ -901,6 +1572,10 @@ func parseInsertStmt(ctx *QueryParseContext, stmt *pg_query.InsertStmt) (*Table,
 		return nil, err
 	}
 
+	if err := checkInsertColumns(ctx, ctx.DB.FindTable(ctx.JoinedTables[0].Table), stmt); err != nil {
+		return nil, err
+	}
+
 	if stmt.GetSelectStmt() != nil && stmt.GetSelectStmt().GetSelectStmt() != nil {
 		for _, f := range stmt.GetSelectStmt().GetSelectStmt().GetFromClause() {
 			if err := addTablesFromFromNode(ctx, f); err != nil {
@@ -912,6 +1587,146 @@ func parseInsertStmt(ctx *QueryParseContext, stmt *pg_query.InsertStmt) (*Table,
 	return parseSelections(ctx, stmt.GetReturningList())
 }
 
+// checkInsertColumns makes sure that an insert gives a value for each column that
+// requires one and doesn't write to generated columns. The inputs that are only
+// inserted into columns that have a default are marked optional.
+func checkInsertColumns(ctx *QueryParseContext, table *Table, stmt *pg_query.InsertStmt) error {
+	// View columns don't keep the defaults of the columns of their base
+	// tables, so it's not known which of them require a value.
+	if table.Kind == TableKindView {
+		return nil
+	}
+
+	ctx.pushLocation(stmt.GetRelation().GetLocation())
+	defer ctx.popLocation()
+
+	valuesLists := stmt.GetSelectStmt().GetSelectStmt().GetValuesLists()
+	columns := make([]*Column, 0, len(table.Columns))
+
+	if len(stmt.GetCols()) > 0 {
+		for _, c := range stmt.GetCols() {
+			name := c.GetResTarget().GetName()
+
+			col := table.ColumnsByName[name]
+			if col == nil {
+				return ctx.Errorf(`unknown column "%s" in table "%s"`, name, table.Name.String())
+			}
+
+			columns = append(columns, col)
+		}
+	} else if len(valuesLists) > 0 {
+		// Without a column list the values are given to the first columns
+		// of the table in order.
+		n := min(len(valuesLists[0].GetList().GetItems()), len(table.Columns))
+		columns = append(columns, table.Columns[:n]...)
+	} else if sel := stmt.GetSelectStmt().GetSelectStmt(); sel != nil {
+		// The columns of a select are given to the first columns of the table
+		// the same way. The number of columns selected using `*` or by a set
+		// operation isn't known here, so all columns are assumed to get one.
+		n := len(table.Columns)
+
+		if sel.GetOp() == pg_query.SetOperation_SETOP_NONE && !slices.ContainsFunc(sel.GetTargetList(), hasStarRef) {
+			n = min(len(sel.GetTargetList()), n)
+		}
+
+		columns = append(columns, table.Columns[:n]...)
+	}
+
+	for _, col := range table.Columns {
+		if col.IsRequired() && !slices.Contains(columns, col) {
+			return ctx.Errorf(`column "%s" of table "%s" is not null and has no default but no value is inserted to it`, col.Name, table.Name.String())
+		}
+	}
+
+	for i, col := range columns {
+		if !col.IsGenerated() {
+			continue
+		}
+
+		if col.Generated == nil && stmt.GetOverride() == pg_query.OverridingKind_OVERRIDING_SYSTEM_VALUE {
+			continue
+		}
+
+		if len(valuesLists) > 0 && allValuesDefault(valuesLists, i) {
+			continue
+		}
+
+		return ctx.Errorf(`cannot insert a value into generated column "%s" of table "%s"`, col.Name, table.Name.String())
+	}
+
+	markOptionalInputs(ctx, stmt, columns, valuesLists)
+
+	// `ON CONFLICT DO UPDATE SET` updates the conflicting row.
+	return checkUpdateColumns(ctx, table, stmt.GetOnConflictClause().GetTargetList())
+}
+
+// markOptionalInputs marks the inputs that are only used as values of `VALUES`
+// lists inserted into `columns` that have a default. Those inputs can be left
+// out of the input model and replaced by `DEFAULT`.
+func markOptionalInputs(ctx *QueryParseContext, stmt *pg_query.InsertStmt, columns []*Column, valuesLists []*pg_query.Node) {
+	if ctx.In == nil {
+		return
+	}
+
+	defaulted := make(map[int]int)
+	for _, l := range valuesLists {
+		for i, item := range l.GetList().GetItems() {
+			if p := item.GetParamRef(); p != nil && i < len(columns) && columns[i].HasDefault() {
+				defaulted[int(p.GetNumber())]++
+			}
+		}
+	}
+
+	uses := make(map[int]int)
+	walkNodes(stmt, func(n *pg_query.Node) bool {
+		if p := n.GetParamRef(); p != nil {
+			uses[int(p.GetNumber())]++
+		}
+
+		return true
+	})
+
+	for i, n := range defaulted {
+		if in := ctx.In.placeholderInput(i); in != nil && uses[i] == n {
+			in.Optional = true
+		}
+	}
+}
+
+// allValuesDefault returns true if the value at index `i` of each values list
+// is `DEFAULT`.
+func allValuesDefault(valuesLists []*pg_query.Node, i int) bool {
+	for _, l := range valuesLists {
+		items := l.GetList().GetItems()
+
+		if i >= len(items) || items[i].GetSetToDefault() == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkUpdateColumns makes sure that the `SET` clause `targets` of an update
+// doesn't write to generated columns.
+func checkUpdateColumns(ctx *QueryParseContext, table *Table, targets []*pg_query.Node) error {
+	for _, t := range targets {
+		res := t.GetResTarget()
+
+		col := table.ColumnsByName[res.GetName()]
+		if col == nil || !col.IsGenerated() || res.GetVal().GetSetToDefault() != nil {
+			continue
+		}
+
+		ctx.pushLocation(res.GetLocation())
+		defer ctx.popLocation()
+
+		return ctx.Errorf(`column "%s" of table "%s" can only be updated to default`, col.Name, table.Name.String())
+	}
+
+	return nil
+}
+
 func parseUpdateStmt(ctx *QueryParseContext, stmt *pg_query.UpdateStmt) (*Table, error) {
 	ctx = ctx.CloneForSubquery()
 
@@ -927,6 +1742,10 @@ func parseUpdateStmt(ctx *QueryParseContext, stmt *pg_query.UpdateStmt) (*Table,
 		return nil, err
 	}
 
+	if err := checkUpdateColumns(ctx, ctx.DB.FindTable(ctx.JoinedTables[0].Table), stmt.GetTargetList()); err != nil {
+		return nil, err
+	}
+
 	// Add from statements and joins as tables to ctx.DB and to ctx.JoinedTables.
 	for _, f := range stmt.GetFromClause() {
 		if err := addTablesFromFromNode(ctx, f); err != nil {
@@ -971,11 +1790,13 @@ func parseDeleteStmt(ctx *QueryParseContext, stmt *pg_query.DeleteStmt) (*Table,
 // track how far up the table was joined.
 func (ctx *QueryParseContext) CloneForSubquery() *QueryParseContext {
 	clone := &QueryParseContext{
-		DB:           ctx.DB.Clone(),
-		SQL:          ctx.SQL,
-		In:           ctx.In,
-		JoinedTables: make([]JoinedTable, 0, len(ctx.JoinedTables)),
-		locations:    make([]int32, len(ctx.locations)),
+		DB:                ctx.DB.Clone(),
+		SQL:               ctx.SQL,
+		In:                ctx.In,
+		JoinedTables:      make([]JoinedTable, 0, len(ctx.JoinedTables)),
+		locations:         make([]int32, len(ctx.locations)),
+		referencedTables:  ctx.referencedTables,
+		unnamedSelections: ctx.unnamedSelections,
 	}
 
 	copy(clone.locations, ctx.locations)
@@ -985,6 +1806,8 @@ func (ctx *QueryParseContext) CloneForSubquery() *QueryParseContext {
 			Table:         jt.Table,
 			Alias:         jt.Alias,
 			SubQueryDepth: jt.SubQueryDepth + 1,
+			JoinType:      jt.JoinType,
+			Nullable:      jt.Nullable,
 		})
 	}
 
@@ -11,8 +11,6 @@ import (
 	"github.com/koskimas/norsu/internal/ptr"
 )
 
-var paramRegex = buildParamRegex()
-
 type QueryInput struct {
 	// Model holds the name of the input model with the package name.
 	// For example `api.Person`.
@@ -20,6 +18,18 @@ type QueryInput struct {
 	Inputs []QueryInputInfo
 }
 
+// placeholderInput returns the input of the parameter placeholder `$index`
+// or nil if there's no such input.
+func (in *QueryInput) placeholderInput(index int) *QueryInputInfo {
+	for i := range in.Inputs {
+		if in.Inputs[i].PlaceholderIndex == index {
+			return &in.Inputs[i]
+		}
+	}
+
+	return nil
+}
+
 type QueryInputInfo struct {
 	// Ref holds the reference as it was written in the SQL query.
 	// For example `someInput` or `someInput.someProp`.
@@ -31,6 +41,11 @@ type QueryInputInfo struct {
 	// Type holds the parsed data type if the input. This can be nil if the input
 	// couldn't be determined.
 	Type *DataType
+
+	// Optional is true if the input is only inserted into columns that have a
+	// default. The input model can leave it out in which case the default is
+	// inserted.
+	Optional bool
 }
 
 // parametrizeInputs finds all inputs with format `:someInput` using a regex
@@ -41,8 +56,9 @@ type QueryInputInfo struct {
 // phase but in order to find all inputs, we'd need to traverse the whole AST tree
 // which is difficult since the `pg_query` library doesn't come with a visitor
 // implementation.
-func parametrizeInputs(sql string, input *QueryInput) (string, error) {
+func parametrizeInputs(db *DB, sql string, input *QueryInput) (string, error) {
 	s := bufio.NewScanner(strings.NewReader(sql))
+	paramRegex := buildParamRegex(db)
 
 	linesOut := make([]string, 0)
 	for s.Scan() {
@@ -90,10 +106,7 @@ func parametrizeInputs(sql string, input *QueryInput) (string, error) {
 			}
 
 			if cast != nil && in.Type == nil {
-				in.Type = &DataType{
-					Name:  strings.ToLower(*cast),
-					Array: isArray,
-				}
+				in.Type = parseCastDataType(db, *cast, isArray)
 			}
 
 			newLine := line[:m[0]+1]
@@ -116,15 +129,57 @@ func parametrizeInputs(sql string, input *QueryInput) (string, error) {
 	return strings.Join(linesOut, "\n"), nil
 }
 
+// parseCastDataType parses the data type of an input cast like `:foo::my_enum`
+// resolving user defined types from `db`.
+func parseCastDataType(db *DB, cast string, isArray bool) *DataType {
+	t := &DataType{Array: isArray}
+	if isArray {
+		t.ArrayDims = 1
+	}
+
+	name := strings.ToLower(cast)
+	if dot := strings.IndexByte(name, '.'); dot != -1 {
+		t.Schema = ptr.V(name[:dot])
+		name = name[dot+1:]
+	}
+
+	t.Name = name
+	db.resolveDataType(t)
+
+	return t
+}
+
 // buildParamRegex builds the regex used to parse query inputs from the SQL.
+// The known types are the built-in `DataTypes` and the user defined types
+// of `db`.
 //
 // :foo               --> ok
 // :foo.bar           --> ok
 // :foo.bar::INT      --> ok
 // :foo.bar.baz::int  --> ok
 // :foo::INT[]        --> ok
+// :foo::my_enum      --> ok
+// :foo::my_domain    --> ok
 // ::INT              --> fail
 // :foo::UNKNOWN_TYPE --> fail
-func buildParamRegex() *regexp.Regexp {
-	return regexp.MustCompile(fmt.Sprintf(`[^:]:([\w\.]+)(::((?i)%s)(\[\])?)?`, strings.Join(maps.Keys(DataTypes), "|")))
+func buildParamRegex(db *DB) *regexp.Regexp {
+	types := maps.Keys(DataTypes)
+
+	for _, n := range db.UserTypeNames() {
+		types = append(types, n.Name)
+
+		if n.HasSchema() {
+			types = append(types, n.String())
+		}
+	}
+
+	// Try longer names first so that for example `integer` isn't
+	// matched as `int`.
+	slices.SortFunc(types, func(a, b string) int { return len(b) - len(a) })
+
+	for i := range types {
+		types[i] = regexp.QuoteMeta(types[i])
+	}
+
+	return regexp.MustCompile(fmt.Sprintf(`[^:]:([\w\.]+)(::((?i)%s)\b(\[\])?)?`, strings.Join(types, "|")))
 }
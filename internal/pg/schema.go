@@ -3,310 +3,2407 @@ package pg
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/koskimas/norsu/internal/ptr"
 	pg_query "github.com/pganalyze/pg_query_go/v5"
 )
 
-func ParseMigration(db *DB, sql string) error {
-	upMigration, err := omitDownMigration(sql)
+// MigrationResult holds the findings of parsing a migration.
+type MigrationResult struct {
+	// Unsupported holds the statements and parts of statements that norsu
+	// doesn't understand. They are skipped so that the caller can report them.
+	Unsupported []UnsupportedStatement
+
+	// Warnings holds the statements flagged by the lint rules that were
+	// not suppressed using `norsu:ignore` comments.
+	Warnings []LintWarning
+}
+
+// ParseMigration applies the statements of a migration to `db`. Each migration
+// is considered to be run in its own session: changes to the search path made
+// using `SET` are reverted once the migration has been applied.
+func ParseMigration(db *DB, sql string) (*MigrationResult, error) {
+	return parseMigration(db, sql, newLinter())
+}
+
+// parseMigration applies a migration to `db`. The statements are linted
+// using `l` if it's not nil.
+func parseMigration(db *DB, sql string, l *linter) (*MigrationResult, error) {
+	ast, err := parseSql(sql)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse: %w`, err)
+	}
+
+	searchPath := db.SearchPath
+	defer func() {
+		db.SearchPath = searchPath
+	}()
+
+	result := &MigrationResult{
+		Unsupported: make([]UnsupportedStatement, 0),
+		Warnings:    make([]LintWarning, 0),
+	}
+
+	for _, s := range ast.GetStmts() {
+		if l != nil {
+			l.lintStatement(db, sql, s.GetStmt(), int(s.GetStmtLocation()))
+		}
+
+		err := applyStatement(db, sql, s.GetStmt(), searchPath)
+
+		if tags := unsupportedTags(err); tags != nil {
+			for _, t := range tags {
+				result.Unsupported = append(result.Unsupported, UnsupportedStatement{
+					Tag:  t,
+					Line: statementLine(sql, int(s.GetStmtLocation())),
+				})
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if l != nil {
+		result.Warnings = l.warnings
+	}
+
+	return result, nil
+}
+
+// applyStatement applies a single migration statement to `db`. An
+// `unsupportedError` is returned for statements that were ignored.
+func applyStatement(db *DB, sql string, stmt *pg_query.Node, searchPath []string) error {
+	switch node := stmt.GetNode().(type) {
+	case *pg_query.Node_VariableSetStmt:
+		if err := setVariable(db, node.VariableSetStmt, searchPath); err != nil {
+			return fmt.Errorf(`failed to parse a set statement: %w`, err)
+		}
+	case *pg_query.Node_SelectStmt:
+		if node.SelectStmt.GetIntoClause() != nil {
+			if err := selectInto(db, sql, node.SelectStmt); err != nil {
+				return fmt.Errorf(`failed to parse a select into statement: %w`, err)
+			}
+		} else if err := selectSetConfig(db, node.SelectStmt, searchPath); err != nil {
+			return fmt.Errorf(`failed to parse a select statement: %w`, err)
+		}
+	case *pg_query.Node_CreateStmt:
+		if err := createTable(db, node.CreateStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create table statement: %w`, err)
+		}
+	case *pg_query.Node_DropStmt:
+		if err := drop(db, node.DropStmt); err != nil {
+			return fmt.Errorf(`failed to parse a drop statement: %w`, err)
+		}
+	case *pg_query.Node_AlterTableStmt:
+		if err := alterTable(db, node.AlterTableStmt); err != nil {
+			return fmt.Errorf(`failed to parse an alter table statement: %w`, err)
+		}
+	case *pg_query.Node_RenameStmt:
+		if err := rename(db, node.RenameStmt); err != nil {
+			return fmt.Errorf(`failed to parse a rename statement: %w`, err)
+		}
+	case *pg_query.Node_CreateSchemaStmt:
+		if err := createSchema(db, node.CreateSchemaStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create schema statement: %w`, err)
+		}
+	case *pg_query.Node_AlterObjectSchemaStmt:
+		if err := alterObjectSchema(db, node.AlterObjectSchemaStmt); err != nil {
+			return fmt.Errorf(`failed to parse a set schema statement: %w`, err)
+		}
+	case *pg_query.Node_ViewStmt:
+		if err := createView(db, sql, node.ViewStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create view statement: %w`, err)
+		}
+	case *pg_query.Node_CreateTableAsStmt:
+		if err := createTableAs(db, sql, node.CreateTableAsStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create table as statement: %w`, err)
+		}
+	case *pg_query.Node_CreateEnumStmt:
+		if err := createEnum(db, node.CreateEnumStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create enum statement: %w`, err)
+		}
+	case *pg_query.Node_AlterEnumStmt:
+		if err := alterEnum(db, node.AlterEnumStmt); err != nil {
+			return fmt.Errorf(`failed to parse an alter enum statement: %w`, err)
+		}
+	case *pg_query.Node_CompositeTypeStmt:
+		if err := createCompositeType(db, node.CompositeTypeStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create type statement: %w`, err)
+		}
+	case *pg_query.Node_CreateDomainStmt:
+		if err := createDomain(db, node.CreateDomainStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create domain statement: %w`, err)
+		}
+	case *pg_query.Node_CreateFunctionStmt:
+		if err := createFunction(db, node.CreateFunctionStmt); err != nil {
+			return fmt.Errorf(`failed to parse a create function statement: %w`, err)
+		}
+	case *pg_query.Node_CommentStmt:
+		if err := comment(db, node.CommentStmt); err != nil {
+			return fmt.Errorf(`failed to parse a comment statement: %w`, err)
+		}
+	case *pg_query.Node_TransactionStmt:
+		// Transaction control doesn't affect the schema.
+	default:
+		return &unsupportedError{tag: statementTag(stmt)}
+	}
+
+	return nil
+}
+
+// ParseSchemaDump applies a schema dump created using `pg_dump --schema-only`
+// to `db` like `ParseMigration`. psql meta-commands like `\restrict` are ignored.
+// The dump is not linted since it describes an existing database.
+func ParseSchemaDump(db *DB, sql string) ([]UnsupportedStatement, error) {
+	lines := strings.Split(sql, "\n")
+
+	for i, l := range lines {
+		if strings.HasPrefix(l, "\\") {
+			lines[i] = ""
+		}
+	}
+
+	result, err := parseMigration(db, strings.Join(lines, "\n"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Unsupported, nil
+}
+
+// setVariable handles `SET`, `SET ... TO DEFAULT` and `RESET` statements. Only
+// the search path is tracked. `defaultSearchPath` is the search path at the
+// beginning of the session.
+func setVariable(db *DB, stmt *pg_query.VariableSetStmt, defaultSearchPath []string) error {
+	if stmt.GetName() != "search_path" {
+		return nil
+	}
+
+	switch stmt.GetKind() {
+	case pg_query.VariableSetKind_VAR_SET_VALUE:
+		values := make([]string, 0, len(stmt.GetArgs()))
+
+		for _, a := range stmt.GetArgs() {
+			c := a.GetAConst()
+			if c == nil {
+				return errors.New("unsupported search_path value")
+			}
+
+			values = append(values, c.GetSval().GetSval())
+		}
+
+		db.SearchPath = parseSearchPath(strings.Join(values, ","))
+	case pg_query.VariableSetKind_VAR_SET_DEFAULT, pg_query.VariableSetKind_VAR_RESET:
+		db.SearchPath = defaultSearchPath
+	}
+
+	return nil
+}
+
+// selectSetConfig handles `SELECT pg_catalog.set_config('search_path', ...)`
+// statements that pg_dump emits. Other selects are unsupported.
+func selectSetConfig(db *DB, stmt *pg_query.SelectStmt, defaultSearchPath []string) error {
+	setConfig := false
+
+	for _, t := range stmt.GetTargetList() {
+		fc := t.GetResTarget().GetVal().GetFuncCall()
+		names := getStrings(fc.GetFuncname())
+
+		if len(names) == 0 || names[len(names)-1] != "set_config" || len(fc.GetArgs()) != 3 {
+			continue
+		}
+
+		setConfig = true
+
+		name := fc.GetArgs()[0].GetAConst()
+		value := fc.GetArgs()[1].GetAConst()
+
+		if name == nil || value == nil {
+			return errors.New("unsupported set_config arguments")
+		}
+
+		if name.GetSval().GetSval() == "search_path" {
+			db.SearchPath = parseSearchPath(value.GetSval().GetSval())
+		}
+	}
+
+	if !setConfig {
+		return &unsupportedError{tag: "SELECT"}
+	}
+
+	return nil
+}
+
+// parseSearchPath parses a comma separated search path value like
+// `"$user", public`. The `$user` schema is skipped.
+func parseSearchPath(value string) []string {
+	path := make([]string, 0)
+
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+
+		if strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) && len(s) > 1 {
+			s = s[1 : len(s)-1]
+		} else {
+			s = strings.ToLower(s)
+		}
+
+		if len(s) != 0 && s != "$user" {
+			path = append(path, s)
+		}
+	}
+
+	return path
+}
+
+func createTable(db *DB, stmt *pg_query.CreateStmt) error {
+	name, err := parseNewRangeVarName(db, stmt.GetRelation())
+	if err != nil {
+		return err
+	}
+
+	if db.TablesByName[name] != nil {
+		if stmt.GetIfNotExists() {
+			return nil
+		}
+
+		return fmt.Errorf(`relation "%s" already exists`, name.String())
+	}
+
+	table := NewTable(name)
+	table.Kind = TableKindTable
+	table.Partitioned = stmt.GetPartspec() != nil
+
+	for _, r := range stmt.GetInhRelations() {
+		if err := inheritTable(db, table, r.GetRangeVar(), stmt.GetPartbound() != nil); err != nil {
+			return err
+		}
+	}
+
+	inherited := slices.Clone(table.Columns)
+
+	for _, c := range stmt.GetTableElts() {
+		if def := c.GetColumnDef(); def != nil {
+			if col := table.ColumnsByName[def.GetColname()]; col != nil && slices.Contains(inherited, col) {
+				if err := mergeColumn(db, table, col, def); err != nil {
+					return err
+				}
+			} else if err := addColumn(db, table, def); err != nil {
+				return err
+			}
+		} else if con := c.GetConstraint(); con != nil {
+			if err := addConstraint(db, table, con, ""); err != nil {
+				return err
+			}
+		} else if like := c.GetTableLikeClause(); like != nil {
+			likeTable, err := findRangeVarTable(db, like.GetRelation())
+			if err != nil {
+				return fmt.Errorf(`tried to create a table using like clause: %w`, err)
+			}
+
+			for _, col := range likeTable.Columns {
+				table.AddColumn(col.Clone())
+			}
+		}
+	}
+
+	db.AddTable(table)
+	return nil
+}
+
+// inheritTable makes `table` inherit the columns and constraints of the table
+// `rel`. If `partition` is true, `table` becomes a partition of `rel`.
+func inheritTable(db *DB, table *Table, rel *pg_query.RangeVar, partition bool) error {
+	parent, err := findRangeVarTable(db, rel)
+	if err != nil {
+		return err
+	}
+
+	if parent.Kind != TableKindTable {
+		return fmt.Errorf(`inherited relation "%s" is not a table`, parent.Name.String())
+	}
+
+	if partition {
+		if !parent.Partitioned {
+			return fmt.Errorf(`table "%s" is not partitioned`, parent.Name.String())
+		}
+
+		table.PartitionOf = parent.Name.Clone()
+	} else {
+		if parent.Partitioned {
+			return fmt.Errorf(`cannot inherit from partitioned table "%s"`, parent.Name.String())
+		}
+
+		table.Inherits = append(table.Inherits, *parent.Name)
+	}
+
+	for _, c := range parent.Columns {
+		if col := table.ColumnsByName[c.Name]; col != nil {
+			// Columns of multiple parents with the same name are merged.
+			if !col.Type.HasSameType(&c.Type) {
+				return fmt.Errorf(`inherited column "%s" has a type conflict`, c.Name)
+			}
+
+			col.Type.NotNull = col.Type.NotNull || c.Type.NotNull
+			continue
+		}
+
+		// Comments are not inherited.
+		col := c.Clone()
+		col.Comment = nil
+
+		if !partition {
+			col.Identity = ""
+		}
+
+		table.AddColumn(col)
+	}
+
+	inheritConstraints(table, parent)
+	return nil
+}
+
+// inheritConstraints copies the constraints of `parent` that `child` inherits
+// and doesn't have yet. Check constraints are always inherited with the same
+// name. Partitions also inherit foreign keys and get their own primary key and
+// unique constraints.
+func inheritConstraints(child *Table, parent *Table) {
+	partition := child.PartitionOf != nil
+
+	for _, c := range parent.Constraints {
+		switch c.Type {
+		case ConstraintTypeCheck, ConstraintTypeForeignKey:
+			if (c.Type == ConstraintTypeCheck || partition) && child.ConstraintsByName[c.Name] == nil {
+				child.AddConstraint(c.Clone())
+			}
+		case ConstraintTypePrimaryKey, ConstraintTypeUnique:
+			if !partition || hasKeyConstraint(child, c) {
+				continue
+			}
+
+			if c.Type == ConstraintTypePrimaryKey && child.PrimaryKey() != nil {
+				continue
+			}
+
+			con := c.Clone()
+			con.Name = constraintName(child, con, "")
+			child.AddConstraint(con)
+		}
+	}
+}
+
+func hasKeyConstraint(table *Table, con *Constraint) bool {
+	return slices.ContainsFunc(table.Constraints, func(c *Constraint) bool {
+		return c.Type == con.Type && slices.Equal(c.Columns, con.Columns)
+	})
+}
+
+// mergeColumn merges the definition of a column in `CREATE TABLE` to a column
+// inherited from a parent table. The type can be omitted in partitions.
+func mergeColumn(db *DB, table *Table, col *Column, def *pg_query.ColumnDef) error {
+	if def.GetTypeName() != nil {
+		t, err := parseColumnType(db, def)
+		if err != nil {
+			return fmt.Errorf(`failed to parse type for column "%s": %w`, col.Name, err)
+		}
+
+		if !t.HasSameType(&col.Type) {
+			return fmt.Errorf(`column "%s" has a type conflict`, col.Name)
+		}
+	}
+
+	col.Type.NotNull = col.Type.NotNull || isNotNull(def)
+
+	for _, c := range def.GetConstraints() {
+		if err := setColumnConstraint(col, c.GetConstraint()); err != nil {
+			return fmt.Errorf(`failed to parse column "%s": %w`, col.Name, err)
+		}
+
+		if err := addConstraint(db, table, c.GetConstraint(), col.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addColumn(db *DB, table *Table, def *pg_query.ColumnDef) error {
+	col, err := parseColumnDef(db, def)
+	if err != nil {
+		return err
+	}
+
+	if table.ColumnsByName[col.Name] != nil {
+		return fmt.Errorf(`column "%s" already exists`, col.Name)
+	}
+
+	if intType, ok := serialTypes[col.Type.Name]; ok && col.Type.Schema == nil {
+		// Serial types are not real types but shorthands for an integer column
+		// with a sequence default.
+		col.Type.Name = intType
+		col.Type.Schema = ptr.V("pg_catalog")
+		col.Type.NotNull = true
+		col.Default = ptr.V(fmt.Sprintf("nextval('%s_%s_seq'::regclass)", table.Name.Name, col.Name))
+	}
+
+	table.AddColumn(col)
+
+	for _, c := range def.GetConstraints() {
+		if err := addConstraint(db, table, c.GetConstraint(), col.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addConstraint adds a primary key, unique, foreign key or check constraint to
+// `table`. `column` is the name of the column for inline column constraints and
+// empty for table constraints. Other types of constraints are ignored.
+func addConstraint(db *DB, table *Table, c *pg_query.Constraint, column string) error {
+	con := &Constraint{
+		Name: c.GetConname(),
+	}
+
+	keys := c.GetKeys()
+	if len(column) > 0 {
+		keys = []*pg_query.Node{pg_query.MakeStrNode(column)}
+	}
+
+	switch c.GetContype() {
+	case pg_query.ConstrType_CONSTR_PRIMARY:
+		con.Type = ConstraintTypePrimaryKey
+		con.Columns = getStrings(keys)
+	case pg_query.ConstrType_CONSTR_UNIQUE:
+		con.Type = ConstraintTypeUnique
+		con.Columns = getStrings(keys)
+	case pg_query.ConstrType_CONSTR_FOREIGN:
+		if len(column) == 0 {
+			keys = c.GetFkAttrs()
+		}
+
+		con.Type = ConstraintTypeForeignKey
+		con.Columns = getStrings(keys)
+
+		if err := setForeignKeyReference(db, table, con, c); err != nil {
+			return err
+		}
+	case pg_query.ConstrType_CONSTR_CHECK:
+		check, err := deparseExpr(c.GetRawExpr())
+		if err != nil {
+			return err
+		}
+
+		con.Type = ConstraintTypeCheck
+		con.Check = check
+		con.Columns = columnRefNames(c.GetRawExpr())
+	default:
+		return nil
+	}
+
+	for _, col := range con.Columns {
+		if table.ColumnsByName[col] == nil {
+			return fmt.Errorf(`column "%s" named in %s constraint does not exist`, col, con.Type)
+		}
+	}
+
+	if con.Type == ConstraintTypePrimaryKey {
+		if table.PrimaryKey() != nil {
+			return fmt.Errorf(`multiple primary keys for table "%s" are not allowed`, table.Name.String())
+		}
+
+		for _, col := range con.Columns {
+			table.ColumnsByName[col].Type.NotNull = true
+		}
+	}
+
+	if len(con.Name) == 0 {
+		con.Name = constraintName(table, con, column)
+	} else if table.ConstraintsByName[con.Name] != nil {
+		return fmt.Errorf(`constraint "%s" for relation "%s" already exists`, con.Name, table.Name.String())
+	}
+
+	table.AddConstraint(con)
+	return nil
+}
+
+// setForeignKeyReference sets the referenced table and columns of a foreign key.
+// The primary key of the referenced table is used if the columns are omitted.
+func setForeignKeyReference(db *DB, table *Table, con *Constraint, c *pg_query.Constraint) error {
+	ref, err := parseRangeVarName(c.GetPktable())
+	if err != nil {
+		return err
+	}
+
+	refTable := db.FindTable(ref)
+	if ref.Name == table.Name.Name && (!ref.HasSchema() || ref.Schema == table.Name.Schema) {
+		// A self reference in `CREATE TABLE` refers to a table that
+		// hasn't been added to the database yet.
+		refTable = table
+	}
+
+	if refTable == nil {
+		return fmt.Errorf(`foreign key references unknown table "%s"`, ref.String())
+	}
+
+	con.References = refTable.Name.Clone()
+	con.ReferencedColumns = getStrings(c.GetPkAttrs())
+
+	if len(con.ReferencedColumns) == 0 {
+		pk := refTable.PrimaryKey()
+		if pk == nil {
+			return fmt.Errorf(`there is no primary key for referenced table "%s"`, ref.String())
+		}
+
+		con.ReferencedColumns = slices.Clone(pk.Columns)
+	}
+
+	for _, col := range con.ReferencedColumns {
+		if refTable.ColumnsByName[col] == nil {
+			return fmt.Errorf(`column "%s" referenced in foreign key constraint does not exist`, col)
+		}
+	}
+
+	if len(con.ReferencedColumns) != len(con.Columns) {
+		return errors.New("number of referencing and referenced columns for foreign key disagree")
+	}
+
+	return nil
+}
+
+// constraintName generates a name for a constraint the same way postgres does.
+// For example `persons_pkey` or `pets_owner_id_fkey`.
+func constraintName(table *Table, con *Constraint, column string) string {
+	var suffix string
+	columns := con.Columns
+
+	switch con.Type {
+	case ConstraintTypePrimaryKey:
+		suffix = "pkey"
+		columns = nil
+	case ConstraintTypeUnique:
+		suffix = "key"
+	case ConstraintTypeForeignKey:
+		suffix = "fkey"
+	case ConstraintTypeCheck:
+		suffix = "check"
+
+		if len(column) > 0 {
+			columns = []string{column}
+		} else if len(columns) != 1 {
+			columns = nil
+		}
+	}
+
+	base := strings.Join(append([]string{table.Name.Name}, columns...), "_")
+	name := base + "_" + suffix
+
+	for i := 1; table.ConstraintsByName[name] != nil; i++ {
+		name = fmt.Sprintf("%s_%s%d", base, suffix, i)
+	}
+
+	return name
+}
+
+func parseColumnDef(db *DB, def *pg_query.ColumnDef) (*Column, error) {
+	col := Column{
+		Name: def.GetColname(),
+	}
+
+	if t, err := parseColumnType(db, def); err != nil {
+		return nil, fmt.Errorf(`failed to parse type for column "%s": %w`, col.Name, err)
+	} else {
+		col.Type = *t
+	}
+
+	for _, c := range def.GetConstraints() {
+		if err := setColumnConstraint(&col, c.GetConstraint()); err != nil {
+			return nil, fmt.Errorf(`failed to parse column "%s": %w`, col.Name, err)
+		}
+	}
+
+	return &col, nil
+}
+
+// setColumnConstraint sets the default, identity or generation expression of
+// `col` from a column constraint. Other constraints are ignored.
+func setColumnConstraint(col *Column, c *pg_query.Constraint) error {
+	var err error
+
+	switch c.GetContype() {
+	case pg_query.ConstrType_CONSTR_DEFAULT:
+		col.Default, err = deparseExpr(c.GetRawExpr())
+	case pg_query.ConstrType_CONSTR_IDENTITY:
+		col.Identity = parseColumnIdentity(c.GetGeneratedWhen())
+		col.Type.NotNull = true
+	case pg_query.ConstrType_CONSTR_GENERATED:
+		col.Generated, err = deparseExpr(c.GetRawExpr())
+	}
+
+	return err
+}
+
+// parseColumnIdentity parses the `generated_when` attribute of an identity
+// constraint. "a" stands for always and "d" for by default.
+func parseColumnIdentity(generatedWhen string) ColumnIdentity {
+	if generatedWhen == "a" {
+		return ColumnIdentityAlways
+	}
+
+	return ColumnIdentityByDefault
+}
+
+// deparseExpr turns an expression node back into SQL.
+func deparseExpr(expr *pg_query.Node) (*string, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	sql, err := pg_query.Deparse(&pg_query.ParseResult{
+		Stmts: []*pg_query.RawStmt{{
+			Stmt: &pg_query.Node{Node: &pg_query.Node_SelectStmt{SelectStmt: &pg_query.SelectStmt{
+				TargetList: []*pg_query.Node{pg_query.MakeResTargetNodeWithVal(expr, 0)},
+			}}},
+		}},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ptr.V(strings.TrimPrefix(sql, "SELECT ")), nil
+}
+
+func parseColumnType(db *DB, def *pg_query.ColumnDef) (*DataType, error) {
+	typeName := def.GetTypeName()
+	if typeName == nil {
+		return nil, errors.New("no type name")
+	}
+
+	t, err := parseTypeName(db, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	t.NotNull = t.NotNull || isNotNull(def)
+	return t, nil
+}
+
+// parseTypeName parses a type name into a `DataType`. User defined types are
+// resolved from `db`.
+func parseTypeName(db *DB, typeName *pg_query.TypeName) (*DataType, error) {
+	t := &DataType{}
+
+	names := typeName.GetNames()
+	if len(names) == 2 {
+		t.Schema = ptr.V(getString(names[0]))
+		t.Name = getString(names[1])
+	} else if len(names) == 1 {
+		t.Name = getString(names[0])
+	} else {
+		return nil, fmt.Errorf("a surprising amount of names (%d) in a type name", len(names))
+	}
+
+	t.ArrayDims = len(typeName.GetArrayBounds())
+	t.Array = t.ArrayDims > 0
+	t.Modifiers = parseTypeModifiers(typeName.GetTypmods())
+
+	t.Name = strings.ToLower(t.Name)
+	if t.Schema != nil {
+		t.Schema = ptr.V(strings.ToLower(*t.Schema))
+	}
+
+	db.resolveDataType(t)
+	return t, nil
+}
+
+// parseTypeModifiers returns the integer values of type modifiers like the
+// `12, 2` of `NUMERIC(12, 2)`. Nil is returned if any of the modifiers is
+// not an integer since those have a meaning only for extension types.
+func parseTypeModifiers(typmods []*pg_query.Node) []int {
+	if len(typmods) == 0 {
+		return nil
+	}
+
+	mods := make([]int, 0, len(typmods))
+
+	for _, m := range typmods {
+		c := m.GetAConst()
+		if c == nil || c.GetIval() == nil {
+			return nil
+		}
+
+		mods = append(mods, int(c.GetIval().GetIval()))
+	}
+
+	return mods
+}
+
+func isNotNull(def *pg_query.ColumnDef) bool {
+	for _, c := range def.GetConstraints() {
+		switch c.GetConstraint().GetContype() {
+		case pg_query.ConstrType_CONSTR_NOTNULL, pg_query.ConstrType_CONSTR_PRIMARY:
+			return true
+		}
+
+	}
+
+	return false
+}
+
+func drop(db *DB, stmt *pg_query.DropStmt) error {
+	switch stmt.GetRemoveType() {
+	case pg_query.ObjectType_OBJECT_TABLE:
+		return dropTables(db, stmt, TableKindTable)
+	case pg_query.ObjectType_OBJECT_VIEW:
+		return dropTables(db, stmt, TableKindView)
+	case pg_query.ObjectType_OBJECT_MATVIEW:
+		return dropTables(db, stmt, TableKindMaterializedView)
+	case pg_query.ObjectType_OBJECT_TYPE:
+		return dropType(db, stmt)
+	case pg_query.ObjectType_OBJECT_DOMAIN:
+		return dropDomain(db, stmt)
+	case pg_query.ObjectType_OBJECT_SCHEMA:
+		return dropSchema(db, stmt)
+	case pg_query.ObjectType_OBJECT_FUNCTION:
+		return dropFunctions(db, stmt)
+	case pg_query.ObjectType_OBJECT_PROCEDURE:
+		// Procedures are not tracked.
+		return nil
+	}
+
+	return &unsupportedError{tag: "DROP " + objectTypeName(stmt.GetRemoveType())}
+}
+
+// dropTables drops the tables or views listed in a drop statement. `kind`
+// is the kind of relations the statement drops.
+func dropTables(db *DB, stmt *pg_query.DropStmt, kind TableKind) error {
+	for _, o := range stmt.GetObjects() {
+		name, err := parseTableNameParts(o.GetList().GetItems())
+		if err != nil {
+			return err
+		}
+
+		table := db.FindTable(name)
+		if table == nil {
+			if stmt.GetMissingOk() {
+				continue
+			}
+
+			return fmt.Errorf(`unknown %s "%s"`, kind, name.String())
+		}
+
+		if table.Kind != kind {
+			return fmt.Errorf(`"%s" is not a %s`, name.String(), kind)
+		}
+
+		if err := dropTable(db, table, stmt.GetBehavior() == pg_query.DropBehavior_DROP_CASCADE); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropTable drops a table or a view. The views that depend on it are also dropped
+// if `cascade` is true. Otherwise an error is returned if there are any like postgres
+// does.
+func dropTable(db *DB, table *Table, cascade bool) error {
+	for _, d := range db.Dependents(*table.Name) {
+		if !cascade {
+			return fmt.Errorf(`cannot drop %s "%s" because %s "%s" depends on it`, table.Kind, table.Name.String(), d.Kind, d.Name.String())
+		}
+
+		if err := dropTable(db, d, cascade); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range db.Children(*table.Name) {
+		// Partitions are dropped with the partitioned table. Inheritance
+		// children need `CASCADE`.
+		if c.PartitionOf == nil && !cascade {
+			return fmt.Errorf(`cannot drop %s "%s" because table "%s" inherits from it`, table.Kind, table.Name.String(), c.Name.String())
+		}
+
+		if err := dropTable(db, c, cascade); err != nil {
+			return err
+		}
+	}
+
+	for _, fk := range db.ForeignKeysTo(*table.Name) {
+		if fk.Table == table {
+			continue
+		}
+
+		if !cascade {
+			return fmt.Errorf(`cannot drop %s "%s" because constraint "%s" on table "%s" depends on it`, table.Kind, table.Name.String(), fk.Constraint.Name, fk.Table.Name.String())
+		}
+
+		fk.Table.RemoveConstraint(fk.Constraint.Name)
+	}
+
+	db.RemoveTable(*table.Name)
+	return nil
+}
+
+func alterTable(db *DB, stmt *pg_query.AlterTableStmt) error {
+	switch stmt.GetObjtype() {
+	case pg_query.ObjectType_OBJECT_TYPE:
+		return alterCompositeType(db, stmt)
+	case pg_query.ObjectType_OBJECT_SEQUENCE, pg_query.ObjectType_OBJECT_INDEX:
+		// Sequences and indexes are not tracked.
+		unsupported := make([]error, 0, len(stmt.GetCmds()))
+
+		for _, cmd := range stmt.GetCmds() {
+			unsupported = append(unsupported, &unsupportedError{tag: alterTableCmdTag(stmt.GetObjtype(), cmd.GetAlterTableCmd().GetSubtype())})
+		}
+
+		return errors.Join(unsupported...)
+	}
+
+	table, err := lookupRangeVarTable(db, stmt.GetRelation(), stmt.GetMissingOk())
+	if err != nil || table == nil {
+		return err
+	}
+
+	// Without `ONLY` the changes are also made to the child tables.
+	return alterTableCmds(db, table, stmt.GetObjtype(), stmt.GetCmds(), stmt.GetRelation().GetInh())
+}
+
+// alterTableCmds applies the commands of an alter table statement to `table`
+// and to its child tables if `inh` is true. The attributes of composite types
+// are altered using the same commands. Unsupported commands are skipped and
+// returned as `unsupportedError`s once the other commands have been applied.
+func alterTableCmds(db *DB, table *Table, objtype pg_query.ObjectType, cmds []*pg_query.Node, inh bool) error {
+	unsupported := make([]error, 0)
+
+	for _, cmd := range cmds {
+		if skipAlterTableCmd(table, cmd.GetAlterTableCmd()) {
+			continue
+		}
+
+		err := alterTableCmd(db, table, cmd.GetAlterTableCmd())
+
+		if errors.Is(err, errUnsupportedAlterTableCmd) {
+			unsupported = append(unsupported, &unsupportedError{tag: alterTableCmdTag(objtype, cmd.GetAlterTableCmd().GetSubtype())})
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if inh {
+			if err := alterChildTables(db, table, cmd.GetAlterTableCmd()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return errors.Join(unsupported...)
+}
+
+// skipAlterTableCmd returns true for `ADD COLUMN IF NOT EXISTS` commands of
+// existing columns and `DROP COLUMN IF EXISTS` commands of missing columns.
+// Postgres skips them without recursing to the child tables.
+func skipAlterTableCmd(table *Table, alter *pg_query.AlterTableCmd) bool {
+	if !alter.GetMissingOk() {
+		return false
+	}
+
+	switch alter.GetSubtype() {
+	case pg_query.AlterTableType_AT_AddColumn:
+		return table.ColumnsByName[alter.GetDef().GetColumnDef().GetColname()] != nil
+	case pg_query.AlterTableType_AT_DropColumn:
+		return table.ColumnsByName[alter.GetName()] == nil
+	}
+
+	return false
+}
+
+// alterChildTables applies an alter table command that recurses to the child
+// tables of `parent` and their children.
+func alterChildTables(db *DB, parent *Table, alter *pg_query.AlterTableCmd) error {
+	for _, child := range db.Children(*parent.Name) {
+		var err error
+
+		switch alter.Subtype {
+		case pg_query.AlterTableType_AT_AddColumn:
+			def := alter.Def.GetColumnDef()
+
+			if col := child.ColumnsByName[def.GetColname()]; col != nil {
+				err = mergeColumn(db, child, col, def)
+			} else {
+				err = addColumn(db, child, def)
+			}
+		case pg_query.AlterTableType_AT_DropColumn:
+			if child.ColumnsByName[alter.GetName()] != nil {
+				err = removeColumn(db, child, alter.GetName(), alter.GetBehavior() == pg_query.DropBehavior_DROP_CASCADE)
+			}
+		case pg_query.AlterTableType_AT_AddConstraint:
+			inheritConstraints(child, parent)
+		case pg_query.AlterTableType_AT_DropConstraint:
+			child.RemoveConstraint(alter.GetName())
+		case pg_query.AlterTableType_AT_SetNotNull,
+			pg_query.AlterTableType_AT_DropNotNull,
+			pg_query.AlterTableType_AT_AlterColumnType,
+			pg_query.AlterTableType_AT_ColumnDefault:
+			err = alterTableCmd(db, child, alter)
+		default:
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf(`failed to alter child table "%s": %w`, child.Name.String(), err)
+		}
+
+		if err := alterChildTables(db, child, alter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func alterTableCmd(db *DB, table *Table, alter *pg_query.AlterTableCmd) error {
+	switch alter.Subtype {
+	case pg_query.AlterTableType_AT_AddColumn:
+		if err := addColumn(db, table, alter.Def.GetColumnDef()); err != nil {
+			return fmt.Errorf("failed to add column: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DropColumn:
+		if err := removeColumn(db, table, alter.GetName(), alter.GetBehavior() == pg_query.DropBehavior_DROP_CASCADE); err != nil {
+			return fmt.Errorf("failed to drop column: %w", err)
+		}
+	case pg_query.AlterTableType_AT_SetNotNull:
+		if err := setNotNull(table, alter.GetName()); err != nil {
+			return fmt.Errorf("failed to set column not null: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DropNotNull:
+		if err := dropNotNull(table, alter.GetName()); err != nil {
+			return fmt.Errorf("failed to drop not null: %w", err)
+		}
+	case pg_query.AlterTableType_AT_AlterColumnType:
+		if err := alterColumnType(db, table, alter.GetName(), alter.Def.GetColumnDef()); err != nil {
+			return fmt.Errorf("failed to alter column type: %w", err)
+		}
+	case pg_query.AlterTableType_AT_AddConstraint:
+		if err := addConstraint(db, table, alter.GetDef().GetConstraint(), ""); err != nil {
+			return fmt.Errorf("failed to add constraint: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DropConstraint:
+		if err := dropConstraint(table, alter.GetName(), alter.GetMissingOk()); err != nil {
+			return fmt.Errorf("failed to drop constraint: %w", err)
+		}
+	case pg_query.AlterTableType_AT_ColumnDefault:
+		if err := setColumnDefault(table, alter.GetName(), alter.GetDef()); err != nil {
+			return fmt.Errorf("failed to alter column default: %w", err)
+		}
+	case pg_query.AlterTableType_AT_AddIdentity:
+		if err := addIdentity(table, alter.GetName(), alter.GetDef().GetConstraint()); err != nil {
+			return fmt.Errorf("failed to add identity: %w", err)
+		}
+	case pg_query.AlterTableType_AT_SetIdentity:
+		if err := setIdentity(table, alter.GetName(), alter.GetDef().GetList().GetItems()); err != nil {
+			return fmt.Errorf("failed to set identity: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DropIdentity:
+		if err := dropIdentity(table, alter.GetName(), alter.GetMissingOk()); err != nil {
+			return fmt.Errorf("failed to drop identity: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DropExpression:
+		if err := dropExpression(table, alter.GetName(), alter.GetMissingOk()); err != nil {
+			return fmt.Errorf("failed to drop expression: %w", err)
+		}
+	case pg_query.AlterTableType_AT_AttachPartition:
+		if err := attachPartition(db, table, alter.GetDef().GetPartitionCmd().GetName()); err != nil {
+			return fmt.Errorf("failed to attach partition: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DetachPartition:
+		if err := detachPartition(db, table, alter.GetDef().GetPartitionCmd().GetName()); err != nil {
+			return fmt.Errorf("failed to detach partition: %w", err)
+		}
+	case pg_query.AlterTableType_AT_AddInherit:
+		if err := addInherit(db, table, alter.GetDef().GetRangeVar()); err != nil {
+			return fmt.Errorf("failed to add inheritance: %w", err)
+		}
+	case pg_query.AlterTableType_AT_DropInherit:
+		if err := dropInherit(db, table, alter.GetDef().GetRangeVar()); err != nil {
+			return fmt.Errorf("failed to remove inheritance: %w", err)
+		}
+	case pg_query.AlterTableType_AT_ValidateConstraint:
+		// Validating a `NOT VALID` constraint doesn't change the schema.
+	default:
+		return errUnsupportedAlterTableCmd
+	}
+
+	return nil
+}
+
+func attachPartition(db *DB, parent *Table, rel *pg_query.RangeVar) error {
+	if !parent.Partitioned {
+		return fmt.Errorf(`table "%s" is not partitioned`, parent.Name.String())
+	}
+
+	child, err := findRangeVarTable(db, rel)
+	if err != nil {
+		return err
+	}
+
+	if child.PartitionOf != nil || len(child.Inherits) > 0 {
+		return fmt.Errorf(`"%s" is already a child table`, child.Name.String())
+	}
+
+	if err := checkChildColumns(child, parent); err != nil {
+		return err
+	}
+
+	child.PartitionOf = parent.Name.Clone()
+	inheritConstraints(child, parent)
+	return nil
+}
+
+func detachPartition(db *DB, parent *Table, rel *pg_query.RangeVar) error {
+	child, err := findRangeVarTable(db, rel)
+	if err != nil {
+		return err
+	}
+
+	if child.PartitionOf == nil || *child.PartitionOf != *parent.Name {
+		return fmt.Errorf(`relation "%s" is not a partition of relation "%s"`, child.Name.String(), parent.Name.String())
+	}
+
+	child.PartitionOf = nil
+	return nil
+}
+
+func addInherit(db *DB, child *Table, rel *pg_query.RangeVar) error {
+	parent, err := findRangeVarTable(db, rel)
+	if err != nil {
+		return err
+	}
+
+	if parent.Partitioned || child.PartitionOf != nil {
+		return errors.New("cannot change inheritance of a partitioned table or a partition")
+	}
+
+	if child.IsChildOf(*parent.Name) {
+		return fmt.Errorf(`relation "%s" would be inherited from more than once`, parent.Name.String())
+	}
+
+	if err := checkChildColumns(child, parent); err != nil {
+		return err
+	}
+
+	child.Inherits = append(child.Inherits, *parent.Name)
+	inheritConstraints(child, parent)
+	return nil
+}
+
+func dropInherit(db *DB, child *Table, rel *pg_query.RangeVar) error {
+	parent, err := findRangeVarTable(db, rel)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(child.Inherits, *parent.Name) {
+		return fmt.Errorf(`relation "%s" is not a parent of relation "%s"`, parent.Name.String(), child.Name.String())
+	}
+
+	child.Inherits = slices.DeleteFunc(child.Inherits, func(n TableName) bool { return n == *parent.Name })
+	return nil
+}
+
+// checkChildColumns makes sure a table that is attached as a child of
+// `parent` has all columns of the parent with the same types.
+func checkChildColumns(child *Table, parent *Table) error {
+	for _, c := range parent.Columns {
+		col := child.ColumnsByName[c.Name]
+		if col == nil {
+			return fmt.Errorf(`child table "%s" is missing column "%s"`, child.Name.String(), c.Name)
+		}
+
+		if !col.Type.HasSameType(&c.Type) {
+			return fmt.Errorf(`child table "%s" has different type for column "%s"`, child.Name.String(), c.Name)
+		}
+	}
+
+	return nil
+}
+
+func removeColumn(db *DB, table *Table, colName string, cascade bool) error {
+	_, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	if table.Name != nil {
+		for _, fk := range db.ForeignKeysTo(*table.Name) {
+			if !slices.Contains(fk.Constraint.ReferencedColumns, colName) {
+				continue
+			}
+
+			if !cascade {
+				return fmt.Errorf(`cannot drop column "%s" of table "%s" because constraint "%s" on table "%s" depends on it`, colName, table.Name.String(), fk.Constraint.Name, fk.Table.Name.String())
+			}
+
+			fk.Table.RemoveConstraint(fk.Constraint.Name)
+		}
+
+		for _, v := range db.Dependents(*table.Name) {
+			if !v.DependsOnColumn(*table.Name, colName) {
+				continue
+			}
+
+			if !cascade {
+				return fmt.Errorf(`cannot drop column "%s" of table "%s" because %s "%s" depends on it`, colName, table.Name.String(), v.Kind, v.Name.String())
+			}
+
+			if err := dropTable(db, v, cascade); err != nil {
+				return err
+			}
+		}
+	}
+
+	table.RemoveColumn(colName)
+	return nil
+}
+
+func setNotNull(table *Table, colName string) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	col.Type.NotNull = true
+	return nil
+}
+
+func dropNotNull(table *Table, colName string) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	col.Type.NotNull = false
+	return nil
+}
+
+func dropConstraint(table *Table, name string, missingOk bool) error {
+	if table.ConstraintsByName[name] == nil {
+		if missingOk {
+			return nil
+		}
+
+		return fmt.Errorf(`constraint "%s" of relation "%s" does not exist`, name, table.Name.String())
+	}
+
+	table.RemoveConstraint(name)
+	return nil
+}
+
+func setColumnDefault(table *Table, colName string, expr *pg_query.Node) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	def, err := deparseExpr(expr)
+	if err != nil {
+		return err
+	}
+
+	col.Default = def
+	return nil
+}
+
+func addIdentity(table *Table, colName string, c *pg_query.Constraint) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	if col.Identity != "" {
+		return fmt.Errorf(`column "%s" of table "%s" is already an identity column`, colName, table.Name)
+	}
+
+	return setColumnConstraint(col, c)
+}
+
+func setIdentity(table *Table, colName string, options []*pg_query.Node) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	if col.Identity == "" {
+		return fmt.Errorf(`column "%s" of table "%s" is not an identity column`, colName, table.Name)
+	}
+
+	for _, o := range options {
+		if def := o.GetDefElem(); def.GetDefname() == "generated" {
+			col.Identity = parseColumnIdentity(string(rune(def.GetArg().GetInteger().GetIval())))
+		}
+	}
+
+	return nil
+}
+
+func dropIdentity(table *Table, colName string, missingOk bool) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	if col.Identity == "" && !missingOk {
+		return fmt.Errorf(`column "%s" of table "%s" is not an identity column`, colName, table.Name)
+	}
+
+	col.Identity = ""
+	return nil
+}
+
+func dropExpression(table *Table, colName string, missingOk bool) error {
+	col, ok := table.ColumnsByName[colName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+	}
+
+	if col.Generated == nil && !missingOk {
+		return fmt.Errorf(`column "%s" of table "%s" is not a stored generated column`, colName, table.Name)
+	}
+
+	col.Generated = nil
+	return nil
+}
+
+func alterColumnType(db *DB, table *Table, columnName string, def *pg_query.ColumnDef) error {
+	t, err := parseColumnType(db, def)
+	if err != nil {
+		return err
+	}
+
+	col, ok := table.ColumnsByName[columnName]
+	if !ok {
+		return fmt.Errorf(`could not find column "%s" in table "%s"`, columnName, table.Name)
+	}
+
+	// The new type replaces the old one completely. Only the nullability
+	// of the column is kept.
+	notNull := col.Type.NotNull
+	col.Type = *t
+	col.Type.NotNull = notNull || t.NotNull
+	return nil
+}
+
+func rename(db *DB, stmt *pg_query.RenameStmt) error {
+	switch stmt.GetRenameType() {
+	case pg_query.ObjectType_OBJECT_TYPE, pg_query.ObjectType_OBJECT_DOMAIN:
+		return renameType(db, stmt)
+	case pg_query.ObjectType_OBJECT_ATTRIBUTE:
+		return renameAttribute(db, stmt)
+	case pg_query.ObjectType_OBJECT_SCHEMA:
+		return renameSchema(db, stmt)
+	case pg_query.ObjectType_OBJECT_FUNCTION:
+		return renameFunction(db, stmt)
+	case pg_query.ObjectType_OBJECT_COLUMN,
+		pg_query.ObjectType_OBJECT_TABCONSTRAINT,
+		pg_query.ObjectType_OBJECT_TABLE,
+		pg_query.ObjectType_OBJECT_VIEW,
+		pg_query.ObjectType_OBJECT_MATVIEW:
+	default:
+		return &unsupportedError{tag: "ALTER " + objectTypeName(stmt.GetRenameType()) + " RENAME"}
+	}
+
+	table, err := lookupRangeVarTable(db, stmt.GetRelation(), stmt.GetMissingOk())
+	if err != nil || table == nil {
+		return err
+	}
+
+	switch stmt.GetRenameType() {
+	case pg_query.ObjectType_OBJECT_COLUMN:
+		if col := table.ColumnsByName[stmt.GetSubname()]; col == nil {
+			return fmt.Errorf(`unknown column "%s" in table "%s"`, stmt.GetSubname(), table.Name.String())
+		} else {
+			renameColumn(db, table, stmt.GetSubname(), stmt.GetNewname())
+		}
+	case pg_query.ObjectType_OBJECT_TABCONSTRAINT:
+		if c := table.ConstraintsByName[stmt.GetSubname()]; c == nil {
+			return fmt.Errorf(`constraint "%s" for table "%s" does not exist`, stmt.GetSubname(), table.Name.String())
+		} else if table.ConstraintsByName[stmt.GetNewname()] != nil {
+			return fmt.Errorf(`constraint "%s" for relation "%s" already exists`, stmt.GetNewname(), table.Name.String())
+		} else {
+			table.RenameConstraint(stmt.GetSubname(), stmt.GetNewname())
+		}
+	case pg_query.ObjectType_OBJECT_TABLE, pg_query.ObjectType_OBJECT_VIEW, pg_query.ObjectType_OBJECT_MATVIEW:
+		newName := NewTableName(stmt.GetNewname(), table.Name.Schema)
+		if db.TablesByName[newName] != nil {
+			return fmt.Errorf(`relation "%s" already exists`, newName.String())
+		}
+
+		db.RenameTable(*table.Name, newName)
+	}
+
+	return nil
+}
+
+// renameColumn renames a column of a table and the inherited columns of its
+// child tables. Foreign keys referencing the columns are updated.
+func renameColumn(db *DB, table *Table, name string, newName string) {
+	table.RenameColumn(name, newName)
+
+	for _, fk := range db.ForeignKeysTo(*table.Name) {
+		fk.Constraint.RenameReferencedColumn(name, newName)
+	}
+
+	// Views keep referencing the renamed column.
+	for _, v := range db.Dependents(*table.Name) {
+		if i := slices.Index(v.columnRefs, name); i != -1 {
+			v.columnRefs[i] = newName
+		}
+	}
+
+	for _, c := range db.Children(*table.Name) {
+		if c.ColumnsByName[name] != nil {
+			renameColumn(db, c, name, newName)
+		}
+	}
+}
+
+// comment handles `COMMENT ON` statements of tables, views and columns. An
+// empty comment removes the comment. Comments on other objects are reported
+// as unsupported.
+func comment(db *DB, stmt *pg_query.CommentStmt) error {
+	var value *string
+	if len(stmt.GetComment()) != 0 {
+		value = ptr.V(stmt.GetComment())
+	}
+
+	names := stmt.GetObject().GetList().GetItems()
+
+	switch stmt.GetObjtype() {
+	case pg_query.ObjectType_OBJECT_TABLE, pg_query.ObjectType_OBJECT_VIEW, pg_query.ObjectType_OBJECT_MATVIEW:
+		name, err := parseTableNameParts(names)
+		if err != nil {
+			return err
+		}
+
+		table := db.FindTable(name)
+		if table == nil {
+			return fmt.Errorf(`unknown table "%s"`, name.String())
+		}
+
+		table.Comment = value
+	case pg_query.ObjectType_OBJECT_COLUMN:
+		if len(names) < 2 {
+			return errors.New("column name without a table name")
+		}
+
+		tableName, err := parseTableNameParts(names[:len(names)-1])
+		if err != nil {
+			return err
+		}
+
+		columns := findCommentColumns(db, tableName)
+		if columns == nil {
+			return fmt.Errorf(`unknown table "%s"`, tableName.String())
+		}
+
+		name := getString(names[len(names)-1])
+
+		col := columns.ColumnsByName[name]
+		if col == nil {
+			return fmt.Errorf(`unknown column "%s" in table "%s"`, name, tableName.String())
+		}
+
+		col.Comment = value
+	default:
+		// The tag doesn't include the object type so that configurations
+		// that allow "COMMENT" keep working.
+		return &unsupportedError{tag: "COMMENT"}
+	}
+
+	return nil
+}
+
+// findCommentColumns returns the columns of the table, view or composite type
+// `name` whose column `COMMENT ON COLUMN` targets or nil if there's no such
+// relation.
+func findCommentColumns(db *DB, name TableName) *Table {
+	if table := db.FindTable(name); table != nil {
+		return table
+	}
+
+	if c := db.FindCompositeType(NewTypeName(name.Name, name.Schema)); c != nil {
+		return c.Attributes
+	}
+
+	return nil
+}
+
+func createSchema(db *DB, stmt *pg_query.CreateSchemaStmt) error {
+	name := stmt.GetSchemaname()
+	if len(name) == 0 {
+		return errors.New("schemas without an explicit name are not supported")
+	}
+
+	if db.HasSchema(name) {
+		if stmt.GetIfNotExists() {
+			return nil
+		}
+
+		return fmt.Errorf(`schema "%s" already exists`, name)
+	}
+
+	db.AddSchema(name)
+	return nil
+}
+
+func dropSchema(db *DB, stmt *pg_query.DropStmt) error {
+	cascade := stmt.GetBehavior() == pg_query.DropBehavior_DROP_CASCADE
+
+	for _, o := range stmt.GetObjects() {
+		name := getString(o)
+
+		if !db.HasSchema(name) {
+			if stmt.GetMissingOk() {
+				continue
+			}
+
+			return fmt.Errorf(`unknown schema "%s"`, name)
+		}
+
+		for _, t := range slices.Clone(db.Tables) {
+			if t.Name.Schema != name || db.TablesByName[*t.Name] == nil {
+				// Skip tables of other schemas and views that were already
+				// dropped along with their tables.
+				continue
+			}
+
+			if !cascade {
+				return fmt.Errorf(`cannot drop schema "%s" because %s "%s" depends on it`, name, t.Kind, t.Name.String())
+			}
+
+			if err := dropTable(db, t, cascade); err != nil {
+				return err
+			}
+		}
+
+		for _, n := range db.UserTypeNames() {
+			if n.Schema != name {
+				continue
+			}
+
+			if !cascade {
+				return fmt.Errorf(`cannot drop schema "%s" because type "%s" depends on it`, name, n.String())
+			}
+
+			if err := dropUserType(db, n, cascade); err != nil {
+				return err
+			}
+		}
+
+		for _, f := range slices.Clone(db.Functions) {
+			if f.Name.Schema != name {
+				continue
+			}
+
+			if !cascade {
+				return fmt.Errorf(`cannot drop schema "%s" because function "%s" depends on it`, name, f.Name.String())
+			}
+
+			db.RemoveFunction(f)
+		}
+
+		db.RemoveSchema(name)
+	}
+
+	return nil
+}
+
+func renameSchema(db *DB, stmt *pg_query.RenameStmt) error {
+	name := stmt.GetSubname()
+	newName := stmt.GetNewname()
+
+	if !db.HasSchema(name) {
+		return fmt.Errorf(`unknown schema "%s"`, name)
+	}
+
+	if db.HasSchema(newName) {
+		return fmt.Errorf(`schema "%s" already exists`, newName)
+	}
+
+	db.AddSchema(newName)
+
+	for _, t := range slices.Clone(db.Tables) {
+		if t.Name.Schema == name {
+			db.RenameTable(*t.Name, NewTableName(t.Name.Name, newName))
+		}
+	}
+
+	for _, n := range db.UserTypeNames() {
+		if n.Schema == name {
+			renameUserType(db, n, NewTypeName(n.Name, newName))
+		}
+	}
+
+	for _, f := range slices.Clone(db.Functions) {
+		if f.Name.Schema == name {
+			db.RenameFunction(f, NewTypeName(f.Name.Name, newName))
+		}
+	}
+
+	db.RemoveSchema(name)
+	return nil
+}
+
+// alterObjectSchema moves a table, a view, a user defined type or a function to another schema.
+func alterObjectSchema(db *DB, stmt *pg_query.AlterObjectSchemaStmt) error {
+	schema := stmt.GetNewschema()
+	if !db.HasSchema(schema) {
+		return fmt.Errorf(`schema "%s" does not exist`, schema)
+	}
+
+	switch stmt.GetObjectType() {
+	case pg_query.ObjectType_OBJECT_TABLE, pg_query.ObjectType_OBJECT_VIEW, pg_query.ObjectType_OBJECT_MATVIEW:
+		table, err := lookupRangeVarTable(db, stmt.GetRelation(), stmt.GetMissingOk())
+		if err != nil || table == nil {
+			return err
+		}
+
+		newName := NewTableName(table.Name.Name, schema)
+		if db.TablesByName[newName] != nil {
+			return fmt.Errorf(`relation "%s" already exists in schema "%s"`, newName.Name, schema)
+		}
+
+		db.RenameTable(*table.Name, newName)
+	case pg_query.ObjectType_OBJECT_TYPE, pg_query.ObjectType_OBJECT_DOMAIN:
+		name, err := findUserTypeName(db, stmt.GetObject())
+		if err != nil {
+			return err
+		}
+
+		newName := NewTypeName(name.Name, schema)
+		if userTypeExists(db, newName) {
+			return fmt.Errorf(`type "%s" already exists in schema "%s"`, newName.Name, schema)
+		}
+
+		renameUserType(db, name, newName)
+	case pg_query.ObjectType_OBJECT_FUNCTION:
+		f, err := findFunction(db, stmt.GetObject().GetObjectWithArgs())
+		if err != nil {
+			return err
+		}
+
+		return moveFunction(db, f, NewTypeName(f.Name.Name, schema))
+	default:
+		return &unsupportedError{tag: "ALTER " + objectTypeName(stmt.GetObjectType()) + " SET SCHEMA"}
+	}
+
+	return nil
+}
+
+func createView(db *DB, sql string, stmt *pg_query.ViewStmt) error {
+	name, err := parseNewRangeVarName(db, stmt.GetView())
+	if err != nil {
+		return err
+	}
+
+	view, err := parseViewQuery(db, sql, stmt.GetQuery())
+	if err != nil {
+		return fmt.Errorf(`failed to analyze the query of view "%s": %w`, name.String(), err)
+	}
+
+	if err := setColumnNames(view, stmt.GetAliases()); err != nil {
+		return err
+	}
+
+	view.Name = &name
+	view.Kind = TableKindView
+
+	if existing := db.TablesByName[name]; existing != nil {
+		if !stmt.GetReplace() || existing.Kind != TableKindView {
+			return fmt.Errorf(`relation "%s" already exists`, name.String())
+		}
+
+		db.ReplaceTable(view)
+		return nil
+	}
+
+	db.AddTable(view)
+	return nil
+}
+
+// createTableAs handles `CREATE TABLE ... AS` and `CREATE MATERIALIZED VIEW`
+// statements. The columns are derived by analyzing the query.
+func createTableAs(db *DB, sql string, stmt *pg_query.CreateTableAsStmt) error {
+	switch stmt.GetObjtype() {
+	case pg_query.ObjectType_OBJECT_TABLE:
+		return createTableFromQuery(db, sql, stmt.GetInto(), stmt.GetQuery(), TableKindTable, stmt.GetIfNotExists())
+	case pg_query.ObjectType_OBJECT_MATVIEW:
+		return createTableFromQuery(db, sql, stmt.GetInto(), stmt.GetQuery(), TableKindMaterializedView, stmt.GetIfNotExists())
+	}
+
+	return nil
+}
+
+// selectInto handles `SELECT ... INTO` statements which create a table like
+// `CREATE TABLE ... AS`.
+func selectInto(db *DB, sql string, stmt *pg_query.SelectStmt) error {
+	query := &pg_query.Node{Node: &pg_query.Node_SelectStmt{SelectStmt: stmt}}
+	return createTableFromQuery(db, sql, stmt.GetIntoClause(), query, TableKindTable, false)
+}
+
+func createTableFromQuery(
+	db *DB,
+	sql string,
+	into *pg_query.IntoClause,
+	query *pg_query.Node,
+	kind TableKind,
+	ifNotExists bool,
+) error {
+	name, err := parseNewRangeVarName(db, into.GetRel())
+	if err != nil {
+		return err
+	}
+
+	if db.TablesByName[name] != nil {
+		if ifNotExists {
+			return nil
+		}
+
+		return fmt.Errorf(`relation "%s" already exists`, name.String())
+	}
+
+	table, err := parseViewQuery(db, sql, query)
+	if err != nil {
+		return fmt.Errorf(`failed to analyze the query of %s "%s": %w`, kind, name.String(), err)
+	}
+
+	if err := setColumnNames(table, into.GetColNames()); err != nil {
+		return err
+	}
+
+	table.Name = &name
+	table.Kind = kind
+
+	if kind == TableKindTable {
+		// A table doesn't depend on the relations it was created from and its
+		// columns don't inherit the not null constraints of the query. Only
+		// the not null constraint of a domain type remains.
+		table.DependsOn = nil
+		table.columnRefs = nil
+
+		for _, c := range table.Columns {
+			c.Type.NotNull = c.Type.valueDomain() != nil && c.Type.Domain.Type.NotNull
+		}
+	}
+
+	db.AddTable(table)
+	return nil
+}
+
+// parseViewQuery analyzes the query of a view using the same analysis as
+// for queries. The names of the relations the query selects from are
+// stored in `DependsOn` of the returned table.
+func parseViewQuery(db *DB, sql string, query *pg_query.Node) (*Table, error) {
+	ctx := &QueryParseContext{
+		DB:               db,
+		JoinedTables:     make([]JoinedTable, 0),
+		SQL:              sql,
+		locations:        make([]int32, 0),
+		referencedTables: make(map[TableName]bool),
+	}
+
+	view, err := parseStmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveUnknownTypes(view)
+
+	// Subqueries are analyzed using clones of `db`. Make the columns use the
+	// types of `db` so that altering or dropping the types affects them.
+	view.ForEachDataType(db.linkDataType)
+
+	// The result doesn't inherit the constraints of a table selected using
+	// `*` or the defaults and comments of the columns it was selected from.
+	view.Constraints = make([]*Constraint, 0)
+	view.ConstraintsByName = make(map[string]*Constraint)
+
+	for _, c := range view.Columns {
+		c.Default = nil
+		c.Identity = ""
+		c.Generated = nil
+		c.Comment = nil
+	}
+
+	view.columnRefs = columnRefNames(query)
+	star := hasStarRef(query)
+
+	for _, t := range existingTables(db, ctx.referencedTables) {
+		view.DependsOn = append(view.DependsOn, *t.Name)
+
+		if star {
+			for _, c := range t.Columns {
+				if !slices.Contains(view.columnRefs, c.Name) {
+					view.columnRefs = append(view.columnRefs, c.Name)
+				}
+			}
+		}
+	}
+
+	return view, nil
+}
+
+// existingTables returns the tables and views of `names` ordered by their
+// names. CTEs and subqueries also end up in the referenced tables of a query
+// so only relations that actually exist in the database are kept.
+func existingTables(db *DB, names map[TableName]bool) []*Table {
+	tables := make([]*Table, 0, len(names))
+
+	for name := range names {
+		if t := db.TablesByName[name]; t != nil && len(t.Kind) != 0 {
+			tables = append(tables, t)
+		}
+	}
+
+	slices.SortFunc(tables, func(a, b *Table) int {
+		return strings.Compare(a.Name.String(), b.Name.String())
+	})
+
+	return tables
+}
+
+// setColumnNames renames the columns of the table using the explicit column
+// names of a view or a `CREATE TABLE AS` statement. Like in postgres, there
+// can be fewer names than columns.
+func setColumnNames(table *Table, names []*pg_query.Node) error {
+	if len(names) > len(table.Columns) {
+		return errors.New("more column names than columns specified")
+	}
+
+	columns := table.Columns
+	table.Columns = make([]*Column, 0, len(columns))
+	table.ColumnsByName = make(map[string]*Column, len(columns))
+
+	for i, c := range columns {
+		if i < len(names) {
+			c.Name = getString(names[i])
+		}
+
+		table.AddColumn(c)
+	}
+
+	return nil
+}
+
+// parseNewRangeVarName parses the name of a new table or view. Unqualified names
+// are placed in the creation schema of the search path.
+func parseNewRangeVarName(db *DB, rel *pg_query.RangeVar) (TableName, error) {
+	name, err := parseRangeVarName(rel)
 	if err != nil {
-		return fmt.Errorf(`failed to omit down migration: %w`, err)
+		return name, err
 	}
 
-	ast, err := parseSql(upMigration)
+	return db.QualifyTableName(name)
+}
+
+// findRangeVarTable finds the existing table or view a range var refers to.
+func findRangeVarTable(db *DB, rel *pg_query.RangeVar) (*Table, error) {
+	return lookupRangeVarTable(db, rel, false)
+}
+
+// lookupRangeVarTable finds the table or view a range var refers to like
+// `findRangeVarTable`. If `missingOk` is true, nil is returned instead of
+// an error for missing relations like postgres does for `IF EXISTS`.
+func lookupRangeVarTable(db *DB, rel *pg_query.RangeVar, missingOk bool) (*Table, error) {
+	name, err := parseRangeVarName(rel)
 	if err != nil {
-		return fmt.Errorf(`failed to parse: %w`, err)
+		return nil, err
 	}
 
-	for _, s := range ast.GetStmts() {
-		switch node := s.GetStmt().GetNode().(type) {
-		case *pg_query.Node_CreateStmt:
-			if err := createTable(db, node.CreateStmt); err != nil {
-				return fmt.Errorf(`failed to parse a create table statement: %w`, err)
-			}
-		case *pg_query.Node_DropStmt:
-			if err := dropTable(db, node.DropStmt); err != nil {
-				return fmt.Errorf(`failed to parse a drop table statement: %w`, err)
-			}
-		case *pg_query.Node_AlterTableStmt:
-			if err := alterTable(db, node.AlterTableStmt); err != nil {
-				return fmt.Errorf(`failed to parse an alter table statement: %w`, err)
-			}
-		case *pg_query.Node_RenameStmt:
-			if err := rename(db, node.RenameStmt); err != nil {
-				return fmt.Errorf(`failed to parse a rename statement: %w`, err)
-			}
-		}
+	table := db.FindTable(name)
+	if table == nil && !missingOk {
+		return nil, fmt.Errorf(`unknown table "%s"`, name.String())
 	}
 
-	return nil
+	return table, nil
 }
 
-func createTable(db *DB, stmt *pg_query.CreateStmt) error {
-	rel := stmt.GetRelation()
+func parseRangeVarName(rel *pg_query.RangeVar) (TableName, error) {
 	if rel == nil {
-		return errors.New("no relation")
+		return TableName{}, errors.New("no relation")
 	}
 
 	name := rel.GetRelname()
 	if len(name) == 0 {
-		return errors.New("empty table name")
+		return TableName{}, errors.New("empty table name")
 	}
 
-	table := NewTable(NewTableName(name, rel.GetSchemaname()))
-	for _, c := range stmt.GetTableElts() {
-		if def := c.GetColumnDef(); def != nil {
-			if err := addColumn(table, def); err != nil {
-				return err
-			}
-		} else if like := c.GetTableLikeClause(); like != nil {
-			likeTable := db.TablesByName[NewTableName(like.GetRelation().GetRelname())]
-			if likeTable == nil {
-				return fmt.Errorf(`tried to create a table using like clause with unknown table "%s"`, like.GetRelation().GetRelname())
-			}
+	return NewTableName(name, rel.GetSchemaname()), nil
+}
 
-			for _, col := range likeTable.Columns {
-				table.AddColumn(col)
-			}
-		}
+// parseTableNameParts parses a possibly schema qualified table name
+// from the name parts of the AST.
+func parseTableNameParts(names []*pg_query.Node) (TableName, error) {
+	switch len(names) {
+	case 1:
+		return NewTableName(getString(names[0])), nil
+	case 2:
+		return NewTableName(getString(names[1]), getString(names[0])), nil
 	}
 
-	db.AddTable(table)
-	return nil
+	return TableName{}, fmt.Errorf("a surprising amount of names (%d) in a table name", len(names))
 }
 
-func addColumn(table *Table, def *pg_query.ColumnDef) error {
-	if col, err := parseColumnDef(def); err != nil {
+func createEnum(db *DB, stmt *pg_query.CreateEnumStmt) error {
+	name, err := parseNewTypeNameParts(db, stmt.GetTypeName())
+	if err != nil {
 		return err
-	} else {
-		table.AddColumn(col)
 	}
 
+	if userTypeExists(db, name) {
+		return fmt.Errorf(`type "%s" already exists`, name.String())
+	}
+
+	enum := NewEnum(name)
+	for _, v := range stmt.GetVals() {
+		enum.Labels = append(enum.Labels, getString(v))
+	}
+
+	db.AddEnum(enum)
 	return nil
 }
 
-func parseColumnDef(def *pg_query.ColumnDef) (*Column, error) {
-	col := Column{
-		Name: def.GetColname(),
+func alterEnum(db *DB, stmt *pg_query.AlterEnumStmt) error {
+	name, err := parseTypeNameParts(stmt.GetTypeName())
+	if err != nil {
+		return err
 	}
 
-	if t, err := parseColumnType(def); err != nil {
-		return nil, fmt.Errorf(`failed to parse type for column "%s": %w`, col.Name, err)
-	} else {
-		col.Type = *t
+	enum := db.FindEnum(name)
+	if enum == nil {
+		return fmt.Errorf(`unknown enum type "%s"`, name.String())
 	}
 
-	return &col, nil
+	if len(stmt.GetOldVal()) != 0 {
+		if !enum.HasLabel(stmt.GetOldVal()) {
+			return fmt.Errorf(`"%s" is not an existing enum label of type "%s"`, stmt.GetOldVal(), name.String())
+		}
+
+		enum.RenameLabel(stmt.GetOldVal(), stmt.GetNewVal())
+		return nil
+	}
+
+	if enum.HasLabel(stmt.GetNewVal()) {
+		if stmt.GetSkipIfNewValExists() {
+			return nil
+		}
+
+		return fmt.Errorf(`enum label "%s" already exists in type "%s"`, stmt.GetNewVal(), name.String())
+	}
+
+	if len(stmt.GetNewValNeighbor()) != 0 && !enum.HasLabel(stmt.GetNewValNeighbor()) {
+		return fmt.Errorf(`"%s" is not an existing enum label of type "%s"`, stmt.GetNewValNeighbor(), name.String())
+	}
+
+	enum.AddLabel(stmt.GetNewVal(), stmt.GetNewValNeighbor(), stmt.GetNewValIsAfter())
+	return nil
 }
 
-func parseColumnType(def *pg_query.ColumnDef) (*DataType, error) {
-	typeName := def.GetTypeName()
-	if typeName == nil {
-		return nil, errors.New("no type name")
+func createDomain(db *DB, stmt *pg_query.CreateDomainStmt) error {
+	name, err := parseNewTypeNameParts(db, stmt.GetDomainname())
+	if err != nil {
+		return err
+	}
+
+	if userTypeExists(db, name) {
+		return fmt.Errorf(`type "%s" already exists`, name.String())
 	}
 
-	t, err := parseTypeName(typeName)
+	t, err := parseTypeName(db, stmt.GetTypeName())
 	if err != nil {
-		return nil, err
+		return fmt.Errorf(`failed to parse the type of domain "%s": %w`, name.String(), err)
 	}
 
-	t.NotNull = isNotNull(def)
-	return t, nil
+	domain := NewDomain(name, *t)
+
+	for _, c := range stmt.GetConstraints() {
+		switch c.GetConstraint().GetContype() {
+		case pg_query.ConstrType_CONSTR_NOTNULL:
+			domain.Type.NotNull = true
+		case pg_query.ConstrType_CONSTR_DEFAULT:
+			if domain.Default, err = deparseExpr(c.GetConstraint().GetRawExpr()); err != nil {
+				return fmt.Errorf(`failed to parse the default of domain "%s": %w`, name.String(), err)
+			}
+		}
+	}
+
+	db.AddDomain(domain)
+	return nil
 }
 
-func parseTypeName(typeName *pg_query.TypeName) (*DataType, error) {
-	t := &DataType{}
+func createCompositeType(db *DB, stmt *pg_query.CompositeTypeStmt) error {
+	rel := stmt.GetTypevar()
 
-	names := typeName.GetNames()
-	if len(names) == 2 {
-		t.Schema = ptr.V(getString(names[0]))
-		t.Name = getString(names[1])
-	} else if len(names) == 1 {
-		t.Name = getString(names[0])
-	} else {
-		return nil, fmt.Errorf("a surprising amount of names (%d) in a type name", len(names))
+	name, err := db.QualifyTypeName(NewTypeName(rel.GetRelname(), rel.GetSchemaname()))
+	if err != nil {
+		return err
 	}
 
-	if len(typeName.GetArrayBounds()) > 0 {
-		t.Array = true
+	if userTypeExists(db, name) {
+		return fmt.Errorf(`type "%s" already exists`, name.String())
 	}
 
-	t.Name = strings.ToLower(t.Name)
-	if t.Schema != nil {
-		t.Schema = ptr.V(strings.ToLower(*t.Schema))
+	attributes, err := parseColumnDefList(db, stmt.GetColdeflist())
+	if err != nil {
+		return fmt.Errorf(`failed to parse the attributes of type "%s": %w`, name.String(), err)
 	}
 
-	return t, nil
+	attributes.Name = NewTableNamePtr(name.Name, name.Schema)
+	db.AddCompositeType(NewCompositeType(name, attributes))
+
+	return nil
 }
 
-func isNotNull(def *pg_query.ColumnDef) bool {
-	for _, c := range def.GetConstraints() {
-		switch c.GetConstraint().GetContype() {
-		case pg_query.ConstrType_CONSTR_NOTNULL, pg_query.ConstrType_CONSTR_PRIMARY:
-			return true
-		}
+func alterCompositeType(db *DB, stmt *pg_query.AlterTableStmt) error {
+	rel := stmt.GetRelation()
+	name := NewTypeName(rel.GetRelname(), rel.GetSchemaname())
 
+	c := db.FindCompositeType(name)
+	if c == nil {
+		return fmt.Errorf(`unknown composite type "%s"`, name.String())
 	}
 
-	return false
+	return alterTableCmds(db, c.Attributes, stmt.GetObjtype(), stmt.GetCmds(), false)
+}
+
+func renameAttribute(db *DB, stmt *pg_query.RenameStmt) error {
+	rel := stmt.GetRelation()
+	name := NewTypeName(rel.GetRelname(), rel.GetSchemaname())
+
+	c := db.FindCompositeType(name)
+	if c == nil {
+		return fmt.Errorf(`unknown composite type "%s"`, name.String())
+	}
+
+	if c.Attributes.ColumnsByName[stmt.GetSubname()] == nil {
+		return fmt.Errorf(`unknown attribute "%s" in type "%s"`, stmt.GetSubname(), name.String())
+	}
+
+	c.Attributes.RenameColumn(stmt.GetSubname(), stmt.GetNewname())
+	return nil
 }
 
-func dropTable(db *DB, stmt *pg_query.DropStmt) error {
+func dropType(db *DB, stmt *pg_query.DropStmt) error {
 	for _, o := range stmt.GetObjects() {
-		for _, i := range o.GetList().GetItems() {
-			tableName := getString(i)
+		name, err := parseTypeNameParts(o.GetTypeName().GetNames())
+		if err != nil {
+			return err
+		}
 
-			table := db.TablesByName[NewTableName(tableName)]
-			if table == nil {
-				return fmt.Errorf(`unknown table "%s"`, tableName)
+		qualifiedName, ok := resolveUserTypeName(db, name)
+		if !ok {
+			if stmt.GetMissingOk() {
+				continue
 			}
 
-			db.RemoveTable(*table.Name)
+			return fmt.Errorf(`unknown type "%s"`, name.String())
+		}
+
+		if err := dropUserType(db, qualifiedName, stmt.GetBehavior() == pg_query.DropBehavior_DROP_CASCADE); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func alterTable(db *DB, stmt *pg_query.AlterTableStmt) error {
-	rel := stmt.GetRelation()
-	if rel == nil {
-		return errors.New("no relation")
+// dropUserType drops the user defined type `name`. The columns, attributes, views
+// and domains that use the type are also dropped if `cascade` is true. Otherwise an error is
+// returned if there are any like postgres does.
+func dropUserType(db *DB, name TypeName, cascade bool) error {
+	if d := db.DomainsByName[name]; d != nil {
+		return removeDomain(db, d, cascade)
 	}
 
-	name := rel.GetRelname()
-	if len(name) == 0 {
-		return errors.New("empty table name")
+	if c := db.CompositeTypesByName[name]; c != nil {
+		if err := dropColumnsOfType(db, name, func(t *DataType) bool { return t.Composite == c }, cascade); err != nil {
+			return err
+		}
+
+		db.RemoveCompositeType(name)
+		return nil
 	}
 
-	table := db.TablesByName[NewTableName(name)]
-	if table == nil {
-		return fmt.Errorf(`table "%s" hasn't been created`, name)
+	enum := db.EnumsByName[name]
+	if enum == nil {
+		return fmt.Errorf(`unknown type "%s"`, name.String())
 	}
 
-	for _, cmd := range stmt.GetCmds() {
-		alter := cmd.GetAlterTableCmd()
+	for _, d := range slices.Clone(db.Domains) {
+		if d.Type.Enum != enum {
+			continue
+		}
 
-		switch alter.Subtype {
-		case pg_query.AlterTableType_AT_AddColumn:
-			if err := addColumn(table, alter.Def.GetColumnDef()); err != nil {
-				return fmt.Errorf("failed to add column: %w", err)
+		if !cascade {
+			return fmt.Errorf(`cannot drop type "%s" because domain "%s" depends on it`, name.String(), d.Name.String())
+		}
+
+		if err := removeDomain(db, d, cascade); err != nil {
+			return err
+		}
+	}
+
+	if err := dropColumnsOfType(db, name, func(t *DataType) bool { return t.Enum == enum }, cascade); err != nil {
+		return err
+	}
+
+	db.RemoveEnum(name)
+	return nil
+}
+
+func dropDomain(db *DB, stmt *pg_query.DropStmt) error {
+	for _, o := range stmt.GetObjects() {
+		name, err := parseTypeNameParts(o.GetTypeName().GetNames())
+		if err != nil {
+			return err
+		}
+
+		domain := db.FindDomain(name)
+		if domain == nil {
+			if stmt.GetMissingOk() {
+				continue
 			}
-		case pg_query.AlterTableType_AT_DropColumn:
-			if err := removeColumn(table, alter.GetName()); err != nil {
-				return fmt.Errorf("failed to drop column: %w", err)
+
+			return fmt.Errorf(`unknown domain "%s"`, name.String())
+		}
+
+		if err := removeDomain(db, domain, stmt.GetBehavior() == pg_query.DropBehavior_DROP_CASCADE); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeDomain(db *DB, domain *Domain, cascade bool) error {
+	if err := dropColumnsOfType(db, domain.Name, func(t *DataType) bool { return t.Domain == domain }, cascade); err != nil {
+		return err
+	}
+
+	db.RemoveDomain(domain.Name)
+	return nil
+}
+
+// dropColumnsOfType drops all table columns and composite type attributes of
+// the type `name` and the views that have columns of the type if `cascade` is
+// true. `uses` tells if a column type uses the type. If `cascade` is false, an
+// error is returned if anything uses the type like postgres does.
+func dropColumnsOfType(db *DB, name TypeName, uses func(*DataType) bool, cascade bool) error {
+	for _, t := range slices.Clone(db.Tables) {
+		if db.TablesByName[*t.Name] != t {
+			// Dropped with a view dropped earlier.
+			continue
+		}
+
+		for _, c := range slices.Clone(t.Columns) {
+			if !uses(&c.Type) {
+				continue
 			}
-		case pg_query.AlterTableType_AT_SetNotNull:
-			if err := setNotNull(table, alter.GetName()); err != nil {
-				return fmt.Errorf("failed to set column not null: %w", err)
+
+			if !cascade {
+				if t.IsView() {
+					return fmt.Errorf(`cannot drop type "%s" because %s "%s" depends on it`, name.String(), t.Kind, t.Name.String())
+				}
+
+				return fmt.Errorf(`cannot drop type "%s" because column "%s" of table "%s" depends on it`, name.String(), c.Name, t.Name.String())
+			}
+
+			if t.IsView() {
+				if err := dropTable(db, t, cascade); err != nil {
+					return err
+				}
+
+				break
 			}
-		case pg_query.AlterTableType_AT_DropNotNull:
-			if err := dropNotNull(table, alter.GetName()); err != nil {
-				return fmt.Errorf("failed to drop not null: %w", err)
+
+			t.RemoveColumn(c.Name)
+		}
+	}
+
+	for _, ct := range db.CompositeTypes {
+		for _, c := range slices.Clone(ct.Attributes.Columns) {
+			if !uses(&c.Type) {
+				continue
 			}
-		case pg_query.AlterTableType_AT_AlterColumnType:
-			if err := alterColumnType(table, alter.GetName(), alter.Def.GetColumnDef()); err != nil {
-				return fmt.Errorf("failed to alter column type: %w", err)
+
+			if !cascade {
+				return fmt.Errorf(`cannot drop type "%s" because attribute "%s" of type "%s" depends on it`, name.String(), c.Name, ct.Name.String())
 			}
+
+			ct.Attributes.RemoveColumn(c.Name)
 		}
 	}
 
 	return nil
 }
 
-func removeColumn(table *Table, colName string) error {
-	_, ok := table.ColumnsByName[colName]
-	if !ok {
-		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+func renameType(db *DB, stmt *pg_query.RenameStmt) error {
+	name, err := findUserTypeName(db, stmt.GetObject())
+	if err != nil {
+		return err
 	}
 
-	table.RemoveColumn(colName)
+	newName := NewTypeName(stmt.GetNewname(), name.Schema)
+	if userTypeExists(db, newName) {
+		return fmt.Errorf(`type "%s" already exists`, newName.String())
+	}
+
+	renameUserType(db, name, newName)
 	return nil
 }
 
-func setNotNull(table *Table, colName string) error {
-	col, ok := table.ColumnsByName[colName]
+func userTypeExists(db *DB, name TypeName) bool {
+	return db.EnumsByName[name] != nil || db.DomainsByName[name] != nil || db.CompositeTypesByName[name] != nil
+}
+
+// findUserTypeName finds the qualified name of the existing user defined type
+// referred to by a list of name parts.
+func findUserTypeName(db *DB, object *pg_query.Node) (TypeName, error) {
+	name, err := parseTypeNameParts(object.GetList().GetItems())
+	if err != nil {
+		return name, err
+	}
+
+	qualifiedName, ok := resolveUserTypeName(db, name)
 	if !ok {
-		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+		return name, fmt.Errorf(`unknown type "%s"`, name.String())
 	}
 
-	col.Type.NotNull = true
-	return nil
+	return qualifiedName, nil
 }
 
-func dropNotNull(table *Table, colName string) error {
-	col, ok := table.ColumnsByName[colName]
-	if !ok {
-		return fmt.Errorf(`could not find column "%s" in table "%s"`, colName, table.Name)
+// resolveUserTypeName returns the qualified name of the user defined type `name`
+// using the search path. The second return value is false if the type doesn't exist.
+func resolveUserTypeName(db *DB, name TypeName) (TypeName, bool) {
+	if e := db.FindEnum(name); e != nil {
+		return e.Name, true
+	} else if d := db.FindDomain(name); d != nil {
+		return d.Name, true
+	} else if c := db.FindCompositeType(name); c != nil {
+		return c.Name, true
 	}
 
-	col.Type.NotNull = false
-	return nil
+	return name, false
+}
+
+// renameUserType renames the user defined type `name`, which can also be
+// moved to another schema this way.
+func renameUserType(db *DB, name TypeName, newName TypeName) {
+	if db.EnumsByName[name] != nil {
+		db.RenameEnum(name, newName)
+	} else if db.DomainsByName[name] != nil {
+		db.RenameDomain(name, newName)
+	} else if db.CompositeTypesByName[name] != nil {
+		db.RenameCompositeType(name, newName)
+	}
 }
 
-func alterColumnType(table *Table, columnName string, def *pg_query.ColumnDef) error {
-	t, err := parseColumnType(def)
+// parseNewTypeNameParts parses the name of a new user defined type. Unqualified
+// names are placed in the creation schema of the search path.
+func parseNewTypeNameParts(db *DB, names []*pg_query.Node) (TypeName, error) {
+	name, err := parseTypeNameParts(names)
 	if err != nil {
-		return err
+		return name, err
 	}
 
-	col, ok := table.ColumnsByName[columnName]
-	if !ok {
-		return fmt.Errorf(`could not find column "%s" in table "%s"`, columnName, table.Name)
+	return db.QualifyTypeName(name)
+}
+
+// parseTypeNameParts parses a possibly schema qualified type name
+// from the name parts of the AST.
+func parseTypeNameParts(names []*pg_query.Node) (TypeName, error) {
+	switch len(names) {
+	case 1:
+		return NewTypeName(getString(names[0])), nil
+	case 2:
+		return NewTypeName(getString(names[1]), getString(names[0])), nil
 	}
 
-	col.Type.Name = strings.ToLower(t.Name)
-	return nil
+	return TypeName{}, fmt.Errorf("a surprising amount of names (%d) in a type name", len(names))
 }
 
-func rename(db *DB, stmt *pg_query.RenameStmt) error {
-	rel := stmt.GetRelation()
-	if rel == nil {
-		return errors.New("no relation")
+// createFunction adds a function created using `CREATE FUNCTION` to `db`. Only
+// the signature of the function is stored. Procedures can't be called in
+// queries and are ignored.
+func createFunction(db *DB, stmt *pg_query.CreateFunctionStmt) error {
+	if stmt.GetIsProcedure() {
+		return nil
 	}
 
-	tableName := rel.GetRelname()
-	if len(tableName) == 0 {
-		return errors.New("empty table name")
+	name, err := parseNewTypeNameParts(db, stmt.GetFuncname())
+	if err != nil {
+		return err
 	}
 
-	table := db.TablesByName[NewTableName(tableName)]
-	if table == nil {
-		return fmt.Errorf(`unknown table "%s"`, tableName)
+	f := NewFunction(name)
+	out := NewTable()
+
+	for _, p := range stmt.GetParameters() {
+		param := p.GetFunctionParameter()
+
+		t, err := parseTypeName(db, param.GetArgType())
+		if err != nil {
+			return fmt.Errorf(`failed to parse the arguments of function "%s": %w`, name.String(), err)
+		}
+
+		if t.Domain == nil {
+			// Postgres ignores the modifiers of argument and return types.
+			t.Modifiers = nil
+		}
+
+		arg := &Column{Name: param.GetName(), Type: *t}
+
+		switch param.GetMode() {
+		case pg_query.FunctionParameterMode_FUNC_PARAM_OUT, pg_query.FunctionParameterMode_FUNC_PARAM_TABLE:
+			out.AddColumn(arg)
+			continue
+		case pg_query.FunctionParameterMode_FUNC_PARAM_INOUT:
+			out.AddColumn(arg.Clone())
+		case pg_query.FunctionParameterMode_FUNC_PARAM_VARIADIC:
+			f.Variadic = true
+		}
+
+		if param.GetDefexpr() != nil {
+			if arg.Default, err = deparseExpr(param.GetDefexpr()); err != nil {
+				return fmt.Errorf(`failed to parse the arguments of function "%s": %w`, name.String(), err)
+			}
+		}
+
+		f.Args = append(f.Args, arg)
 	}
 
-	switch stmt.GetRenameType() {
-	case pg_query.ObjectType_OBJECT_COLUMN:
-		if col := table.ColumnsByName[stmt.GetSubname()]; col == nil {
-			return fmt.Errorf(`unknown column "%s" in table "%s"`, stmt.GetSubname(), tableName)
-		} else {
-			table.RenameColumn(stmt.GetSubname(), stmt.GetNewname())
+	if ret := stmt.GetReturnType(); ret != nil {
+		t, err := parseTypeName(db, ret)
+		if err != nil {
+			return fmt.Errorf(`failed to parse the return type of function "%s": %w`, name.String(), err)
 		}
-	case pg_query.ObjectType_OBJECT_TABLE:
-		db.RenameTable(*table.Name, NewTableName(stmt.GetNewname()))
-	default:
-		return fmt.Errorf("unknown rename type %s", stmt.GetRenameType().String())
+
+		f.Returns = *t
+		if t.Domain == nil {
+			f.Returns.Modifiers = nil
+		}
+
+		f.ReturnsSet = ret.GetSetof()
+	} else {
+		// Functions with `OUT` arguments don't need to declare a return type.
+		f.Returns = DataType{Name: DataTypeRecord}
+	}
+
+	if len(out.Columns) > 0 {
+		f.Returns.Record = out
+	} else if f.Returns.Record == nil && f.Returns.Enum == nil && f.Returns.Domain == nil {
+		table := db.FindTable(TableName(f.Returns.TypeName()))
+		if table != nil {
+			// Qualify the name of a table row type so that the table
+			// can be found regardless of the search path.
+			f.Returns.Schema = ptr.V(table.Name.Schema)
+		}
+	}
+
+	for _, existing := range db.FunctionsByName[name] {
+		if existing.HasSameArgs(f) && !stmt.GetReplace() {
+			return fmt.Errorf(`function "%s" already exists with same argument types`, name.String())
+		}
+	}
+
+	db.ReplaceFunction(f)
+	return nil
+}
+
+func dropFunctions(db *DB, stmt *pg_query.DropStmt) error {
+	for _, o := range stmt.GetObjects() {
+		f, err := findFunction(db, o.GetObjectWithArgs())
+		if err != nil {
+			if stmt.GetMissingOk() && errors.Is(err, errUnknownFunction) {
+				continue
+			}
+
+			return err
+		}
+
+		db.RemoveFunction(f)
 	}
 
 	return nil
 }
 
-func omitDownMigration(m string) (string, error) {
-	lines := make([]string, 0)
+var errUnknownFunction = errors.New("unknown function")
+
+// findFunction finds the function referred to in statements like `DROP FUNCTION`
+// and `ALTER FUNCTION`. The argument types can be left out if the function
+// isn't overloaded.
+func findFunction(db *DB, o *pg_query.ObjectWithArgs) (*Function, error) {
+	name, err := parseTypeNameParts(o.GetObjname())
+	if err != nil {
+		return nil, err
+	}
+
+	overloads := db.FindFunctions(name)
+
+	if o.GetArgsUnspecified() {
+		if len(overloads) == 0 {
+			return nil, fmt.Errorf(`%w "%s"`, errUnknownFunction, name.String())
+		} else if len(overloads) > 1 {
+			return nil, fmt.Errorf(`function name "%s" is not unique`, name.String())
+		}
+
+		return overloads[0], nil
+	}
+
+	signature := NewFunction(name)
+
+	for _, a := range o.GetObjargs() {
+		t, err := parseTypeName(db, a.GetTypeName())
+		if err != nil {
+			return nil, err
+		}
 
-	for _, l := range strings.Split(m, "\n") {
-		if isDownMigrationStartLine(l) {
-			break
+		signature.Args = append(signature.Args, &Column{Type: *t})
+	}
+
+	for _, f := range overloads {
+		if f.HasSameArgs(signature) {
+			return f, nil
 		}
+	}
+
+	return nil, fmt.Errorf(`%w "%s"`, errUnknownFunction, name.String())
+}
 
-		lines = append(lines, l)
+func renameFunction(db *DB, stmt *pg_query.RenameStmt) error {
+	f, err := findFunction(db, stmt.GetObject().GetObjectWithArgs())
+	if err != nil {
+		return err
 	}
 
-	return strings.Join(lines, "\n"), nil
+	return moveFunction(db, f, NewTypeName(stmt.GetNewname(), f.Name.Schema))
 }
 
-func isDownMigrationStartLine(l string) bool {
-	return strings.HasPrefix(l, "-- +goose Down")
+// moveFunction renames a function or moves it to another schema.
+func moveFunction(db *DB, f *Function, newName TypeName) error {
+	renamed := f.Clone()
+	renamed.Name = newName
+
+	for _, existing := range db.FunctionsByName[newName] {
+		if existing.HasSameArgs(renamed) {
+			return fmt.Errorf(`function "%s" already exists`, renamed.Signature())
+		}
+	}
+
+	db.RenameFunction(f, newName)
+	return nil
 }
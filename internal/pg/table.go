@@ -14,8 +14,43 @@ type Table struct {
 	Name          *TableName
 	Columns       []*Column
 	ColumnsByName map[string]*Column
+
+	// Kind tells what kind of a relation the table is. Empty for tables
+	// that don't exist in the database, like selections.
+	Kind TableKind
+
+	// DependsOn holds the names of the relations a view selects from.
+	DependsOn []TableName
+
+	// columnRefs holds the names of the columns a view references. Columns
+	// selected using `*` are included as they were when the view was created.
+	columnRefs []string
+
+	Constraints       []*Constraint
+	ConstraintsByName map[string]*Constraint
+
+	// Inherits holds the parents of a table created using `INHERITS`.
+	Inherits []TableName
+
+	// PartitionOf holds the partitioned parent table of a partition.
+	PartitionOf *TableName
+
+	// Partitioned is true for tables created using `PARTITION BY`.
+	Partitioned bool
+
+	// Comment holds the comment set using `COMMENT ON TABLE` or
+	// `COMMENT ON VIEW`.
+	Comment *string
 }
 
+type TableKind string
+
+const (
+	TableKindTable            TableKind = "table"
+	TableKindView             TableKind = "view"
+	TableKindMaterializedView TableKind = "materialized view"
+)
+
 type TableName struct {
 	Name string
 	// Schema holds the schema of the table (as in "public" or "my_custom_schema").
@@ -27,8 +62,10 @@ type TableName struct {
 
 func NewTable(name ...TableName) *Table {
 	t := &Table{
-		Columns:       make([]*Column, 0),
-		ColumnsByName: make(map[string]*Column),
+		Columns:           make([]*Column, 0),
+		ColumnsByName:     make(map[string]*Column),
+		Constraints:       make([]*Constraint, 0),
+		ConstraintsByName: make(map[string]*Constraint),
 	}
 
 	if len(name) > 0 {
@@ -42,14 +79,44 @@ func (t *Table) HasName() bool {
 	return t.Name != nil
 }
 
+// IsView returns true for both normal and materialized views.
+func (t *Table) IsView() bool {
+	return t.Kind == TableKindView || t.Kind == TableKindMaterializedView
+}
+
+// IsChildOf returns true if the table inherits from the table `name`
+// or is a partition of it.
+func (t *Table) IsChildOf(name TableName) bool {
+	return slices.Contains(t.Inherits, name) || (t.PartitionOf != nil && *t.PartitionOf == name)
+}
+
+// DependsOnTable returns true if the view selects from the relation `name`.
+func (t *Table) DependsOnTable(name TableName) bool {
+	return slices.Contains(t.DependsOn, name)
+}
+
+// DependsOnColumn returns true if the view may reference the column `column`
+// of the relation `table`. Like `Query.ReferencesColumn`, any reference to a
+// column with the same name counts.
+func (t *Table) DependsOnColumn(table TableName, column string) bool {
+	return t.DependsOnTable(table) && slices.Contains(t.columnRefs, column)
+}
+
 func (t *Table) AddColumn(col *Column) {
 	t.ColumnsByName[col.Name] = col
 	t.Columns = append(t.Columns, col)
 }
 
+// RemoveColumn removes a column and all constraints that involve it.
 func (t *Table) RemoveColumn(name string) {
 	delete(t.ColumnsByName, name)
 	t.Columns = slices.DeleteFunc(t.Columns, func(c *Column) bool { return c.Name == name })
+
+	for _, c := range slices.Clone(t.Constraints) {
+		if c.HasColumn(name) {
+			t.RemoveConstraint(c.Name)
+		}
+	}
 }
 
 func (t *Table) RenameColumn(name string, newName string) {
@@ -58,6 +125,57 @@ func (t *Table) RenameColumn(name string, newName string) {
 
 	c.Name = newName
 	t.ColumnsByName[newName] = c
+
+	for _, c := range t.Constraints {
+		c.RenameColumn(name, newName)
+	}
+}
+
+func (t *Table) AddConstraint(c *Constraint) {
+	t.ConstraintsByName[c.Name] = c
+	t.Constraints = append(t.Constraints, c)
+}
+
+func (t *Table) RemoveConstraint(name string) {
+	delete(t.ConstraintsByName, name)
+	t.Constraints = slices.DeleteFunc(t.Constraints, func(c *Constraint) bool { return c.Name == name })
+}
+
+func (t *Table) RenameConstraint(name string, newName string) {
+	c := t.ConstraintsByName[name]
+	delete(t.ConstraintsByName, name)
+
+	c.Name = newName
+	t.ConstraintsByName[newName] = c
+}
+
+// PrimaryKey returns the primary key constraint of the table or nil
+// if the table has no primary key.
+func (t *Table) PrimaryKey() *Constraint {
+	for _, c := range t.Constraints {
+		if c.Type == ConstraintTypePrimaryKey {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// IsUniqueKey returns true if the table has a primary key or a unique
+// constraint whose columns are all included in `columns`. At most one row
+// can then match given values for `columns`.
+func (t *Table) IsUniqueKey(columns []string) bool {
+	for _, c := range t.Constraints {
+		if c.Type != ConstraintTypePrimaryKey && c.Type != ConstraintTypeUnique {
+			continue
+		}
+
+		if !slices.ContainsFunc(c.Columns, func(col string) bool { return !slices.Contains(columns, col) }) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (t *Table) Clone() *Table {
@@ -67,13 +185,43 @@ func (t *Table) Clone() *Table {
 		clone.Name = t.Name.Clone()
 	}
 
+	clone.Kind = t.Kind
+	clone.DependsOn = slices.Clone(t.DependsOn)
+	clone.columnRefs = slices.Clone(t.columnRefs)
+	clone.Inherits = slices.Clone(t.Inherits)
+	clone.Partitioned = t.Partitioned
+
+	if t.PartitionOf != nil {
+		clone.PartitionOf = t.PartitionOf.Clone()
+	}
+
+	if t.Comment != nil {
+		clone.Comment = ptr.V(*t.Comment)
+	}
+
 	for _, c := range t.Columns {
 		clone.AddColumn(c.Clone())
 	}
 
+	for _, c := range t.Constraints {
+		clone.AddConstraint(c.Clone())
+	}
+
 	return clone
 }
 
+// ForEachDataType calls `f` for the data type of each column including
+// the columns of nested records.
+func (t *Table) ForEachDataType(f func(*DataType)) {
+	for _, c := range t.Columns {
+		f(&c.Type)
+
+		if c.Type.Record != nil {
+			c.Type.Record.ForEachDataType(f)
+		}
+	}
+}
+
 func (t *Table) writeString(s *stringBuilder, omitName bool) {
 	if t.Name != nil && !omitName {
 		t.Name.string(s)
@@ -87,7 +235,17 @@ func (t *Table) writeString(s *stringBuilder, omitName bool) {
 	for i, c := range t.Columns {
 		c.writeString(s)
 
-		if i != len(t.Columns)-1 {
+		if i != len(t.Columns)-1 || len(t.Constraints) > 0 {
+			s.WriteString(",")
+		}
+
+		s.WriteNewLine()
+	}
+
+	for i, c := range t.Constraints {
+		c.writeString(s)
+
+		if i != len(t.Constraints)-1 {
 			s.WriteString(",")
 		}
 
@@ -96,6 +254,25 @@ func (t *Table) writeString(s *stringBuilder, omitName bool) {
 
 	s.DeIndent()
 	s.WriteString(")")
+
+	if t.PartitionOf != nil {
+		s.WriteString(" PARTITION OF ")
+		t.PartitionOf.string(s)
+	}
+
+	if len(t.Inherits) > 0 {
+		s.WriteString(" INHERITS (")
+
+		for i, p := range t.Inherits {
+			p.string(s)
+
+			if i != len(t.Inherits)-1 {
+				s.WriteString(", ")
+			}
+		}
+
+		s.WriteString(")")
+	}
 }
 
 func (t *Table) String() string {
@@ -0,0 +1,252 @@
+package pg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+)
+
+// UnsupportedStatement is a migration statement, or a part of one, that was
+// ignored because norsu doesn't know how it affects the schema.
+type UnsupportedStatement struct {
+	// Tag describes the statement like `CREATE INDEX` or `ALTER TABLE OWNER TO`.
+	Tag string
+
+	// Line is the line of the migration the statement starts on.
+	Line int
+}
+
+// unsupportedError is returned when a statement is ignored. The functions
+// that apply statements return it only after applying all supported parts
+// of the statement.
+type unsupportedError struct {
+	tag string
+}
+
+func (err *unsupportedError) Error() string {
+	return fmt.Sprintf(`unsupported statement "%s"`, err.tag)
+}
+
+// errUnsupportedAlterTableCmd is returned by `alterTableCmd` for subcommands
+// it doesn't handle. The caller knows the object type needed for the tag.
+var errUnsupportedAlterTableCmd = errors.New("unsupported alter table command")
+
+// unsupportedTags returns the tags of the unsupported statements if `err`
+// consists of `unsupportedError`s only. Otherwise nil is returned.
+func unsupportedTags(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	if u, ok := err.(*unsupportedError); ok {
+		return []string{u.tag}
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		tags := make([]string, 0)
+
+		for _, err := range e.Unwrap() {
+			t := unsupportedTags(err)
+			if t == nil {
+				return nil
+			}
+
+			tags = append(tags, t...)
+		}
+
+		return tags
+	case interface{ Unwrap() error }:
+		return unsupportedTags(e.Unwrap())
+	}
+
+	return nil
+}
+
+// statementLine returns the line of the first token of the statement that
+// starts at the byte offset `loc`. Statement locations include the whitespace
+// and comments that precede the statement.
+func statementLine(sql string, loc int) int {
+	return resolveLine(sql, statementStart(sql, loc))
+}
+
+// statementStart returns the byte offset of the first token of the statement
+// that starts at the byte offset `loc`, skipping whitespace and comments.
+func statementStart(sql string, loc int) int {
+	for loc < len(sql) {
+		if unicode.IsSpace(rune(sql[loc])) {
+			loc++
+		} else if strings.HasPrefix(sql[loc:], "--") {
+			if i := strings.IndexByte(sql[loc:], '\n'); i != -1 {
+				loc += i
+			} else {
+				loc = len(sql)
+			}
+		} else if strings.HasPrefix(sql[loc:], "/*") {
+			if i := strings.Index(sql[loc:], "*/"); i != -1 {
+				loc += i + 2
+			} else {
+				loc = len(sql)
+			}
+		} else {
+			break
+		}
+	}
+
+	return loc
+}
+
+// statementTags holds the tags of statements whose tag can't be derived
+// from the name of the syntax tree node.
+var statementTags = map[string]string{
+	"IndexStmt":            "CREATE INDEX",
+	"CreateTrigStmt":       "CREATE TRIGGER",
+	"CreateEventTrigStmt":  "CREATE EVENT TRIGGER",
+	"AlterEventTrigStmt":   "ALTER EVENT TRIGGER",
+	"CreateSeqStmt":        "CREATE SEQUENCE",
+	"AlterSeqStmt":         "ALTER SEQUENCE",
+	"RuleStmt":             "CREATE RULE",
+	"CreateStatsStmt":      "CREATE STATISTICS",
+	"AlterStatsStmt":       "ALTER STATISTICS",
+	"SecLabelStmt":         "SECURITY LABEL",
+	"RefreshMatViewStmt":   "REFRESH MATERIALIZED VIEW",
+	"VariableShowStmt":     "SHOW",
+	"ConstraintsSetStmt":   "SET CONSTRAINTS",
+	"CreateTableSpaceStmt": "CREATE TABLESPACE",
+	"CreatePLangStmt":      "CREATE LANGUAGE",
+	"CreateAmStmt":         "CREATE ACCESS METHOD",
+	"CreateFdwStmt":        "CREATE FOREIGN DATA WRAPPER",
+	"CreateOpClassStmt":    "CREATE OPERATOR CLASS",
+	"CreateOpFamilyStmt":   "CREATE OPERATOR FAMILY",
+	"CreatedbStmt":         "CREATE DATABASE",
+	"DropdbStmt":           "DROP DATABASE",
+	"CheckPointStmt":       "CHECKPOINT",
+}
+
+// statementTag returns a tag like `CREATE TRIGGER` that describes the
+// statement `node` in reports of unsupported statements.
+func statementTag(node *pg_query.Node) string {
+	switch n := node.GetNode().(type) {
+	case *pg_query.Node_GrantStmt:
+		if n.GrantStmt.GetIsGrant() {
+			return "GRANT"
+		}
+
+		return "REVOKE"
+	case *pg_query.Node_GrantRoleStmt:
+		if n.GrantRoleStmt.GetIsGrant() {
+			return "GRANT ROLE"
+		}
+
+		return "REVOKE ROLE"
+	case *pg_query.Node_AlterOwnerStmt:
+		return "ALTER " + objectTypeName(n.AlterOwnerStmt.GetObjectType()) + " OWNER TO"
+	case *pg_query.Node_DefineStmt:
+		return "CREATE " + objectTypeName(n.DefineStmt.GetKind())
+	case *pg_query.Node_VacuumStmt:
+		if n.VacuumStmt.GetIsVacuumcmd() {
+			return "VACUUM"
+		}
+
+		return "ANALYZE"
+	}
+
+	m := node.ProtoReflect()
+	field := m.WhichOneof(m.Descriptor().Oneofs().ByName("node"))
+	if field == nil {
+		return "UNKNOWN"
+	}
+
+	name := string(field.Message().Name())
+	if tag, ok := statementTags[name]; ok {
+		return tag
+	}
+
+	return upperWords(strings.TrimSuffix(name, "Stmt"))
+}
+
+// alterTableCmdTags holds the tags of `ALTER TABLE` subcommands whose tag
+// can't be derived from the name of the subcommand type.
+var alterTableCmdTags = map[pg_query.AlterTableType]string{
+	pg_query.AlterTableType_AT_ChangeOwner:             "OWNER TO",
+	pg_query.AlterTableType_AT_EnableTrig:              "ENABLE TRIGGER",
+	pg_query.AlterTableType_AT_EnableAlwaysTrig:        "ENABLE ALWAYS TRIGGER",
+	pg_query.AlterTableType_AT_EnableReplicaTrig:       "ENABLE REPLICA TRIGGER",
+	pg_query.AlterTableType_AT_DisableTrig:             "DISABLE TRIGGER",
+	pg_query.AlterTableType_AT_EnableTrigAll:           "ENABLE TRIGGER ALL",
+	pg_query.AlterTableType_AT_DisableTrigAll:          "DISABLE TRIGGER ALL",
+	pg_query.AlterTableType_AT_EnableTrigUser:          "ENABLE TRIGGER USER",
+	pg_query.AlterTableType_AT_DisableTrigUser:         "DISABLE TRIGGER USER",
+	pg_query.AlterTableType_AT_EnableRowSecurity:       "ENABLE ROW LEVEL SECURITY",
+	pg_query.AlterTableType_AT_DisableRowSecurity:      "DISABLE ROW LEVEL SECURITY",
+	pg_query.AlterTableType_AT_ForceRowSecurity:        "FORCE ROW LEVEL SECURITY",
+	pg_query.AlterTableType_AT_NoForceRowSecurity:      "NO FORCE ROW LEVEL SECURITY",
+	pg_query.AlterTableType_AT_SetRelOptions:           "SET STORAGE PARAMETERS",
+	pg_query.AlterTableType_AT_ResetRelOptions:         "RESET STORAGE PARAMETERS",
+	pg_query.AlterTableType_AT_SetUnLogged:             "SET UNLOGGED",
+	pg_query.AlterTableType_AT_SetTableSpace:           "SET TABLESPACE",
+	pg_query.AlterTableType_AT_DropCluster:             "SET WITHOUT CLUSTER",
+	pg_query.AlterTableType_AT_AddIndexConstraint:      "ADD CONSTRAINT USING INDEX",
+	pg_query.AlterTableType_AT_AddOf:                   "OF",
+	pg_query.AlterTableType_AT_DropOf:                  "NOT OF",
+	pg_query.AlterTableType_AT_AddColumnToView:         "ADD COLUMN",
+	pg_query.AlterTableType_AT_DetachPartitionFinalize: "DETACH PARTITION FINALIZE",
+}
+
+// alterTableCmdTag returns a tag like `ALTER TABLE OWNER TO` that describes
+// an `ALTER` subcommand of the type `subtype`.
+func alterTableCmdTag(objtype pg_query.ObjectType, subtype pg_query.AlterTableType) string {
+	tag, ok := alterTableCmdTags[subtype]
+	if !ok {
+		tag = upperWords(strings.TrimPrefix(subtype.String(), "AT_"))
+	}
+
+	return "ALTER " + objectTypeName(objtype) + " " + tag
+}
+
+// objectTypeNames holds the SQL names of the object types whose name
+// can't be derived from the name of the enum value.
+var objectTypeNames = map[pg_query.ObjectType]string{
+	pg_query.ObjectType_OBJECT_MATVIEW:         "MATERIALIZED VIEW",
+	pg_query.ObjectType_OBJECT_TABCONSTRAINT:   "CONSTRAINT",
+	pg_query.ObjectType_OBJECT_DOMCONSTRAINT:   "CONSTRAINT",
+	pg_query.ObjectType_OBJECT_FDW:             "FOREIGN DATA WRAPPER",
+	pg_query.ObjectType_OBJECT_FOREIGN_SERVER:  "SERVER",
+	pg_query.ObjectType_OBJECT_LARGEOBJECT:     "LARGE OBJECT",
+	pg_query.ObjectType_OBJECT_OPCLASS:         "OPERATOR CLASS",
+	pg_query.ObjectType_OBJECT_OPFAMILY:        "OPERATOR FAMILY",
+	pg_query.ObjectType_OBJECT_STATISTIC_EXT:   "STATISTICS",
+	pg_query.ObjectType_OBJECT_TSCONFIGURATION: "TEXT SEARCH CONFIGURATION",
+	pg_query.ObjectType_OBJECT_TSDICTIONARY:    "TEXT SEARCH DICTIONARY",
+	pg_query.ObjectType_OBJECT_TSPARSER:        "TEXT SEARCH PARSER",
+	pg_query.ObjectType_OBJECT_TSTEMPLATE:      "TEXT SEARCH TEMPLATE",
+	pg_query.ObjectType_OBJECT_DEFACL:          "DEFAULT PRIVILEGES",
+}
+
+// objectTypeName returns the name of an object type as it's written in SQL.
+func objectTypeName(t pg_query.ObjectType) string {
+	if name, ok := objectTypeNames[t]; ok {
+		return name
+	}
+
+	return strings.ReplaceAll(strings.TrimPrefix(t.String(), "OBJECT_"), "_", " ")
+}
+
+// upperWords converts a camel case name like `CreateExtension` into upper
+// case words like `CREATE EXTENSION`.
+func upperWords(name string) string {
+	var s strings.Builder
+
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			s.WriteByte(' ')
+		}
+
+		s.WriteRune(unicode.ToUpper(r))
+	}
+
+	return s.String()
+}
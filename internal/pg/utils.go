@@ -6,6 +6,16 @@ func getString(node *pg_query.Node) string {
 	return node.GetString_().GetSval()
 }
 
+func getStrings(nodes []*pg_query.Node) []string {
+	strs := make([]string, 0, len(nodes))
+
+	for _, n := range nodes {
+		strs = append(strs, getString(n))
+	}
+
+	return strs
+}
+
 func resolveLine(sql string, pos int) int {
 	line := 1
 
@@ -0,0 +1,82 @@
+package pg
+
+import (
+	"slices"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// walkNodes calls `f` for each node of the syntax tree `root` in depth first
+// order. The children of a node are not visited if `f` returns false.
+func walkNodes(root proto.Message, f func(*pg_query.Node) bool) {
+	if root == nil {
+		return
+	}
+
+	walkMessage(root.ProtoReflect(), f)
+}
+
+func walkMessage(m protoreflect.Message, f func(*pg_query.Node) bool) {
+	if !m.IsValid() {
+		return
+	}
+
+	if node, ok := m.Interface().(*pg_query.Node); ok && !f(node) {
+		return
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+
+		if fd.IsList() {
+			for i := 0; i < v.List().Len(); i++ {
+				walkMessage(v.List().Get(i).Message(), f)
+			}
+		} else {
+			walkMessage(v.Message(), f)
+		}
+
+		return true
+	})
+}
+
+// hasStarRef returns true if the expression selects columns using `*`
+// or `table.*`.
+func hasStarRef(expr *pg_query.Node) bool {
+	found := false
+
+	walkNodes(expr, func(n *pg_query.Node) bool {
+		if ref := n.GetColumnRef(); ref != nil {
+			fields := ref.GetFields()
+			found = found || fields[len(fields)-1].GetAStar() != nil
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// columnRefNames returns the names of the columns referenced
+// in an expression in the order they appear.
+func columnRefNames(expr *pg_query.Node) []string {
+	names := make([]string, 0)
+
+	walkNodes(expr, func(n *pg_query.Node) bool {
+		if ref := n.GetColumnRef(); ref != nil {
+			fields := ref.GetFields()
+
+			if name := getString(fields[len(fields)-1]); len(name) > 0 && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+
+		return true
+	})
+
+	return names
+}
@@ -1,6 +1,9 @@
 package sqlio
 
-import "github.com/koskimas/norsu/test/fixtures/persons"
+import (
+	"github.com/koskimas/norsu/test/fixtures/persons"
+	"github.com/koskimas/norsu/test/fixtures/pets"
+)
 
 type Id struct {
 	Id string `json:"id"`
@@ -10,3 +13,12 @@ type PersonUpdate struct {
 	Id     string               `json:"id"`
 	Person persons.PersonUpdate `json:"person"`
 }
+
+type PetSpecies struct {
+	Species pets.Species `json:"species"`
+}
+
+type PetInsert struct {
+	OwnerId string   `json:"ownerId"`
+	Pet     pets.Pet `json:"pet"`
+}
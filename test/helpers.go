@@ -1,10 +1,14 @@
 package test
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/koskimas/norsu/internal/cmd"
+	"github.com/koskimas/norsu/internal/pg"
 	assert "github.com/stretchr/testify/require"
 )
 
@@ -13,3 +17,55 @@ func getWd(t *testing.T, folder string) string {
 	assert.NoError(t, err, "failed to get working directory")
 	return filepath.Join(wd, folder)
 }
+
+// assertSchema asserts that the schema built from the migrations of a test
+// is written as the DDL in the test's `expected/schema.sql`.
+func assertSchema(t *testing.T, settings cmd.Settings) {
+	var out bytes.Buffer
+	assert.NoError(t, cmd.Schema(settings, cmd.SchemaFormatDDL, &out))
+
+	expected, err := os.ReadFile(filepath.Join(settings.WorkingDir, "expected/schema.sql"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
+
+// migrate applies the migrations `sql` to an empty database.
+func migrate(t *testing.T, sql ...string) *pg.DB {
+	db := pg.NewDB()
+
+	for _, s := range sql {
+		_, err := pg.ParseMigration(db, s)
+		assert.NoError(t, err)
+	}
+
+	return db
+}
+
+// columns returns the columns of the table or view `name` of the public
+// schema written as strings like `id int4 not null`. Built-in types are
+// written without the `pg_catalog` schema.
+func columns(t *testing.T, db *pg.DB, name string) []string {
+	table, ok := db.TablesByName[pg.NewTableName(name, pg.DefaultSchema)]
+	assert.True(t, ok, "unknown table %s", name)
+
+	cols := make([]string, 0, len(table.Columns))
+	for _, c := range table.Columns {
+		cols = append(cols, strings.ReplaceAll(c.String(), "pg_catalog.", ""))
+	}
+
+	return cols
+}
+
+// constraints returns the constraints of the table `name` of the public
+// schema written as they are in the schema DDL.
+func constraints(t *testing.T, db *pg.DB, name string) []string {
+	table, ok := db.TablesByName[pg.NewTableName(name, pg.DefaultSchema)]
+	assert.True(t, ok, "unknown table %s", name)
+
+	cons := make([]string, 0, len(table.Constraints))
+	for _, c := range table.Constraints {
+		cons = append(cons, c.String())
+	}
+
+	return cons
+}
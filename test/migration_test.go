@@ -0,0 +1,814 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/koskimas/norsu/internal/migration"
+	"github.com/koskimas/norsu/internal/pg"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestIfExistsErrors(t *testing.T) {
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE TABLE t (b int);", `relation "public.t" already exists`},
+		{"ALTER TABLE t ADD COLUMN a text;", `column "a" already exists`},
+		{"ALTER TABLE t DROP COLUMN b;", `could not find column "b" in table "public.t"`},
+		{"ALTER TABLE missing ADD COLUMN a text;", `unknown table "missing"`},
+		{"ALTER TABLE missing RENAME TO x;", `unknown table "missing"`},
+		{"DROP TABLE missing;", `unknown table "missing"`},
+		{"DROP VIEW missing;", `unknown view "missing"`},
+		{"DROP TYPE missing;", `unknown type "missing"`},
+	}
+
+	for _, test := range tests {
+		db := migrate(t, "CREATE TABLE t (a int);")
+		_, err := pg.ParseMigration(db, test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	// The same statements are skipped with IF EXISTS and IF NOT EXISTS.
+	db := migrate(t, "CREATE TABLE t (a int);", `
+		CREATE TABLE IF NOT EXISTS t (b int);
+		ALTER TABLE t ADD COLUMN IF NOT EXISTS a text;
+		ALTER TABLE t DROP COLUMN IF EXISTS b;
+		ALTER TABLE IF EXISTS missing ADD COLUMN a text;
+		ALTER TABLE IF EXISTS missing RENAME TO x;
+		DROP TABLE IF EXISTS missing;
+		DROP VIEW IF EXISTS missing;
+		DROP TYPE IF EXISTS missing;
+	`)
+
+	assert.Equal(t, []string{"a int4"}, columns(t, db, "t"))
+}
+
+func TestLintWarnings(t *testing.T) {
+	db := migrate(t, "CREATE TABLE accounts (id text PRIMARY KEY, label varchar(32), balance numeric);")
+
+	r, err := pg.ParseMigration(db, `CREATE TABLE drafts (id text PRIMARY KEY, label text);
+ALTER TABLE drafts RENAME TO notes;
+ALTER TABLE notes ALTER COLUMN label SET NOT NULL;
+CREATE INDEX notes_label_idx ON notes (label);
+
+ALTER TABLE accounts ALTER COLUMN label SET NOT NULL;
+ALTER TABLE accounts ADD COLUMN currency char(3) NOT NULL;
+ALTER TABLE accounts ALTER COLUMN label TYPE varchar(64);
+ALTER TABLE accounts ALTER COLUMN balance TYPE numeric(12, 2);
+CREATE INDEX accounts_label_idx ON accounts (label);
+ALTER TABLE accounts RENAME COLUMN label TO name;
+
+-- norsu:ignore set-not-null
+ALTER TABLE accounts
+  ALTER COLUMN balance SET NOT NULL,
+  ALTER COLUMN balance TYPE numeric(14, 2);
+
+-- norsu:ignore
+ALTER TABLE accounts ADD COLUMN opened_at timestamptz NOT NULL;
+`)
+	assert.NoError(t, err)
+
+	warnings := make([]string, 0, len(r.Warnings))
+	for _, w := range r.Warnings {
+		warnings = append(warnings, w.String())
+	}
+
+	assert.Equal(t, []string{
+		`line 6: setting column "label" of table "public.accounts" NOT NULL scans the table while holding an exclusive lock (set-not-null)`,
+		`line 7: adding column "currency" to table "public.accounts" as NOT NULL without a default fails if the table has rows (add-column-not-null)`,
+		`line 9: changing the type of column "balance" of table "public.accounts" rewrites the table while holding an exclusive lock (alter-column-type)`,
+		`line 10: creating an index on table "public.accounts" without CONCURRENTLY blocks writes to the table (create-index-non-concurrently)`,
+		`line 11: renaming column "label" of table "public.accounts" to "name" breaks queries that still use the old name (rename-column)`,
+		`line 14: changing the type of column "balance" of table "public.accounts" rewrites the table while holding an exclusive lock (alter-column-type)`,
+	}, warnings)
+}
+
+func TestEnumLabels(t *testing.T) {
+	db := migrate(t, `
+		CREATE TYPE mood AS ENUM ('happy', 'sad');
+		CREATE TABLE people (name text NOT NULL, mood mood NOT NULL, moods mood[]);
+
+		ALTER TYPE mood ADD VALUE 'angry' BEFORE 'sad';
+		ALTER TYPE mood ADD VALUE 'calm';
+		ALTER TYPE mood ADD VALUE IF NOT EXISTS 'calm';
+		ALTER TYPE mood RENAME VALUE 'sad' TO 'blue';
+	`)
+
+	assert.Equal(t, []string{"happy", "angry", "blue", "calm"}, db.EnumsByName[pg.NewTypeName("mood", pg.DefaultSchema)].Labels)
+	assert.Equal(t, []string{"name text not null", "mood mood not null", "moods mood[]"}, columns(t, db, "people"))
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE TYPE mood AS ENUM ('x');", `type "public.mood" already exists`},
+		{"ALTER TYPE missing ADD VALUE 'x';", `unknown enum type "missing"`},
+		{"ALTER TYPE mood ADD VALUE 'happy';", `enum label "happy" already exists in type "mood"`},
+		{"ALTER TYPE mood ADD VALUE 'x' AFTER 'missing';", `"missing" is not an existing enum label of type "mood"`},
+		{"ALTER TYPE mood RENAME VALUE 'missing' TO 'x';", `"missing" is not an existing enum label of type "mood"`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestViewColumns(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE people (id int PRIMARY KEY, name text NOT NULL, nickname text, age int);
+
+		CREATE VIEW adults AS SELECT id, name FROM people WHERE age >= 18;
+		CREATE OR REPLACE VIEW adults AS SELECT id, name, nickname FROM people WHERE age >= 18;
+		ALTER VIEW adults RENAME TO grown_ups;
+
+		CREATE MATERIALIZED VIEW names (person_id, person_name) AS SELECT id, name FROM people WITH NO DATA;
+		CREATE VIEW everyone AS SELECT * FROM people;
+	`)
+
+	assert.Equal(t, []string{"id int4 not null", "name text not null", "nickname text"}, columns(t, db, "grown_ups"))
+	assert.Equal(t, []string{"person_id int4 not null", "person_name text not null"}, columns(t, db, "names"))
+	assert.Equal(t, []string{"id int4 not null", "name text not null", "nickname text", "age int4"}, columns(t, db, "everyone"))
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE VIEW names AS SELECT 1 AS one;", `relation "public.names" already exists`},
+		{"CREATE OR REPLACE VIEW names AS SELECT 1 AS one;", `relation "public.names" already exists`},
+		{"CREATE VIEW v AS SELECT missing FROM people;", `failed to analyze the query of view "public.v"`},
+		{"DROP VIEW people;", `"people" is not a view`},
+		{"DROP VIEW names;", `"names" is not a view`},
+		{"DROP TABLE people;", `cannot drop table "public.people" because view "public.grown_ups" depends on it`},
+		{"ALTER TABLE people DROP COLUMN nickname;", `cannot drop column "nickname" of table "public.people" because view "public.grown_ups" depends on it`},
+		{"ALTER TABLE people DROP COLUMN age;", `cannot drop column "age" of table "public.people" because view "public.grown_ups" depends on it`},
+		{"ALTER TABLE people ADD COLUMN email text; CREATE VIEW v AS SELECT * FROM people; ALTER TABLE people DROP COLUMN email;", `cannot drop column "email" of table "public.people" because view "public.v" depends on it`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	// Columns the views don't use can be dropped and CASCADE drops the views
+	// that use the column.
+	db = migrate(t, `
+		CREATE TABLE people (id int PRIMARY KEY, name text NOT NULL, nickname text, age int);
+		CREATE VIEW names AS SELECT id, name AS full_name FROM people;
+		CREATE VIEW nicknames AS SELECT id, nickname FROM people;
+		CREATE VIEW nickname_counts AS SELECT count(*) AS n FROM nicknames;
+
+		ALTER TABLE people ADD COLUMN email text;
+		ALTER TABLE people DROP COLUMN email;
+		ALTER TABLE people RENAME COLUMN name TO first_name;
+		ALTER TABLE people DROP COLUMN nickname CASCADE;
+	`)
+
+	assert.Equal(t, []string{"id int4 not null", "full_name text not null"}, columns(t, db, "names"))
+	assert.Nil(t, db.TablesByName[pg.NewTableName("nicknames", pg.DefaultSchema)])
+	assert.Nil(t, db.TablesByName[pg.NewTableName("nickname_counts", pg.DefaultSchema)])
+
+	_, err := pg.ParseMigration(db, "ALTER TABLE people DROP COLUMN first_name;")
+	assert.ErrorContains(t, err, `cannot drop column "first_name" of table "public.people" because view "public.names" depends on it`)
+}
+
+func TestDomainTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TYPE mood AS ENUM ('happy', 'sad');
+		CREATE DOMAIN positive_int AS int NOT NULL CHECK (VALUE > 0);
+		CREATE DOMAIN feeling AS mood;
+		CREATE DOMAIN tags AS text[];
+
+		CREATE TABLE people (
+		  age positive_int,
+		  ages positive_int[],
+		  required_ages positive_int[] NOT NULL,
+		  feeling feeling,
+		  tags tags,
+		  tag_lists tags[]
+		);
+	`)
+
+	assert.Equal(t, []string{
+		"age public.positive_int not null",
+		"ages public.positive_int[]",
+		"required_ages public.positive_int[] not null",
+		"feeling public.feeling",
+		"tags public.tags",
+		"tag_lists public.tags[]",
+	}, columns(t, db, "people"))
+
+	people := db.TablesByName[pg.NewTableName("people", pg.DefaultSchema)]
+	assert.Equal(t, "int4", people.ColumnsByName["ages"].Type.Name)
+	assert.NotNil(t, people.ColumnsByName["feeling"].Type.Enum)
+	assert.Equal(t, 2, people.ColumnsByName["tag_lists"].Type.ArrayDims)
+
+	// Values of the domain must be given in an insert but arrays of it can be left out.
+	_, err := pg.ParseQuery(db, "-- :name Insert\nINSERT INTO people (age) VALUES (1)")
+	assert.ErrorContains(t, err, `column "required_ages" of table "public.people" is not null`)
+
+	_, err = pg.ParseQuery(db, "-- :name Insert\nINSERT INTO people (required_ages) VALUES ('{1}')")
+	assert.ErrorContains(t, err, `column "age" of table "public.people" is not null`)
+}
+
+func TestDropTypeCascade(t *testing.T) {
+	schema := `
+		CREATE TYPE mood AS ENUM ('happy', 'sad');
+		CREATE DOMAIN positive_int AS int NOT NULL;
+		CREATE TYPE visit AS (mood mood, guests positive_int, note text);
+
+		CREATE TABLE people (id int, mood mood, age positive_int, last_visit visit);
+		CREATE VIEW moods AS SELECT id, mood FROM people;
+		CREATE VIEW ages AS SELECT id, age FROM people;
+		CREATE VIEW age_counts AS SELECT count(*) AS n FROM ages;
+	`
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"DROP TYPE mood;", `cannot drop type "public.mood" because column "mood" of table "public.people" depends on it`},
+		{"ALTER TABLE people DROP COLUMN mood CASCADE; DROP TYPE mood;", `cannot drop type "public.mood" because attribute "mood" of type "public.visit" depends on it`},
+		{"ALTER TABLE people DROP COLUMN age CASCADE; DROP DOMAIN positive_int;", `cannot drop type "public.positive_int" because attribute "guests" of type "public.visit" depends on it`},
+		{"DROP VIEW moods; ALTER TABLE people DROP COLUMN mood; ALTER TYPE visit DROP ATTRIBUTE mood; CREATE VIEW happy AS SELECT 'happy'::mood AS mood; DROP TYPE mood;", `cannot drop type "public.mood" because view "public.happy" depends on it`},
+		{"DROP TYPE visit;", `cannot drop type "public.visit" because column "last_visit" of table "public.people" depends on it`},
+	}
+
+	for _, test := range tests {
+		db := migrate(t, schema)
+		_, err := pg.ParseMigration(db, test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	// CASCADE drops the columns and attributes of the type and the views that
+	// have columns of the type.
+	db := migrate(t, schema, "DROP TYPE mood CASCADE; DROP DOMAIN positive_int CASCADE;")
+
+	assert.Equal(t, []string{"id int4", "last_visit visit"}, columns(t, db, "people"))
+	assert.Equal(t, "public.visit (\n  note text\n)", db.CompositeTypesByName[pg.NewTypeName("visit", pg.DefaultSchema)].String())
+	assert.Nil(t, db.TablesByName[pg.NewTableName("moods", pg.DefaultSchema)])
+	assert.Nil(t, db.TablesByName[pg.NewTableName("ages", pg.DefaultSchema)])
+	assert.Nil(t, db.TablesByName[pg.NewTableName("age_counts", pg.DefaultSchema)])
+}
+
+func TestCompositeTypeAttributes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TYPE address AS (street text, city text);
+		CREATE TABLE people (home address NOT NULL, addresses address[]);
+
+		ALTER TYPE address ADD ATTRIBUTE zip varchar(5);
+		ALTER TYPE address RENAME ATTRIBUTE city TO town;
+		ALTER TYPE address DROP ATTRIBUTE street;
+		ALTER TYPE address ALTER ATTRIBUTE zip TYPE varchar(10);
+	`)
+
+	people := db.TablesByName[pg.NewTableName("people", pg.DefaultSchema)]
+	home := people.ColumnsByName["home"].Type
+	addresses := people.ColumnsByName["addresses"].Type
+
+	// The columns share the attributes of the type.
+	assert.Equal(t, []string{"home address not null", "addresses address[]"}, columns(t, db, "people"))
+	assert.Equal(t, "public.address (\n  town text,\n  zip pg_catalog.varchar(10)\n)", home.Composite.String())
+	assert.Same(t, home.Composite.Attributes, home.Record)
+	assert.False(t, home.RecordArray)
+	assert.Same(t, home.Composite.Attributes, addresses.Record)
+	assert.True(t, addresses.RecordArray)
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE TYPE address AS (x int);", `type "public.address" already exists`},
+		{"ALTER TYPE missing ADD ATTRIBUTE x int;", `unknown composite type "missing"`},
+		{"ALTER TYPE address ADD ATTRIBUTE town text;", `column "town" already exists`},
+		{"ALTER TYPE address RENAME ATTRIBUTE missing TO x;", `unknown attribute "missing" in type "address"`},
+		{"ALTER TYPE address DROP ATTRIBUTE missing;", `could not find column "missing"`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestSchemaSearchPath(t *testing.T) {
+	db := migrate(t, `
+		CREATE SCHEMA shop;
+		CREATE TYPE shop.status AS ENUM ('new', 'done');
+		CREATE TABLE shop.orders (id int PRIMARY KEY, status shop.status NOT NULL);
+
+		SET search_path TO shop, public;
+		CREATE TABLE items (order_id int REFERENCES orders (id), status status);
+		CREATE TABLE public.notes (body text);
+	`)
+
+	// The search path of a migration doesn't leak to the next ones.
+	assert.Equal(t, []string{pg.DefaultSchema}, db.SearchPath)
+	assert.Equal(t, []string{"public", "shop"}, db.Schemas)
+
+	items := db.TablesByName[pg.NewTableName("items", "shop")]
+	assert.NotNil(t, items)
+	assert.Equal(t, "shop.orders", items.Constraints[0].References.String())
+	assert.Equal(t, "shop.status", items.ColumnsByName["status"].Type.Enum.Name.String())
+	assert.Equal(t, []string{"body text"}, columns(t, db, "notes"))
+
+	q, err := pg.ParseQuery(db, "-- :name Find :out Model\nSELECT o.id, o.status FROM shop.orders o")
+	assert.NoError(t, err)
+	assert.Equal(t, "(\n  id pg_catalog.int4 not null,\n  status shop.status not null\n)", q.Out.Table.String())
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE SCHEMA shop;", `schema "shop" already exists`},
+		{"CREATE TABLE missing.t (a int);", `schema "missing" does not exist`},
+		{"DROP SCHEMA shop;", `cannot drop schema "shop" because table "shop.orders" depends on it`},
+		{"DROP TABLE shop.items, shop.orders; DROP SCHEMA shop;", `cannot drop schema "shop" because type "shop.status" depends on it`},
+		{"SET search_path TO missing; CREATE TABLE t (a int);", `no schema has been selected to create in`},
+		{"ALTER TABLE shop.orders SET SCHEMA missing;", `schema "missing" does not exist`},
+		{"CREATE TABLE shop.items (a int); ", `relation "shop.items" already exists`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	// Unqualified names are looked up from the search path.
+	_, err = pg.ParseQuery(db, "-- :name Find\nSELECT id FROM orders")
+	assert.ErrorContains(t, err, `could not find table "orders"`)
+
+	db.SearchPath = []string{"shop"}
+	_, err = pg.ParseQuery(db, "-- :name Find\nSELECT id FROM orders")
+	assert.NoError(t, err)
+
+	db = migrate(t, "CREATE SCHEMA shop; CREATE TABLE shop.orders (id int); ALTER SCHEMA shop RENAME TO store; DROP SCHEMA store CASCADE;")
+	assert.Equal(t, []string{pg.DefaultSchema}, db.Schemas)
+	assert.Empty(t, db.Tables)
+}
+
+func TestTableConstraints(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE pets (id text PRIMARY KEY, name text UNIQUE);
+		CREATE TABLE pet_friends (
+		  pet_id text REFERENCES pets,
+		  friend_id text,
+		  since date CHECK (since > '2000-01-01'),
+		  until date,
+		  PRIMARY KEY (pet_id, friend_id),
+		  FOREIGN KEY (friend_id) REFERENCES pets (id),
+		  CHECK (since < until),
+		  UNIQUE (friend_id, pet_id)
+		);
+
+		ALTER TABLE pet_friends DROP CONSTRAINT pet_friends_since_check;
+		ALTER TABLE pet_friends RENAME CONSTRAINT pet_friends_friend_id_pet_id_key TO pet_friends_unique;
+		ALTER TABLE pet_friends ADD CONSTRAINT pet_friends_not_self CHECK (pet_id <> friend_id);
+		ALTER TABLE pet_friends ADD UNIQUE (since);
+		ALTER TABLE pet_friends DROP COLUMN until;
+		ALTER TABLE pets RENAME COLUMN id TO pet_id;
+	`)
+
+	assert.Equal(t, []string{
+		"CONSTRAINT pets_pkey PRIMARY KEY (pet_id)",
+		"CONSTRAINT pets_name_key UNIQUE (name)",
+	}, constraints(t, db, "pets"))
+
+	assert.Equal(t, []string{
+		"CONSTRAINT pet_friends_pet_id_fkey FOREIGN KEY (pet_id) REFERENCES public.pets (pet_id)",
+		"CONSTRAINT pet_friends_pkey PRIMARY KEY (pet_id, friend_id)",
+		"CONSTRAINT pet_friends_friend_id_fkey FOREIGN KEY (friend_id) REFERENCES public.pets (pet_id)",
+		"CONSTRAINT pet_friends_unique UNIQUE (friend_id, pet_id)",
+		"CONSTRAINT pet_friends_not_self CHECK (pet_id <> friend_id)",
+		"CONSTRAINT pet_friends_since_key UNIQUE (since)",
+	}, constraints(t, db, "pet_friends"))
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"ALTER TABLE pet_friends ADD CONSTRAINT pet_friends_unique UNIQUE (pet_id);", `constraint "pet_friends_unique" for relation "public.pet_friends" already exists`},
+		{"ALTER TABLE pet_friends ADD PRIMARY KEY (since);", `multiple primary keys for table "public.pet_friends" are not allowed`},
+		{"ALTER TABLE pet_friends ADD UNIQUE (missing);", `column "missing" named in unique constraint does not exist`},
+		{"ALTER TABLE pet_friends ADD FOREIGN KEY (since) REFERENCES missing;", `foreign key references unknown table "missing"`},
+		{"ALTER TABLE pet_friends ADD FOREIGN KEY (since) REFERENCES pets (missing);", `column "missing" referenced in foreign key constraint does not exist`},
+		{"ALTER TABLE pet_friends ADD FOREIGN KEY (since) REFERENCES pets (pet_id, name);", "number of referencing and referenced columns for foreign key disagree"},
+		{"CREATE TABLE t (a text REFERENCES pet_friends (missing));", `column "missing" referenced in foreign key constraint does not exist`},
+		{"ALTER TABLE pet_friends DROP CONSTRAINT missing;", `constraint "missing" of relation "public.pet_friends" does not exist`},
+		{"ALTER TABLE pet_friends RENAME CONSTRAINT missing TO x;", `constraint "missing" for table "public.pet_friends" does not exist`},
+		{"ALTER TABLE pet_friends RENAME CONSTRAINT pet_friends_pkey TO pet_friends_unique;", `constraint "pet_friends_unique" for relation "public.pet_friends" already exists`},
+		{"DROP TABLE pets;", `cannot drop table "public.pets" because constraint "pet_friends_pet_id_fkey" on table "public.pet_friends" depends on it`},
+		{"ALTER TABLE pets DROP COLUMN pet_id;", `cannot drop column "pet_id" of table "public.pets" because constraint "pet_friends_pet_id_fkey" on table "public.pet_friends" depends on it`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	// CASCADE drops the foreign keys that reference the dropped table.
+	_, err := pg.ParseMigration(db, "DROP TABLE pets CASCADE;")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"CONSTRAINT pet_friends_pkey PRIMARY KEY (pet_id, friend_id)",
+		"CONSTRAINT pet_friends_unique UNIQUE (friend_id, pet_id)",
+		"CONSTRAINT pet_friends_not_self CHECK (pet_id <> friend_id)",
+		"CONSTRAINT pet_friends_since_key UNIQUE (since)",
+	}, constraints(t, db, "pet_friends"))
+}
+
+func TestMigrationFiles(t *testing.T) {
+	// read writes the migration files `files` into a new directory and
+	// reads them using `format`.
+	read := func(format migration.Format, files map[string]string) ([]*migration.Migration, error) {
+		dir := t.TempDir()
+		paths := make([]string, 0, len(files))
+
+		for name, sql := range files {
+			p := filepath.Join(dir, name)
+			assert.NoError(t, os.WriteFile(p, []byte(sql), 0o644))
+			paths = append(paths, p)
+		}
+
+		return migration.Read(format, paths)
+	}
+
+	tests := []struct {
+		format migration.Format
+		files  map[string]string
+		order  []string
+		up     string
+		down   string
+	}{
+		{
+			migration.FormatGoose,
+			map[string]string{
+				"10_b.sql": "-- +goose Up\nB;",
+				"2_a.sql":  "-- +goose Up\n-- +goose StatementBegin\nA; A;\n-- +goose StatementEnd\n-- +goose Down\nDROP A;",
+			},
+			[]string{"2", "10"},
+			"\n\nA; A;\n\n\n",
+			"\n\n\n\n\nDROP A;",
+		},
+		{
+			migration.FormatSqlMigrate,
+			map[string]string{
+				"10_b.sql":    "-- +migrate Up\nB;",
+				"2_a.sql":     "-- +migrate Down\nDROP A;\n-- +migrate Up\nA;",
+				"initial.sql": "-- +migrate Up\nI;",
+			},
+			[]string{"2", "10", ""},
+			"\n\n\nA;",
+			"\nDROP A;\n\n",
+		},
+		{
+			migration.FormatDbmate,
+			map[string]string{
+				"20240102000000_b.sql": "-- migrate:up\nB;",
+				"20240101000000_a.sql": "-- migrate:up\nA;\n-- migrate:down\nDROP A;",
+			},
+			[]string{"20240101000000", "20240102000000"},
+			"\nA;\n\n",
+			"\n\n\nDROP A;",
+		},
+		{
+			migration.FormatGolangMigrate,
+			map[string]string{
+				"10_b.up.sql":  "B;",
+				"2_a.up.sql":   "A;",
+				"2_a.down.sql": "DROP A;",
+			},
+			[]string{"2", "10"},
+			"A;",
+			"DROP A;",
+		},
+		{
+			migration.FormatFlyway,
+			map[string]string{
+				"R__view.sql":  "R;",
+				"V1_10__c.sql": "C;",
+				"V1_2__b.sql":  "B;",
+				"V1__a.sql":    "A;",
+				"U1__a.sql":    "DROP A;",
+			},
+			[]string{"1", "1.2", "1.10", ""},
+			"A;",
+			"DROP A;",
+		},
+	}
+
+	for _, test := range tests {
+		migrations, err := read(test.format, test.files)
+		assert.NoError(t, err, test.format)
+
+		order := make([]string, 0, len(migrations))
+		for _, m := range migrations {
+			order = append(order, m.Version)
+		}
+
+		assert.Equal(t, test.order, order, test.format)
+		assert.Equal(t, test.up, migrations[0].Up, test.format)
+		assert.Equal(t, test.down, migrations[0].Down, test.format)
+		assert.False(t, migrations[1].HasDown(), test.format)
+	}
+
+	errors := []struct {
+		format migration.Format
+		files  map[string]string
+		err    string
+	}{
+		{"liquibase", map[string]string{"1_a.sql": ""}, `unknown migration format "liquibase"`},
+		{migration.FormatGoose, map[string]string{"a.sql": "-- +goose Up"}, `a.sql" doesn't start with a version`},
+		{migration.FormatGoose, map[string]string{"1_a.sql": "A;"}, `missing "-- +goose Up" annotation`},
+		{migration.FormatGoose, map[string]string{"1_a.sql": "-- +goose Up\n-- +goose StatementBegin\nA;"}, `missing "-- +goose StatementEnd" annotation`},
+		{migration.FormatGoose, map[string]string{"1_a.sql": "-- +goose Up\n-- +goose StatementEnd"}, `line 2: "-- +goose StatementEnd" without "-- +goose StatementBegin"`},
+		{migration.FormatGoose, map[string]string{"1_a.sql": "-- +goose Up", "01_b.sql": "-- +goose Up"}, `have the same version`},
+		{migration.FormatDbmate, map[string]string{"1_a.sql": "-- migrate:down"}, `missing "-- migrate:up" annotation`},
+		{migration.FormatGolangMigrate, map[string]string{"1_a.sql": ""}, `is not named like {version}_{title}.up.sql or {version}_{title}.down.sql`},
+		{migration.FormatGolangMigrate, map[string]string{"1_a.down.sql": ""}, `down migration file`},
+		{migration.FormatFlyway, map[string]string{"1__a.sql": ""}, `is not named like V{version}__{description}.sql`},
+		{migration.FormatFlyway, map[string]string{"R1__a.sql": ""}, `can't have a version`},
+		{migration.FormatFlyway, map[string]string{"V1__a.sql": "", "U2__a.sql": ""}, `has no versioned migration`},
+	}
+
+	for _, test := range errors {
+		_, err := read(test.format, test.files)
+		assert.ErrorContains(t, err, test.err, test.files)
+	}
+}
+
+func TestSchemaDumps(t *testing.T) {
+	db := pg.NewDB()
+
+	_, err := pg.ParseSchemaDump(db, `
+\restrict abc
+
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE SCHEMA accounting;
+ALTER SCHEMA accounting OWNER TO postgres;
+
+CREATE TABLE public.accounts (
+    id integer NOT NULL,
+    name text NOT NULL
+);
+
+ALTER TABLE public.accounts OWNER TO postgres;
+
+CREATE TABLE accounting.ledger_entries (
+    id text NOT NULL,
+    account_id integer NOT NULL
+);
+
+CREATE VIEW public.account_names AS
+ SELECT accounts.name
+   FROM public.accounts;
+
+ALTER VIEW public.account_names OWNER TO postgres;
+
+ALTER TABLE ONLY public.accounts ALTER COLUMN id SET DEFAULT nextval('public.accounts_id_seq'::regclass);
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT accounts_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY accounting.ledger_entries
+    ADD CONSTRAINT ledger_entries_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(id);
+
+\unrestrict abc
+`)
+	assert.NoError(t, err)
+
+	// The search path pg_dump sets only lasts until the end of the dump.
+	assert.Equal(t, []string{"public"}, db.SearchPath)
+	assert.Equal(t, []string{"id int4 not null DEFAULT nextval('public.accounts_id_seq'::regclass)", "name text not null"}, columns(t, db, "accounts"))
+	assert.Equal(t, []string{"CONSTRAINT accounts_pkey PRIMARY KEY (id)"}, constraints(t, db, "accounts"))
+	assert.Equal(t, []string{"name text not null"}, columns(t, db, "account_names"))
+
+	ledger := db.TablesByName[pg.NewTableName("ledger_entries", "accounting")]
+	assert.Equal(t, "CONSTRAINT ledger_entries_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts (id)", ledger.Constraints[0].String())
+
+	// Migrations are applied on top of the dump.
+	_, err = pg.ParseMigration(db, "SET search_path = accounting, public; ALTER TABLE ledger_entries ADD COLUMN amount numeric;")
+	assert.NoError(t, err)
+	assert.Equal(t, "amount pg_catalog.numeric", ledger.Columns[2].String())
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"SELECT pg_catalog.set_config(name, '', false) FROM settings;", "unsupported set_config arguments"},
+		{"SELECT pg_catalog.set_config('search_path', '', false); CREATE VIEW v AS SELECT id FROM public.accounts;", "no schema has been selected to create in"},
+		{"SELECT pg_catalog.set_config('search_path', '', false); CREATE VIEW public.v AS SELECT id FROM accounts;", `failed to analyze the query of view "public.v"`},
+		{"ALTER TABLE ONLY public.missing ADD CONSTRAINT missing_pkey PRIMARY KEY (id);", `unknown table "public.missing"`},
+		{"ALTER TABLE ONLY public.accounts ADD CONSTRAINT accounts_pkey UNIQUE (id);", `constraint "accounts_pkey" for relation "public.accounts" already exists`},
+		{"CREATE TABLE public.accounts (id integer);", `relation "public.accounts" already exists`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseSchemaDump(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestCreateTableAsColumns(t *testing.T) {
+	db := migrate(t, `
+		CREATE DOMAIN email AS text NOT NULL;
+		CREATE TABLE people (id int PRIMARY KEY, name text NOT NULL, email email, age int DEFAULT 0);
+
+		CREATE TABLE archive (person_id, person_name) AS SELECT id, name FROM people WHERE age > 100;
+		CREATE TABLE IF NOT EXISTS archive AS SELECT id FROM people;
+		CREATE TABLE emails AS SELECT email, age FROM people;
+		SELECT id, name || '!' AS shout INTO shouts FROM people;
+		CREATE MATERIALIZED VIEW names (person_name) AS SELECT name FROM people WITH NO DATA;
+	`)
+
+	// The columns of tables don't keep the not null constraints and defaults
+	// of the query except for the not null constraints of domains.
+	assert.Equal(t, []string{"person_id int4", "person_name text"}, columns(t, db, "archive"))
+	assert.Equal(t, []string{"email public.email not null", "age int4"}, columns(t, db, "emails"))
+	assert.Equal(t, []string{"id int4", "shout text"}, columns(t, db, "shouts"))
+	assert.Equal(t, []string{"person_name text not null"}, columns(t, db, "names"))
+
+	// Tables created from a query don't depend on the tables of the query.
+	_, err := pg.ParseMigration(db.Clone(), "ALTER TABLE people DROP COLUMN age; ALTER TABLE people RENAME COLUMN name TO full_name;")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE TABLE archive AS SELECT id FROM people;", `relation "public.archive" already exists`},
+		{"SELECT id INTO archive FROM people;", `relation "public.archive" already exists`},
+		{"CREATE TABLE t AS SELECT missing FROM people;", `failed to analyze the query of table "public.t"`},
+		{"CREATE TABLE t AS SELECT id FROM missing;", `failed to analyze the query of table "public.t"`},
+		{"CREATE TABLE t (a, b, c) AS SELECT id, name FROM people;", "more column names than columns specified"},
+		{"CREATE MATERIALIZED VIEW names AS SELECT name FROM people WITH NO DATA;", `relation "public.names" already exists`},
+		{"ALTER TABLE people DROP COLUMN name;", `cannot drop column "name" of table "public.people" because materialized view "public.names" depends on it`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestInheritedColumns(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE events (
+		  id text NOT NULL,
+		  happened_at date NOT NULL,
+		  PRIMARY KEY (id, happened_at)
+		) PARTITION BY RANGE (happened_at);
+
+		CREATE TABLE events_2025 PARTITION OF events FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');
+		CREATE TABLE events_2026 PARTITION OF events (happened_at DEFAULT '2026-01-01') FOR VALUES FROM ('2026-01-01') TO ('2027-01-01');
+		CREATE TABLE events_2027 (id text NOT NULL, happened_at date NOT NULL);
+
+		ALTER TABLE events ATTACH PARTITION events_2027 FOR VALUES FROM ('2027-01-01') TO ('2028-01-01');
+		ALTER TABLE events DETACH PARTITION events_2025;
+		ALTER TABLE events ADD COLUMN kind text;
+
+		CREATE TABLE notes (id text PRIMARY KEY, body text NOT NULL);
+		CREATE TABLE pet_notes (pet_id text NOT NULL, body text) INHERITS (notes);
+		ALTER TABLE notes ADD COLUMN author text;
+		ALTER TABLE notes RENAME COLUMN author TO written_by;
+	`)
+
+	assert.Equal(t, []string{"id text not null", "happened_at date not null"}, columns(t, db, "events_2025"))
+	assert.Equal(t, []string{"id text not null", "happened_at date not null DEFAULT '2026-01-01'", "kind text"}, columns(t, db, "events_2026"))
+	assert.Equal(t, []string{"id text not null", "happened_at date not null", "kind text"}, columns(t, db, "events_2027"))
+	assert.Equal(t, []string{"CONSTRAINT events_2027_pkey PRIMARY KEY (id, happened_at)"}, constraints(t, db, "events_2027"))
+
+	// Columns of the child with the same name as the columns of the parent
+	// are merged and keep the position of the parent's column.
+	assert.Equal(t, []string{"id text not null", "body text not null", "pet_id text not null", "written_by text"}, columns(t, db, "pet_notes"))
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE TABLE t PARTITION OF notes FOR VALUES IN ('a');", `table "public.notes" is not partitioned`},
+		{"CREATE TABLE t () INHERITS (events);", `cannot inherit from partitioned table "public.events"`},
+		{"CREATE TABLE t (body int) INHERITS (notes);", `column "body" has a type conflict`},
+		{"CREATE TABLE t (body int); CREATE TABLE c () INHERITS (notes, t);", `inherited column "body" has a type conflict`},
+		{"CREATE VIEW v AS SELECT id FROM notes; CREATE TABLE t () INHERITS (v);", `inherited relation "public.v" is not a table`},
+		{"ALTER TABLE events ATTACH PARTITION events_2026 FOR VALUES FROM ('2029-01-01') TO ('2030-01-01');", `"public.events_2026" is already a child table`},
+		{"ALTER TABLE events ATTACH PARTITION events_2025 FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');", `child table "public.events_2025" is missing column "kind"`},
+		{"ALTER TABLE notes ATTACH PARTITION pet_notes FOR VALUES IN ('a');", `table "public.notes" is not partitioned`},
+		{"ALTER TABLE events DETACH PARTITION events_2025;", `relation "public.events_2025" is not a partition of relation "public.events"`},
+		{"ALTER TABLE pet_notes INHERIT notes;", `relation "public.notes" would be inherited from more than once`},
+		{"ALTER TABLE events_2025 NO INHERIT notes;", `relation "public.notes" is not a parent of relation "public.events_2025"`},
+		{"DROP TABLE notes;", `cannot drop table "public.notes" because table "public.pet_notes" inherits from it`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	// Partitions are dropped with the partitioned table and CASCADE drops
+	// the inheritance children.
+	_, err := pg.ParseMigration(db, "DROP TABLE events; DROP TABLE notes CASCADE;")
+	assert.NoError(t, err)
+
+	assert.Nil(t, db.TablesByName[pg.NewTableName("events_2026", pg.DefaultSchema)])
+	assert.Nil(t, db.TablesByName[pg.NewTableName("pet_notes", pg.DefaultSchema)])
+	assert.NotNil(t, db.TablesByName[pg.NewTableName("events_2025", pg.DefaultSchema)])
+}
+
+func TestFunctionSignatures(t *testing.T) {
+	db := migrate(t, `
+		CREATE FUNCTION current_tenant() RETURNS text AS $$ SELECT 'a' $$ LANGUAGE sql;
+		CREATE FUNCTION search(q text) RETURNS SETOF text AS $$ SELECT q $$ LANGUAGE sql;
+		CREATE FUNCTION search(q text, n int DEFAULT 10) RETURNS TABLE (id text) AS $$ SELECT q $$ LANGUAGE sql;
+		CREATE FUNCTION search(q varchar(10)[]) RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql;
+		CREATE OR REPLACE FUNCTION current_tenant() RETURNS text AS $$ SELECT 'b' $$ LANGUAGE sql;
+		CREATE PROCEDURE cleanup() AS $$ SELECT 1 $$ LANGUAGE sql;
+
+		DROP FUNCTION search(varchar[]);
+		ALTER FUNCTION current_tenant RENAME TO tenant;
+	`)
+
+	signatures := make([]string, 0, len(db.Functions))
+	for _, f := range db.Functions {
+		signatures = append(signatures, f.Signature())
+	}
+
+	assert.Equal(t, []string{"public.search(text)", "public.search(text, pg_catalog.int4)", "public.tenant()"}, signatures)
+
+	search := db.FunctionsByName[pg.NewTypeName("search", pg.DefaultSchema)]
+	assert.True(t, search[0].ReturnsSet)
+	assert.Equal(t, "text", search[0].Returns.String())
+	assert.Equal(t, 1, search[1].RequiredArgs())
+	assert.Equal(t, "id text", search[1].Returns.Record.Columns[0].String())
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"CREATE FUNCTION tenant() RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql;", `function "public.tenant" already exists with same argument types`},
+		{"ALTER FUNCTION missing() RENAME TO f;", `unknown function "missing"`},
+		{"DROP FUNCTION missing;", `unknown function "missing"`},
+		{"DROP FUNCTION search;", `function name "search" is not unique`},
+		{"DROP FUNCTION search(int);", `unknown function "search"`},
+		{"ALTER FUNCTION search(text) RENAME TO search;", `function "public.search(text)" already exists`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+
+	_, err := pg.ParseMigration(db, "DROP FUNCTION IF EXISTS missing; DROP FUNCTION search(text, int), tenant();")
+	assert.NoError(t, err)
+	assert.Len(t, db.Functions, 1)
+}
+
+func TestUnsupportedStatements(t *testing.T) {
+	db := migrate(t, "CREATE TABLE accounts (id text PRIMARY KEY, name text NOT NULL);")
+
+	r, err := pg.ParseMigration(db, `
+CREATE INDEX accounts_name_idx ON accounts (name);
+DROP INDEX accounts_name_idx;
+CREATE SEQUENCE accounts_seq;
+ALTER SEQUENCE accounts_seq OWNED BY accounts.id;
+ALTER TABLE accounts OWNER TO postgres;
+ALTER SCHEMA public OWNER TO postgres;
+GRANT SELECT ON accounts TO PUBLIC;
+CREATE TRIGGER accounts_audit AFTER INSERT ON accounts
+  FOR EACH ROW EXECUTE FUNCTION audit();
+ALTER TABLE accounts
+  SET UNLOGGED,
+  ADD COLUMN closed_at timestamptz;
+COMMENT ON TABLE accounts IS 'Accounts';
+BEGIN;
+COMMIT;
+`)
+	assert.NoError(t, err)
+
+	unsupported := make([]string, 0, len(r.Unsupported))
+	for _, u := range r.Unsupported {
+		unsupported = append(unsupported, fmt.Sprintf("%d: %s", u.Line, u.Tag))
+	}
+
+	assert.Equal(t, []string{
+		"2: CREATE INDEX",
+		"3: DROP INDEX",
+		"4: CREATE SEQUENCE",
+		"5: ALTER SEQUENCE",
+		"6: ALTER TABLE OWNER TO",
+		"7: ALTER SCHEMA OWNER TO",
+		"8: GRANT",
+		"9: CREATE TRIGGER",
+		"11: ALTER TABLE SET UNLOGGED",
+	}, unsupported)
+
+	// The supported parts of a statement are applied.
+	assert.Equal(t, []string{"id text not null", "name text not null", "closed_at timestamptz"}, columns(t, db, "accounts"))
+}
@@ -1,16 +1,207 @@
 package test
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/koskimas/norsu/internal/cmd"
 	assert "github.com/stretchr/testify/require"
 )
 
-func TestNorsu(t *testing.T) {
-	err := cmd.Run(cmd.Settings{
-		WorkingDir: getWd(t, "tests/00001_simple"),
-	})
+// failingTests are the tests under `tests` whose run is expected to fail.
+// Their errors are asserted by their own tests.
+var failingTests = []string{
+	"00019_lint",
+	"00028_strict_unsupported",
+}
+
+// TestTests runs each test under `tests` and compares the schema built from
+// its migrations to its `expected/schema.sql` if it has one.
+func TestTests(t *testing.T) {
+	dirs, err := filepath.Glob(getWd(t, "tests/*"))
+	assert.NoError(t, err)
+
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			settings := cmd.Settings{
+				WorkingDir: dir,
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "expected/schema.sql")); err == nil {
+				assertSchema(t, settings)
+			}
+
+			if !slices.Contains(failingTests, filepath.Base(dir)) {
+				assert.NoError(t, cmd.Run(settings))
+			}
+		})
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00007_defaults"),
+	}
+
+	assert.NoError(t, cmd.Run(settings))
+
+	code, err := os.ReadFile(filepath.Join(settings.WorkingDir, "pkg/queries/queries.go"))
+	assert.NoError(t, err)
+
+	// The input model leaves out the status and creation time of the note
+	// and the defaults of the columns are inserted instead.
+	assert.Contains(t, string(code), ") VALUES (\n  $1,\n  $2,\n  DEFAULT,\n  DEFAULT\n)")
+	assert.Contains(t, string(code), "rows, err := q.DB.Query(ctx, insertDraftSql, in.Id, in.Person.FirstName)")
+}
+
+func TestStrictUnsupported(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00028_strict_unsupported"),
+	}
+
+	path := filepath.Join(settings.WorkingDir, "migrations/00001_accounts.sql")
+
+	assert.EqualError(t, cmd.Run(settings), fmt.Sprintf(
+		"found 2 unsupported migration statements (add the statements that don't affect types to strict.allow in norsu.yaml):\n"+
+			"  %s:12: CREATE TRIGGER\n"+
+			"  %s:15: ALTER TABLE SET UNLOGGED",
+		path,
+		path,
+	))
+}
+
+func TestDownMigrations(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00015_down_migrations"),
+	}
+
+	assert.NoError(t, cmd.Check(settings))
+}
+
+func TestUnrevertedDownMigrations(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00030_unreverted_down_migrations"),
+	}
+
+	changes := filepath.Join(settings.WorkingDir, "migrations/00002_account_changes.sql")
+	rename := filepath.Join(settings.WorkingDir, "migrations/00003_rename_account_name.sql")
+
+	// The up migrations still build a valid schema.
+	assert.NoError(t, cmd.Run(settings))
+
+	assert.EqualError(t, cmd.Check(settings), fmt.Sprintf(
+		"found 3 problems in down migrations:\n"+
+			`  %s: column "public.accounts.balance" is not dropped`+"\n"+
+			`  %s: enum "public.account_kind" is not dropped`+"\n"+
+			`  %s: failed to apply the down migration: failed to parse a rename statement: unknown column "label" in table "public.accounts"`,
+		changes,
+		changes,
+		rename,
+	))
+}
+
+func TestTypeModifiers(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00016_type_modifiers"),
+	}
+
+	assert.NoError(t, cmd.Check(settings))
+}
+
+func TestSchema(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00017_schema"),
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, cmd.Schema(settings, cmd.SchemaFormatJSON, &out))
+
+	expected, err := os.ReadFile(filepath.Join(settings.WorkingDir, "expected/schema.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
+
+func TestDiff(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00018_diff"),
+	}
 
+	before := settings
+	before.ExcludedMigrations = []string{
+		filepath.Join(settings.WorkingDir, "migrations/00002_order_changes.sql"),
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, cmd.Diff(before, settings, &out))
+
+	expected, err := os.ReadFile(filepath.Join(settings.WorkingDir, "expected/diff.txt"))
 	assert.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
+
+func TestLint(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00019_lint"),
+	}
+
+	// The statements of the migrations are either safe, suppressed or turned
+	// off, except for the renamed column that a query still uses.
+	assert.EqualError(t, cmd.Run(settings), fmt.Sprintf(
+		"found 1 unsafe migration statements (add a `-- norsu:ignore <rule>` comment before the statements that are safe or turn the rules off in lint.rules in norsu.yaml):\n"+
+			`  %s:7: renaming column "label" of table "public.accounts" to "name" breaks queries that still use the old name (rename-column), used by FindAccountLabel`,
+		filepath.Join(settings.WorkingDir, "migrations/00002_account_changes.sql"),
+	))
+
+	assert.NoError(t, cmd.Check(settings))
+}
+
+func TestComments(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00020_comments"),
+	}
+
+	assert.NoError(t, cmd.Run(settings))
+
+	code, err := os.ReadFile(filepath.Join(settings.WorkingDir, "pkg/queries/queries.go"))
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(code), `// FindAccount uses the tables:
+//   - public.accounts: Customer accounts.
+//   - public.persons
+//
+// The output columns are:
+//   - name: Display name of the account.
+//     Shown on invoices.
+//   - amount: Balance in euros. Can't be negative.
+func (q *QueriesImpl) FindAccount(`)
+
+	assert.NotContains(t, string(code), "FindPerson uses")
+}
+
+func TestQueryErrorResults(t *testing.T) {
+	settings := cmd.Settings{
+		WorkingDir: getWd(t, "tests/00027_output_only_query"),
+	}
+
+	assert.NoError(t, cmd.Run(settings))
+
+	code, err := os.ReadFile(filepath.Join(settings.WorkingDir, "pkg/queries/queries.go"))
+	assert.NoError(t, err)
+
+	// The return values depend on the output of the query, not on the input.
+	assert.Contains(t, string(code), `func (q *QueriesImpl) FindPersonIds(ctx context.Context) ([]sqlio.Id, error) {
+	rows, err := q.DB.Query(ctx, findPersonIdsSql)
+	if err != nil {
+		return nil, err
+	}`)
+
+	assert.Contains(t, string(code), `func (q *QueriesImpl) DeletePerson(ctx context.Context, in sqlio.Id) error {
+	rows, err := q.DB.Query(ctx, deletePersonSql, in.Id)
+	if err != nil {
+		return err
+	}`)
 }
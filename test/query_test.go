@@ -0,0 +1,308 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/koskimas/norsu/internal/match"
+	"github.com/koskimas/norsu/internal/model"
+	"github.com/koskimas/norsu/internal/pg"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestWriteColumns(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE notes (
+		  id int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+		  title text NOT NULL,
+		  body text NOT NULL DEFAULT '',
+		  slug text NOT NULL GENERATED ALWAYS AS (lower(title)) STORED
+		);
+
+		CREATE VIEW titles AS SELECT id, title FROM notes;
+	`)
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"INSERT INTO notes (title) VALUES ('a')", ""},
+		{"INSERT INTO notes (title, slug) VALUES ('a', DEFAULT)", ""},
+		{"INSERT INTO notes (title) VALUES ('a') ON CONFLICT (id) DO UPDATE SET title = 'b'", ""},
+		{"INSERT INTO titles (title) VALUES ('a')", ""},
+		{"UPDATE notes SET title = 'b', slug = DEFAULT", ""},
+		{"INSERT INTO notes (body) VALUES ('a')", `column "title" of table "public.notes" is not null and has no default but no value is inserted to it`},
+		{"INSERT INTO notes (title, slug) VALUES ('a', 'b')", `cannot insert a value into generated column "slug" of table "public.notes"`},
+		{"INSERT INTO notes (id, title) VALUES (1, 'a')", `cannot insert a value into generated column "id" of table "public.notes"`},
+		{"INSERT INTO notes (title) VALUES ('a') ON CONFLICT (id) DO UPDATE SET slug = 'b'", `column "slug" of table "public.notes" can only be updated to default`},
+		{"UPDATE notes SET slug = 'b'", `column "slug" of table "public.notes" can only be updated to default`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseQuery(db, "-- :name Write\n"+test.sql)
+
+		if test.err == "" {
+			assert.NoError(t, err, test.sql)
+		} else {
+			assert.ErrorContains(t, err, test.err, test.sql)
+		}
+	}
+}
+
+func TestOptionalInputs(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE notes (
+		  id serial PRIMARY KEY,
+		  seq int GENERATED BY DEFAULT AS IDENTITY,
+		  title text NOT NULL,
+		  body text NOT NULL DEFAULT ''
+		);
+	`)
+
+	schema := func(props ...string) model.Schema {
+		s := model.Schema{Type: model.TypeObject, Properties: map[string]*model.Schema{}}
+		for _, p := range props {
+			s.Properties[p] = &model.Schema{Type: model.TypeString}
+		}
+
+		return s
+	}
+
+	tests := []struct {
+		sql    string
+		schema model.Schema
+		out    string
+		err    string
+	}{
+		{
+			"INSERT INTO notes (id, seq, title, body) VALUES (:id, :seq, :title, :body)",
+			schema("id", "seq", "title", "body"),
+			"INSERT INTO notes (id, seq, title, body) VALUES ($4, $3, $2, $1)",
+			"",
+		},
+		{
+			"INSERT INTO notes (id, seq, title, body) VALUES (:id, :seq, :title, :body)",
+			schema("title"),
+			"INSERT INTO notes (id, seq, title, body) VALUES (DEFAULT, DEFAULT, $1, DEFAULT)",
+			"",
+		},
+		{
+			"INSERT INTO notes (title, body) VALUES (:title, :body) RETURNING id",
+			schema("title"),
+			"INSERT INTO notes (title, body) VALUES ($1, DEFAULT) RETURNING id",
+			"",
+		},
+		{
+			"INSERT INTO notes (id, title, body) VALUES (:id, :title, :body)",
+			schema("body", "title"),
+			"INSERT INTO notes (id, title, body) VALUES (DEFAULT, $2, $1)",
+			"",
+		},
+		{
+			"INSERT INTO notes (title, body) VALUES (:title, :body)",
+			schema("body"),
+			"",
+			`query inputs: failed to resolve reference "title": could not resolve property "title"`,
+		},
+		{
+			"INSERT INTO notes (title, body) VALUES (:title, :body) ON CONFLICT (id) DO UPDATE SET body = :body",
+			schema("title"),
+			"",
+			`query inputs: failed to resolve reference "body": could not resolve property "body"`,
+		},
+		{
+			"INSERT INTO notes (title, body) VALUES (:title, lower(:body))",
+			schema("title"),
+			"",
+			`query inputs: failed to resolve reference "body": could not resolve property "body"`,
+		},
+	}
+
+	for _, test := range tests {
+		header := "-- :name Insert :in Model\n"
+
+		q, err := pg.ParseQuery(db, header+test.sql)
+		assert.NoError(t, err, test.sql)
+
+		err = match.Input(*q.In, test.schema)
+		if test.err != "" {
+			assert.ErrorContains(t, err, test.err, test.sql)
+			continue
+		}
+
+		assert.NoError(t, err, test.sql)
+		assert.NoError(t, q.OmitInputs(match.OmittedInputs(*q.In, test.schema)), test.sql)
+		assert.Equal(t, header+test.out, q.SQL)
+
+		for i, in := range q.In.Inputs {
+			assert.Equal(t, i+1, in.PlaceholderIndex, test.sql)
+		}
+	}
+}
+
+func TestEnumModels(t *testing.T) {
+	db := migrate(t, "CREATE TYPE mood AS ENUM ('happy', 'sad'); CREATE TABLE people (name text NOT NULL, mood mood NOT NULL, moods mood[]);")
+
+	schema := func(prop string, s *model.Schema) model.Schema {
+		return model.Schema{
+			Type:       model.TypeObject,
+			Properties: map[string]*model.Schema{prop: s},
+			Required:   map[string]bool{prop: true},
+		}
+	}
+
+	moods := func(labels ...string) *model.Schema {
+		return &model.Schema{Type: model.TypeString, Enum: labels}
+	}
+
+	outputs := []struct {
+		sql    string
+		schema model.Schema
+		err    string
+	}{
+		{"SELECT mood FROM people", schema("mood", moods("sad", "happy")), ""},
+		{"SELECT mood FROM people", schema("mood", moods("happy", "sad", "angry")), ""},
+		{"SELECT mood FROM people", schema("mood", moods()), ""},
+		{"SELECT moods FROM people", schema("moods", &model.Schema{Type: model.TypeArray, Items: moods("happy", "sad")}), ""},
+		{"SELECT mood FROM people", schema("mood", moods("happy")), `enum label "sad" of type "public.mood" is not a valid value for output property Mood`},
+		{"SELECT mood FROM people", schema("mood", &model.Schema{Type: model.TypeInt}), `invalid type "mood not null" for a non-string property Mood`},
+		{"SELECT moods FROM people", schema("moods", moods("happy", "sad")), `invalid type "mood[]" for a non-array property Moods`},
+	}
+
+	for _, test := range outputs {
+		q, err := pg.ParseQuery(db, "-- :name Find :out Model\n"+test.sql)
+		assert.NoError(t, err, test.sql)
+
+		err = match.Output(*q.Out, test.schema)
+		if test.err == "" {
+			assert.NoError(t, err, test.sql)
+		} else {
+			assert.EqualError(t, err, test.err, test.sql)
+		}
+	}
+
+	inputs := []struct {
+		schema model.Schema
+		err    string
+	}{
+		{schema("mood", moods("happy")), ""},
+		{schema("mood", moods("happy", "sad")), ""},
+		{schema("mood", moods("happy", "angry")), `query inputs: value "angry" of input property Mood is not a label of enum type "public.mood"`},
+		{schema("mood", moods()), `query inputs: input property Mood allows values that are not labels of enum type "public.mood"`},
+		{schema("mood", &model.Schema{Type: model.TypeBool}), `query inputs: invalid type "mood" for a non-string property Mood`},
+	}
+
+	for _, test := range inputs {
+		q, err := pg.ParseQuery(db, "-- :name Find :in Model\nSELECT name FROM people WHERE mood = :mood::mood")
+		assert.NoError(t, err)
+
+		err = match.Input(*q.In, test.schema)
+		if test.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, test.err)
+		}
+	}
+}
+
+func TestCompositeCasts(t *testing.T) {
+	db := migrate(t, `
+		CREATE TYPE address AS (street text, city text);
+		CREATE TYPE point AS (x int, y int);
+		CREATE TABLE people (home address NOT NULL, spot point, places address[]);
+	`)
+
+	address := model.Schema{
+		Type: model.TypeObject,
+		Properties: map[string]*model.Schema{
+			"street": {Type: model.TypeString},
+			"city":   {Type: model.TypeString},
+		},
+	}
+
+	addressWithZip := model.Schema{
+		Type: model.TypeObject,
+		Properties: map[string]*model.Schema{
+			"street": {Type: model.TypeString},
+			"zip":    {Type: model.TypeString},
+		},
+	}
+
+	tests := []struct {
+		sql    string
+		schema model.Schema
+		err    string
+	}{
+		{"SELECT spot::text::address AS a FROM people", address, ""},
+		{"SELECT ROW('Main St', 'Springfield')::address AS a", address, ""},
+		{"SELECT (home)::address AS a FROM people", address, ""},
+		{"SELECT places::address[] AS a FROM people", model.Schema{Type: model.TypeArray, Items: &address}, ""},
+		{"SELECT spot::text::address AS a FROM people", addressWithZip, `selection missing for output property A.Zip`},
+		{"SELECT home::text AS a FROM people", address, `invalid selection type "text not null" for an object output property A`},
+		{"SELECT places::address[] AS a FROM people", address, `array selected for object output property A`},
+	}
+
+	for _, test := range tests {
+		q, err := pg.ParseQuery(db, "-- :name Find :out Model\n"+test.sql)
+		assert.NoError(t, err, test.sql)
+
+		schema := model.Schema{
+			Type:       model.TypeObject,
+			Properties: map[string]*model.Schema{"a": &test.schema},
+		}
+
+		err = match.Output(*q.Out, schema)
+		if test.err == "" {
+			assert.NoError(t, err, test.sql)
+		} else {
+			assert.EqualError(t, err, test.err, test.sql)
+		}
+	}
+
+	// The cast result has the attributes of the target type only.
+	db = migrate(t, `
+		CREATE TYPE address AS (street text, city text);
+		CREATE TYPE point AS (x int, y int);
+		CREATE TABLE people (home address NOT NULL, spot point);
+		CREATE VIEW v AS SELECT spot::text::address AS a, home::text AS b, (home)::address AS c FROM people;
+	`)
+
+	v := db.TablesByName[pg.NewTableName("v", pg.DefaultSchema)]
+	assert.Equal(t, []string{"a address", "b text not null", "c address not null"}, columns(t, db, "v"))
+	assert.Equal(t, "public.address (\n  street text,\n  city text\n)", v.ColumnsByName["a"].Type.Record.String())
+	assert.Nil(t, v.ColumnsByName["b"].Type.Record)
+	assert.Nil(t, v.ColumnsByName["b"].Type.Composite)
+}
+
+func TestInheritedTableQueries(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE events (id text NOT NULL, happened_at date NOT NULL) PARTITION BY RANGE (happened_at);
+		CREATE TABLE events_2025 PARTITION OF events FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');
+		CREATE TABLE events_2026 PARTITION OF events FOR VALUES FROM ('2026-01-01') TO ('2027-01-01');
+		ALTER TABLE events DETACH PARTITION events_2025;
+		ALTER TABLE events ADD COLUMN kind text NOT NULL;
+	`)
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"SELECT kind FROM events", ""},
+		{"SELECT e.kind FROM events_2026 e JOIN events p ON p.id = e.id", ""},
+		{"INSERT INTO events_2026 (id, happened_at, kind) VALUES ('a', now(), 'b')", ""},
+		{"INSERT INTO events_2026 SELECT id, happened_at, kind FROM events", ""},
+		{"INSERT INTO events_2026 SELECT * FROM events", ""},
+		{"SELECT kind FROM events_2025", `failed to resolve column reference "kind"`},
+		{"INSERT INTO events_2026 (id, happened_at) VALUES ('a', now())", `column "kind" of table "public.events_2026" is not null and has no default but no value is inserted to it`},
+		{"INSERT INTO events_2026 SELECT id, happened_at FROM events", `column "kind" of table "public.events_2026" is not null and has no default but no value is inserted to it`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseQuery(db, "-- :name Find\n"+test.sql)
+
+		if test.err == "" {
+			assert.NoError(t, err, test.sql)
+		} else {
+			assert.ErrorContains(t, err, test.err, test.sql)
+		}
+	}
+}
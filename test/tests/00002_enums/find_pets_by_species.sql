@@ -0,0 +1,12 @@
+-- :name FindPetsBySpecies :in sqlio.PetSpecies :out pets.Pet
+SELECT
+  id,
+  name,
+  species
+FROM
+  pets
+WHERE
+  species = :species::pet_species
+ORDER BY
+  name
+;
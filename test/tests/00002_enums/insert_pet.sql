@@ -0,0 +1,15 @@
+-- :name InsertPet :in sqlio.PetInsert :out sqlio.Id
+INSERT INTO pets (
+  id,
+  name,
+  species,
+  owner_id
+) VALUES (
+  :pet.id,
+  :pet.name,
+  :pet.species::pet_species,
+  :ownerId
+)
+RETURNING
+  id
+;
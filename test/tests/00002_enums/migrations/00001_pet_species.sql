@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TYPE pet_species AS ENUM ('dog', 'cat');
+
+ALTER TABLE pets ALTER COLUMN species TYPE pet_species USING species::pet_species;
+
+-- +goose Down
+ALTER TABLE pets ALTER COLUMN species TYPE TEXT;
+
+DROP TYPE pet_species;
@@ -0,0 +1,32 @@
+CREATE VIEW public.adults (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE MATERIALIZED VIEW public.pet_owners (
+  pet_id text NOT NULL,
+  owner_id text NOT NULL
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
@@ -0,0 +1,23 @@
+-- :name FindAdults :in sqlio.Id :out persons.Person
+SELECT
+  a.*,
+  (
+    SELECT
+      COALESCE(JSON_AGG(pets), '[]')
+    FROM
+    (
+      SELECT
+        pets.*
+      FROM
+        pet_owners po
+      JOIN
+        pets ON pets.id = po.pet_id
+      WHERE
+        po.owner_id = a.id
+    ) pets
+  ) pets
+FROM
+  adults a
+WHERE
+  id = :id
+;
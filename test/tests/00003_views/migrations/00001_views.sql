@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE VIEW adult_persons AS
+SELECT
+  id,
+  first_name,
+  last_name,
+  age
+FROM
+  persons
+WHERE
+  age >= 18;
+
+CREATE OR REPLACE VIEW adult_persons AS
+SELECT
+  id,
+  first_name,
+  last_name,
+  age,
+  address
+FROM
+  persons
+WHERE
+  age >= 18;
+
+ALTER VIEW adult_persons RENAME TO adults;
+
+CREATE MATERIALIZED VIEW pet_owners (pet_id, owner_id) AS
+SELECT
+  id,
+  owner_id
+FROM
+  pets
+WITH NO DATA;
+
+-- +goose Down
+DROP MATERIALIZED VIEW pet_owners;
+DROP VIEW adults;
@@ -0,0 +1,25 @@
+CREATE TYPE public.pet_species AS ENUM ('dog', 'cat');
+
+CREATE DOMAIN public.animal AS public.pet_species;
+
+CREATE DOMAIN public.positive_int AS pg_catalog.int4 NOT NULL;
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age public.positive_int NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species public.animal NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
@@ -0,0 +1,10 @@
+-- :name FindPetsBySpecies :in sqlio.PetSpecies :out pets.Pet
+SELECT
+  id,
+  name,
+  species
+FROM
+  pets
+WHERE
+  species = :species::animal
+;
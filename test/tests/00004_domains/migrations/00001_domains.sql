@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TYPE pet_species AS ENUM ('dog', 'cat');
+
+CREATE DOMAIN positive_int AS INT NOT NULL CHECK (VALUE > 0);
+CREATE DOMAIN animal AS pet_species;
+
+ALTER TABLE persons ALTER COLUMN age TYPE positive_int;
+ALTER TABLE pets ALTER COLUMN species TYPE animal;
+
+-- +goose Down
+ALTER TABLE pets ALTER COLUMN species TYPE TEXT;
+ALTER TABLE persons ALTER COLUMN age TYPE INT;
+
+DROP DOMAIN animal;
+DROP DOMAIN positive_int;
+DROP TYPE pet_species;
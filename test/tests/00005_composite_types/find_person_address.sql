@@ -0,0 +1,8 @@
+-- :name FindPersonAddress :in sqlio.Id :out persons.Address
+SELECT
+  (p.postal_address).*
+FROM
+  persons p
+WHERE
+  id = :id
+;
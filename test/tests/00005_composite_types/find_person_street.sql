@@ -0,0 +1,9 @@
+-- :name FindPersonStreet :in sqlio.Id :out persons.Address
+SELECT
+  (postal_address).street,
+  (postal_address).postal_code
+FROM
+  persons
+WHERE
+  id = :id
+;
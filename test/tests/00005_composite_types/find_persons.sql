@@ -0,0 +1,20 @@
+-- :name FindPersons :in sqlio.Id :out persons.Person
+SELECT
+  p.id,
+  p.first_name,
+  p.last_name,
+  p.age,
+  p.postal_address AS address,
+  (
+    SELECT
+      COALESCE(JSON_AGG(pets), '[]')
+    FROM
+      pets
+    WHERE
+      pets.owner_id = p.id
+  ) pets
+FROM
+  persons p
+WHERE
+  id = :id
+;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TYPE postal_address AS (
+  street_name TEXT,
+  postal_code TEXT
+);
+
+ALTER TYPE postal_address RENAME ATTRIBUTE street_name TO street;
+ALTER TABLE persons ADD COLUMN postal_address postal_address;
+
+-- +goose Down
+ALTER TABLE persons DROP COLUMN postal_address;
+DROP TYPE postal_address;
@@ -0,0 +1,39 @@
+CREATE SCHEMA archive;
+
+CREATE SCHEMA billing;
+
+CREATE TABLE billing.invoices (
+  id text NOT NULL,
+  person_id text NOT NULL,
+  CONSTRAINT invoices_pkey PRIMARY KEY (id),
+  CONSTRAINT invoices_person_id_fkey FOREIGN KEY (person_id) REFERENCES public.persons (id)
+);
+
+CREATE TABLE billing.old_invoices (
+  legacy_id text NOT NULL
+);
+
+CREATE TABLE public.invoices (
+  legacy_id text NOT NULL,
+  CONSTRAINT invoices_pkey PRIMARY KEY (legacy_id)
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
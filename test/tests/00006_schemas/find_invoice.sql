@@ -0,0 +1,10 @@
+-- :name FindInvoice :in sqlio.Id :out sqlio.Id
+SELECT
+  i.id
+FROM
+  invoices i
+JOIN
+  public.persons p ON p.id = i.person_id
+WHERE
+  i.id = :id
+;
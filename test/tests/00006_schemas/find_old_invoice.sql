@@ -0,0 +1,8 @@
+-- :name FindOldInvoice :in sqlio.Id :out sqlio.Id
+SELECT
+  legacy_id AS id
+FROM
+  old_invoices
+WHERE
+  legacy_id = :id
+;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE invoices (
+  legacy_id TEXT PRIMARY KEY
+);
+
+CREATE SCHEMA billing;
+
+CREATE TABLE billing.invoices (
+  invoice_id TEXT PRIMARY KEY
+);
+
+ALTER TABLE billing.invoices RENAME COLUMN invoice_id TO id;
+ALTER TABLE billing.invoices ADD COLUMN person_id TEXT NOT NULL REFERENCES persons (id);
+
+CREATE SCHEMA archive;
+CREATE TABLE archive.old_invoices (LIKE public.invoices);
+ALTER TABLE archive.old_invoices SET SCHEMA billing;
+
+-- +goose Down
+DROP SCHEMA archive;
+DROP SCHEMA billing CASCADE;
+DROP TABLE invoices;
@@ -0,0 +1,15 @@
+-- :name InsertDraft :in sqlio.PersonUpdate :out sqlio.Id
+INSERT INTO notes (
+  person_id,
+  body,
+  status,
+  created_at
+) VALUES (
+  :id,
+  :person.firstName,
+  :status,
+  :createdAt
+)
+RETURNING
+  person_id AS id
+;
@@ -0,0 +1,11 @@
+-- :name InsertNote :in sqlio.PersonUpdate :out sqlio.Id
+INSERT INTO notes (
+  person_id,
+  body
+) VALUES (
+  :id,
+  :person.firstName
+)
+RETURNING
+  person_id AS id
+;
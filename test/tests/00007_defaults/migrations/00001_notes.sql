@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE notes (
+  id BIGSERIAL PRIMARY KEY,
+  seq INT GENERATED BY DEFAULT AS IDENTITY,
+  person_id TEXT NOT NULL REFERENCES persons (id),
+  body TEXT NOT NULL,
+  body_length INT GENERATED ALWAYS AS (length(body)) STORED,
+  status TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+ALTER TABLE notes ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE notes ALTER COLUMN seq SET GENERATED ALWAYS;
+
+-- +goose Down
+DROP TABLE notes;
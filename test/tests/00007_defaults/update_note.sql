@@ -0,0 +1,9 @@
+-- :name UpdateNote :in sqlio.PersonUpdate :out sqlio.Id
+UPDATE notes SET
+  body = :person.firstName,
+  body_length = DEFAULT
+WHERE
+  person_id = :id
+RETURNING
+  person_id AS id
+;
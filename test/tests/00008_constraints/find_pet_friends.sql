@@ -0,0 +1,8 @@
+-- :name FindPetFriends :in sqlio.Id :out sqlio.Id
+SELECT
+  friend_id AS id
+FROM
+  pet_friends
+WHERE
+  pet_id = :id
+;
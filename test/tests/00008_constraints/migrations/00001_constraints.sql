@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE pet_friends (
+  pet_id TEXT REFERENCES pets,
+  friend_id TEXT,
+  since DATE CHECK (since > '2000-01-01'),
+  until DATE,
+  PRIMARY KEY (pet_id, friend_id),
+  FOREIGN KEY (friend_id) REFERENCES pets (id),
+  CHECK (since < until),
+  UNIQUE (friend_id, pet_id)
+);
+
+ALTER TABLE pet_friends DROP CONSTRAINT pet_friends_since_check;
+ALTER TABLE pet_friends DROP CONSTRAINT pet_friends_check;
+ALTER TABLE pet_friends RENAME CONSTRAINT pet_friends_friend_id_pet_id_key TO pet_friends_unique;
+ALTER TABLE pet_friends ADD CONSTRAINT pet_friends_not_self CHECK (pet_id <> friend_id);
+ALTER TABLE pet_friends DROP COLUMN until;
+
+ALTER TABLE pets RENAME COLUMN id TO pet_id;
+ALTER TABLE pets RENAME COLUMN pet_id TO id;
+ALTER TABLE pet_friends DROP CONSTRAINT pet_friends_pet_id_fkey, DROP CONSTRAINT IF EXISTS pet_friends_pet_id_fkey;
+ALTER TABLE pet_friends DROP CONSTRAINT pet_friends_friend_id_fkey;
+
+-- +goose Down
+DROP TABLE pet_friends;
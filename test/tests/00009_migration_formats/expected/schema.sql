@@ -0,0 +1,50 @@
+CREATE TABLE public.dbmate_items (
+  id text NOT NULL,
+  CONSTRAINT dbmate_items_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.flyway_things (
+  id text NOT NULL,
+  CONSTRAINT flyway_items_pkey PRIMARY KEY (id)
+);
+
+CREATE VIEW public.flyway_things_view (
+  id text NOT NULL
+);
+
+CREATE TABLE public.goose_items (
+  id text NOT NULL,
+  CONSTRAINT goose_items_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.migrate_items (
+  id text NOT NULL,
+  CONSTRAINT migrate_items_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
+
+CREATE TABLE public.sql_migrate_items (
+  id text NOT NULL,
+  CONSTRAINT sql_migrate_items_pkey PRIMARY KEY (id)
+);
+
+CREATE FUNCTION public.goose_items_count() RETURNS pg_catalog.int8;
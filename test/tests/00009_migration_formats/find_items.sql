@@ -0,0 +1,16 @@
+-- :name FindItems :in sqlio.Id :out sqlio.Id
+SELECT
+  g.id
+FROM
+  goose_items g
+JOIN
+  migrate_items m ON m.id = g.id
+JOIN
+  dbmate_items d ON d.id = g.id
+JOIN
+  sql_migrate_items s ON s.id = g.id
+JOIN
+  flyway_things_view f ON f.id = g.id
+WHERE
+  g.id = :id
+;
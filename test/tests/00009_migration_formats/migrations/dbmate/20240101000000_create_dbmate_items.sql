@@ -0,0 +1,7 @@
+-- migrate:up transaction:false
+CREATE TABLE dbmate_items (
+  item_id TEXT PRIMARY KEY
+);
+
+-- migrate:down
+DROP TABLE dbmate_items;
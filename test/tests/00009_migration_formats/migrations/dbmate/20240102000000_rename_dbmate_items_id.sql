@@ -0,0 +1,5 @@
+-- migrate:up
+ALTER TABLE dbmate_items RENAME COLUMN item_id TO id;
+
+-- migrate:down
+ALTER TABLE dbmate_items RENAME COLUMN id TO item_id;
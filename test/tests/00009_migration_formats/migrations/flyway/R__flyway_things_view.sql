@@ -0,0 +1 @@
+CREATE OR REPLACE VIEW flyway_things_view AS SELECT id FROM flyway_things;
@@ -0,0 +1 @@
+ALTER TABLE flyway_items RENAME COLUMN id TO item_id;
@@ -0,0 +1 @@
+ALTER TABLE flyway_items RENAME TO flyway_things;
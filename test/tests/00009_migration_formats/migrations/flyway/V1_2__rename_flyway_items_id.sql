@@ -0,0 +1 @@
+ALTER TABLE flyway_items RENAME COLUMN item_id TO id;
@@ -0,0 +1,3 @@
+CREATE TABLE flyway_items (
+  item_id TEXT PRIMARY KEY
+);
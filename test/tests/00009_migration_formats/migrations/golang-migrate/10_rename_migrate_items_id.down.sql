@@ -0,0 +1 @@
+ALTER TABLE migrate_items RENAME COLUMN id TO item_id;
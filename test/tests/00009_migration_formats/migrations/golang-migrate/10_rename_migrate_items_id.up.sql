@@ -0,0 +1 @@
+ALTER TABLE migrate_items RENAME COLUMN item_id TO id;
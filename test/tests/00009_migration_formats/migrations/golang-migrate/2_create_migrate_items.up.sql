@@ -0,0 +1,3 @@
+CREATE TABLE migrate_items (
+  item_id TEXT PRIMARY KEY
+);
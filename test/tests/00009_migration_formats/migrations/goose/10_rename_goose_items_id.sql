@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE goose_items RENAME COLUMN item_id TO id;
+
+-- +goose Down
+ALTER TABLE goose_items RENAME COLUMN id TO item_id;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE goose_items (
+  item_id TEXT PRIMARY KEY
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION goose_items_count() RETURNS BIGINT AS $$
+BEGIN
+  RETURN (SELECT count(*) FROM goose_items);
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION goose_items_count;
+DROP TABLE goose_items;
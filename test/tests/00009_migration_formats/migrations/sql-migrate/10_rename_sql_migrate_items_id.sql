@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE sql_migrate_items RENAME COLUMN item_id TO id;
+
+-- +migrate Down
+ALTER TABLE sql_migrate_items RENAME COLUMN id TO item_id;
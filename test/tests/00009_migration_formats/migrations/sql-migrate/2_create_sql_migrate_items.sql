@@ -0,0 +1,7 @@
+-- +migrate Up
+CREATE TABLE sql_migrate_items (
+  item_id TEXT PRIMARY KEY
+);
+
+-- +migrate Down
+DROP TABLE sql_migrate_items;
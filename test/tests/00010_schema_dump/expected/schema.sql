@@ -0,0 +1,25 @@
+CREATE SCHEMA accounting;
+
+CREATE TYPE public.account_status AS ENUM ('active', 'closed');
+
+CREATE TABLE accounting.ledger_entries (
+  id text NOT NULL,
+  account_id pg_catalog.int4 NOT NULL,
+  amount pg_catalog.numeric NOT NULL,
+  CONSTRAINT ledger_entries_pkey PRIMARY KEY (id),
+  CONSTRAINT ledger_entries_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts (id)
+);
+
+CREATE TABLE public.accounts (
+  id pg_catalog.int4 NOT NULL DEFAULT nextval('public.accounts_id_seq'::regclass),
+  name text NOT NULL,
+  status public.account_status NOT NULL DEFAULT 'active'::public.account_status,
+  created_at pg_catalog.timestamptz NOT NULL DEFAULT current_timestamp,
+  email text,
+  CONSTRAINT accounts_pkey PRIMARY KEY (id)
+);
+
+CREATE VIEW public.active_accounts (
+  id pg_catalog.int4 NOT NULL,
+  name text NOT NULL
+);
@@ -0,0 +1,12 @@
+-- :name FindAccountEmail :in sqlio.Id :out sqlio.Id
+SELECT
+  a.email AS id
+FROM
+  active_accounts aa
+JOIN
+  accounts a ON a.id = aa.id
+JOIN
+  accounting.ledger_entries le ON le.account_id = a.id
+WHERE
+  le.id = :id
+;
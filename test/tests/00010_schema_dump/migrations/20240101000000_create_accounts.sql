@@ -0,0 +1,13 @@
+-- migrate:up
+CREATE TYPE account_status AS ENUM ('active', 'closed');
+
+CREATE TABLE accounts (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  status account_status NOT NULL DEFAULT 'active',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- migrate:down
+DROP TABLE accounts;
+DROP TYPE account_status;
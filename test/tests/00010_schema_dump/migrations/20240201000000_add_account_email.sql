@@ -0,0 +1,5 @@
+-- migrate:up
+ALTER TABLE accounts ADD COLUMN email TEXT;
+
+-- migrate:down
+ALTER TABLE accounts DROP COLUMN email;
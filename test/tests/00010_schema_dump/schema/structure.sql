@@ -0,0 +1,150 @@
+--
+-- PostgreSQL database dump
+--
+
+\restrict b7cQf1kUQaHPbWb1wvsjvKSrMnrNVZbYhHd3oRmI5yBBUUJZzCbIGPMpPkdrmQo
+
+-- Dumped from database version 16.2
+-- Dumped by pg_dump version 16.2
+
+SET statement_timeout = 0;
+SET lock_timeout = 0;
+SET idle_in_transaction_session_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+SET check_function_bodies = false;
+SET xmloption = content;
+SET client_min_messages = warning;
+SET row_security = off;
+
+--
+-- Name: accounting; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA accounting;
+
+
+ALTER SCHEMA accounting OWNER TO postgres;
+
+--
+-- Name: account_status; Type: TYPE; Schema: public; Owner: postgres
+--
+
+CREATE TYPE public.account_status AS ENUM (
+    'active',
+    'closed'
+);
+
+
+ALTER TYPE public.account_status OWNER TO postgres;
+
+SET default_tablespace = '';
+
+SET default_table_access_method = heap;
+
+--
+-- Name: accounts; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.accounts (
+    id integer NOT NULL,
+    name text NOT NULL,
+    status public.account_status DEFAULT 'active'::public.account_status NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+ALTER TABLE public.accounts OWNER TO postgres;
+
+--
+-- Name: accounts_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.accounts_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.accounts_id_seq OWNER TO postgres;
+
+--
+-- Name: accounts_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: postgres
+--
+
+ALTER SEQUENCE public.accounts_id_seq OWNED BY public.accounts.id;
+
+--
+-- Name: ledger_entries; Type: TABLE; Schema: accounting; Owner: postgres
+--
+
+CREATE TABLE accounting.ledger_entries (
+    id text NOT NULL,
+    account_id integer NOT NULL,
+    amount numeric NOT NULL
+);
+
+
+ALTER TABLE accounting.ledger_entries OWNER TO postgres;
+
+--
+-- Name: active_accounts; Type: VIEW; Schema: public; Owner: postgres
+--
+
+CREATE VIEW public.active_accounts AS
+ SELECT accounts.id,
+    accounts.name
+   FROM public.accounts
+  WHERE (accounts.status = 'active'::public.account_status);
+
+
+ALTER VIEW public.active_accounts OWNER TO postgres;
+
+--
+-- Name: accounts id; Type: DEFAULT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.accounts ALTER COLUMN id SET DEFAULT nextval('public.accounts_id_seq'::regclass);
+
+
+--
+-- Name: accounts accounts_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.accounts
+    ADD CONSTRAINT accounts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: ledger_entries ledger_entries_pkey; Type: CONSTRAINT; Schema: accounting; Owner: postgres
+--
+
+ALTER TABLE ONLY accounting.ledger_entries
+    ADD CONSTRAINT ledger_entries_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: ledger_entries_account_id_idx; Type: INDEX; Schema: accounting; Owner: postgres
+--
+
+CREATE INDEX ledger_entries_account_id_idx ON accounting.ledger_entries USING btree (account_id);
+
+
+--
+-- Name: ledger_entries ledger_entries_account_id_fkey; Type: FK CONSTRAINT; Schema: accounting; Owner: postgres
+--
+
+ALTER TABLE ONLY accounting.ledger_entries
+    ADD CONSTRAINT ledger_entries_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(id);
+
+
+--
+-- PostgreSQL database dump complete
+--
+
+\unrestrict b7cQf1kUQaHPbWb1wvsjvKSrMnrNVZbYhHd3oRmI5yBBUUJZzCbIGPMpPkdrmQo
+
@@ -0,0 +1,10 @@
+-- :name FindArchivedPets :in sqlio.Id :out sqlio.Id
+SELECT
+  p.name AS id
+FROM
+  pet_archive p
+JOIN
+  person_archive a ON a.person_id = p.id
+WHERE
+  a.first_name = :id
+;
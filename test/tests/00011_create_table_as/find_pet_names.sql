@@ -0,0 +1,8 @@
+-- :name FindPetNames :in sqlio.Id :out sqlio.Id
+SELECT
+  pet_name AS id
+FROM
+  pet_names
+WHERE
+  pet_name = :id
+;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE person_archive (person_id, first_name) AS
+SELECT
+  id,
+  first_name
+FROM
+  persons
+WHERE
+  age > 100;
+
+CREATE TABLE IF NOT EXISTS person_archive AS SELECT id FROM persons;
+
+SELECT
+  id,
+  name
+INTO
+  pet_archive
+FROM
+  pets;
+
+CREATE MATERIALIZED VIEW pet_names (pet_name) AS
+SELECT
+  name
+FROM
+  pets
+WITH NO DATA;
+
+-- +goose Down
+DROP MATERIALIZED VIEW pet_names;
+DROP TABLE pet_archive;
+DROP TABLE person_archive;
@@ -0,0 +1,69 @@
+CREATE TABLE public.events (
+  id text NOT NULL,
+  person_id text NOT NULL,
+  happened_at date NOT NULL,
+  event_kind text,
+  CONSTRAINT events_person_id_fkey FOREIGN KEY (person_id) REFERENCES public.persons (id),
+  CONSTRAINT events_pkey PRIMARY KEY (id, happened_at)
+);
+
+CREATE TABLE public.events_2025 (
+  id text NOT NULL,
+  person_id text NOT NULL,
+  happened_at date NOT NULL,
+  CONSTRAINT events_person_id_fkey FOREIGN KEY (person_id) REFERENCES public.persons (id),
+  CONSTRAINT events_2025_pkey PRIMARY KEY (id, happened_at)
+);
+
+CREATE TABLE public.events_2026 (
+  id text NOT NULL,
+  person_id text NOT NULL,
+  happened_at date NOT NULL DEFAULT '2026-01-01',
+  event_kind text,
+  CONSTRAINT events_person_id_fkey FOREIGN KEY (person_id) REFERENCES public.persons (id),
+  CONSTRAINT events_2026_pkey PRIMARY KEY (id, happened_at)
+) PARTITION OF public.events;
+
+CREATE TABLE public.events_2027 (
+  id text NOT NULL,
+  person_id text NOT NULL,
+  happened_at date NOT NULL,
+  event_kind text,
+  CONSTRAINT events_person_id_fkey FOREIGN KEY (person_id) REFERENCES public.persons (id),
+  CONSTRAINT events_2027_pkey PRIMARY KEY (id, happened_at)
+) PARTITION OF public.events;
+
+CREATE TABLE public.notes (
+  id text NOT NULL,
+  body text NOT NULL,
+  author text,
+  CONSTRAINT notes_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pet_notes (
+  id text NOT NULL,
+  body text NOT NULL,
+  pet_id text NOT NULL,
+  author text,
+  CONSTRAINT pet_notes_pet_id_fkey FOREIGN KEY (pet_id) REFERENCES public.pets (id)
+) INHERITS (public.notes);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
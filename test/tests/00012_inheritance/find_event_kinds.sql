@@ -0,0 +1,12 @@
+-- :name FindEventKinds :in sqlio.Id :out sqlio.Id
+SELECT
+  e.event_kind AS id
+FROM
+  events_2026 e
+JOIN
+  events_2027 e2 ON e2.event_kind = e.event_kind
+JOIN
+  events ep ON ep.id = e.id
+WHERE
+  e.person_id = :id
+;
@@ -0,0 +1,8 @@
+-- :name FindPetNoteAuthors :in sqlio.Id :out sqlio.Id
+SELECT
+  author AS id
+FROM
+  pet_notes
+WHERE
+  pet_id = :id AND body IS NOT NULL
+;
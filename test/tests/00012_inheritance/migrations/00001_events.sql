@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE events (
+  id TEXT NOT NULL,
+  person_id TEXT NOT NULL REFERENCES persons (id),
+  happened_at DATE NOT NULL,
+  PRIMARY KEY (id, happened_at)
+) PARTITION BY RANGE (happened_at);
+
+CREATE TABLE events_2025 PARTITION OF events
+FOR VALUES FROM ('2025-01-01') TO ('2026-01-01');
+
+CREATE TABLE events_2026 PARTITION OF events (
+  happened_at DEFAULT '2026-01-01'
+)
+FOR VALUES FROM ('2026-01-01') TO ('2027-01-01');
+
+CREATE TABLE events_2027 (
+  id TEXT NOT NULL,
+  person_id TEXT NOT NULL,
+  happened_at DATE NOT NULL
+);
+
+ALTER TABLE events ATTACH PARTITION events_2027 FOR VALUES FROM ('2027-01-01') TO ('2028-01-01');
+ALTER TABLE events DETACH PARTITION events_2025;
+
+ALTER TABLE events ADD COLUMN kind TEXT;
+ALTER TABLE events RENAME COLUMN kind TO event_kind;
+
+CREATE TABLE notes (
+  id TEXT PRIMARY KEY,
+  body TEXT NOT NULL
+);
+
+CREATE TABLE pet_notes (
+  pet_id TEXT NOT NULL REFERENCES pets (id),
+  body TEXT
+) INHERITS (notes);
+
+ALTER TABLE notes ADD COLUMN author TEXT;
+
+-- +goose Down
+DROP TABLE pet_notes;
+DROP TABLE notes;
+DROP TABLE events_2025;
+DROP TABLE events;
@@ -0,0 +1,4 @@
+-- :name CurrentTenant :out sqlio.Id
+SELECT
+  current_tenant() AS id
+;
@@ -0,0 +1,25 @@
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
+
+CREATE FUNCTION public.current_tenant() RETURNS text;
+
+CREATE FUNCTION public.pets_by_species(s text, max_count pg_catalog.int4 DEFAULT 100) RETURNS SETOF public.pets;
+
+CREATE FUNCTION public.search_people(q text) RETURNS TABLE (id text, full_name text);
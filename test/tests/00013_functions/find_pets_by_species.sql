@@ -0,0 +1,6 @@
+-- :name FindPetsBySpecies :in sqlio.PetSpecies :out sqlio.Id
+SELECT
+  p.name AS id
+FROM
+  pets_by_species(:species) AS p
+;
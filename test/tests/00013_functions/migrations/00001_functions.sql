@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE FUNCTION current_tenant() RETURNS text AS $$
+  SELECT current_setting('app.tenant')
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION search_people(q text) RETURNS TABLE (id text, full_name text) AS $$
+  SELECT id, first_name || ' ' || last_name FROM persons WHERE first_name ILIKE q
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION search_people(q text, min_age int) RETURNS TABLE (id text, full_name text) AS $$
+  SELECT id, first_name || ' ' || last_name FROM persons WHERE first_name ILIKE q AND age >= min_age
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION pets_by_species(s text, max_count int DEFAULT 100) RETURNS SETOF pets AS $$
+  SELECT * FROM pets WHERE species = s LIMIT max_count
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION current_tenant() RETURNS text AS $$
+  SELECT current_setting('app.tenant', true)
+$$ LANGUAGE sql STABLE;
+
+DROP FUNCTION search_people(text, int);
+
+-- +goose Down
+DROP FUNCTION pets_by_species;
+DROP FUNCTION search_people;
+DROP FUNCTION current_tenant();
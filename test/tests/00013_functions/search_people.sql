@@ -0,0 +1,10 @@
+-- :name SearchPeople :in sqlio.Id :out sqlio.Id
+SELECT
+  s.id
+FROM
+  search_people(:id) s
+JOIN
+  persons p ON p.id = s.id
+WHERE
+  p.address IS NOT NULL
+;
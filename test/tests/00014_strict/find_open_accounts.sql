@@ -0,0 +1,9 @@
+-- :name FindOpenAccounts :in sqlio.Id :out sqlio.Id
+SELECT
+  a.id
+FROM
+  accounts a
+WHERE
+  a.owner_id = :id
+  AND a.closed_at IS NULL
+;
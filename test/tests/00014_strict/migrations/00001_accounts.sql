@@ -0,0 +1,24 @@
+-- +goose Up
+BEGIN;
+
+CREATE TABLE accounts (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL REFERENCES persons (id),
+    name TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX accounts_name_idx ON accounts (name);
+GRANT SELECT ON accounts TO PUBLIC;
+COMMENT ON TABLE accounts IS 'Accounts of persons';
+
+ALTER TABLE accounts
+  ENABLE ROW LEVEL SECURITY,
+  ADD COLUMN closed_at TIMESTAMP WITH TIME ZONE,
+  VALIDATE CONSTRAINT accounts_owner_id_fkey;
+
+CREATE POLICY accounts_owner ON accounts USING (owner_id = current_user);
+
+COMMIT;
+
+-- +goose Down
+DROP TABLE accounts;
@@ -0,0 +1,9 @@
+-- :name FindAccount :in sqlio.Id :out sqlio.Id
+SELECT
+  id
+FROM
+  accounts
+WHERE
+  id = :id
+  AND account_balance(id) > 0
+;
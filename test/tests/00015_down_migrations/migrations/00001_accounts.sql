@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TYPE account_status AS ENUM ('open', 'closed');
+
+CREATE TABLE accounts (
+  id TEXT PRIMARY KEY,
+  owner_id TEXT NOT NULL REFERENCES persons(id),
+  status account_status NOT NULL DEFAULT 'open'
+);
+
+-- +goose Down
+DROP TABLE accounts;
+DROP TYPE account_status;
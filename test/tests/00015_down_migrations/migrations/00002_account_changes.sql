@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TYPE account_status ADD VALUE 'frozen';
+ALTER TABLE accounts ADD COLUMN balance NUMERIC;
+ALTER TABLE accounts ALTER COLUMN balance SET NOT NULL;
+ALTER TABLE persons ALTER COLUMN last_name SET NOT NULL;
+
+CREATE FUNCTION account_balance(account_id TEXT) RETURNS NUMERIC AS $$
+  SELECT balance FROM accounts WHERE id = account_id
+$$ LANGUAGE sql;
+
+-- +goose Down
+DROP FUNCTION account_balance(TEXT);
+ALTER TABLE persons ALTER COLUMN last_name DROP NOT NULL;
+ALTER TABLE accounts DROP COLUMN balance;
+ALTER TYPE account_status RENAME TO account_status_old;
+CREATE TYPE account_status AS ENUM ('open', 'closed');
+ALTER TABLE accounts ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE accounts ALTER COLUMN status TYPE account_status USING status::text::account_status;
+ALTER TABLE accounts ALTER COLUMN status SET DEFAULT 'open';
+DROP TYPE account_status_old;
@@ -0,0 +1,32 @@
+CREATE DOMAIN public.sku AS pg_catalog.varchar(16);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
+
+CREATE TABLE public.products (
+  id text NOT NULL,
+  sku public.sku NOT NULL,
+  name pg_catalog.varchar(128) NOT NULL,
+  price pg_catalog.numeric(14, 4) NOT NULL,
+  currency pg_catalog.bpchar(3) NOT NULL,
+  tags text[],
+  dimensions pg_catalog.int4[][],
+  CONSTRAINT products_pkey PRIMARY KEY (id)
+);
@@ -0,0 +1,8 @@
+-- :name FindProductsByTag :in sqlio.Id :out sqlio.Id
+SELECT
+  id
+FROM
+  products
+WHERE
+  :id = ANY(tags)
+;
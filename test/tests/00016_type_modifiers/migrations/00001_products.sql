@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE DOMAIN sku AS VARCHAR(16);
+
+CREATE TABLE products (
+  id TEXT PRIMARY KEY,
+  sku sku NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  price NUMERIC(12, 2) NOT NULL,
+  currency CHAR(3) NOT NULL,
+  tags TEXT,
+  dimensions INT[][]
+);
+
+-- +goose Down
+DROP TABLE products;
+DROP DOMAIN sku;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE products ALTER COLUMN name TYPE VARCHAR(128);
+ALTER TABLE products ALTER COLUMN price TYPE NUMERIC(14, 4);
+ALTER TABLE products ALTER COLUMN tags TYPE TEXT[] USING ARRAY[tags];
+
+-- +goose Down
+ALTER TABLE products ALTER COLUMN tags TYPE TEXT USING tags[1];
+ALTER TABLE products ALTER COLUMN price TYPE NUMERIC(12, 2);
+ALTER TABLE products ALTER COLUMN name TYPE VARCHAR(64);
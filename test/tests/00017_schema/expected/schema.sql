@@ -0,0 +1,59 @@
+CREATE SCHEMA shop;
+
+CREATE TYPE shop.order_status AS ENUM ('new', 'shipped', 'it''s complicated');
+
+CREATE DOMAIN shop.price AS pg_catalog.numeric(12, 2) NOT NULL;
+
+CREATE TYPE shop.dimensions AS (
+  width pg_catalog.int4,
+  height pg_catalog.int4
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
+
+CREATE VIEW shop.order_totals (
+  id text NOT NULL,
+  total pg_catalog.numeric(14, 2) NOT NULL
+);
+
+CREATE TABLE shop.orders (
+  id text NOT NULL,
+  person_id text NOT NULL,
+  product_id pg_catalog.int4 NOT NULL,
+  quantity pg_catalog.int4 NOT NULL DEFAULT 1,
+  status shop.order_status NOT NULL DEFAULT 'new',
+  CONSTRAINT orders_pkey PRIMARY KEY (id),
+  CONSTRAINT orders_person_id_fkey FOREIGN KEY (person_id) REFERENCES public.persons (id),
+  CONSTRAINT orders_quantity_check CHECK (quantity > 0),
+  CONSTRAINT orders_product_fkey FOREIGN KEY (product_id) REFERENCES shop.products (id)
+);
+
+CREATE TABLE shop.products (
+  id pg_catalog.int4 NOT NULL GENERATED ALWAYS AS IDENTITY,
+  name pg_catalog.varchar(64) NOT NULL,
+  price shop.price NOT NULL,
+  size shop.dimensions,
+  tags text[],
+  CONSTRAINT products_pkey PRIMARY KEY (id),
+  CONSTRAINT products_name_key UNIQUE (name)
+);
+
+CREATE FUNCTION shop.order_count(person_id text, OUT total pg_catalog.int4, OUT shipped pg_catalog.int4) RETURNS record;
@@ -0,0 +1,8 @@
+-- :name FindOrder :in sqlio.Id :out sqlio.Id
+SELECT
+  id
+FROM
+  shop.orders
+WHERE
+  id = :id
+;
@@ -0,0 +1,50 @@
+-- +goose Up
+CREATE SCHEMA shop;
+
+CREATE DOMAIN shop.price AS NUMERIC(12, 2) NOT NULL CHECK (VALUE >= 0);
+
+CREATE TYPE shop.dimensions AS (
+  width INT,
+  height INT
+);
+
+CREATE TYPE shop.order_status AS ENUM ('new', 'shipped', 'it''s complicated');
+
+CREATE TABLE shop.products (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  name VARCHAR(64) NOT NULL UNIQUE,
+  price shop.price,
+  size shop.dimensions,
+  tags TEXT[]
+);
+
+CREATE TABLE shop.orders (
+  id TEXT PRIMARY KEY,
+  person_id TEXT NOT NULL REFERENCES persons(id),
+  product_id INT NOT NULL,
+  quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0),
+  status shop.order_status NOT NULL DEFAULT 'new',
+  CONSTRAINT orders_product_fkey FOREIGN KEY (product_id) REFERENCES shop.products (id)
+);
+
+CREATE VIEW shop.order_totals AS
+SELECT
+  o.id,
+  (o.quantity * p.price)::NUMERIC(14, 2) AS total
+FROM
+  shop.orders o
+  JOIN shop.products p ON p.id = o.product_id;
+
+CREATE FUNCTION shop.order_count(person_id TEXT, OUT total INT, OUT shipped INT) AS $$
+  SELECT count(*), count(*) FILTER (WHERE status = 'shipped') FROM shop.orders o WHERE o.person_id = person_id
+$$ LANGUAGE sql;
+
+-- +goose Down
+DROP FUNCTION shop.order_count(TEXT);
+DROP VIEW shop.order_totals;
+DROP TABLE shop.orders;
+DROP TABLE shop.products;
+DROP TYPE shop.order_status;
+DROP TYPE shop.dimensions;
+DROP DOMAIN shop.price;
+DROP SCHEMA shop;
@@ -0,0 +1,9 @@
+-- :name FindOrder :in sqlio.Id :out sqlio.Id
+SELECT
+  id,
+  note
+FROM
+  orders
+WHERE
+  id = :id
+;
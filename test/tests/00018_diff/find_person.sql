@@ -0,0 +1,16 @@
+-- :name FindPerson :in sqlio.Id :out persons.Person
+SELECT
+  p.*,
+  (
+    SELECT
+      COALESCE(JSON_AGG(pets), '[]')
+    FROM
+      pets
+    WHERE
+      pets.owner_id = p.id
+  ) pets
+FROM
+  persons p
+WHERE
+  p.id = :id
+;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE orders (
+  id TEXT PRIMARY KEY,
+  person_id TEXT NOT NULL REFERENCES persons(id),
+  note TEXT
+);
+
+-- +goose Down
+DROP TABLE orders;
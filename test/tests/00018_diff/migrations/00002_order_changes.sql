@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE orders ALTER COLUMN note SET NOT NULL;
+ALTER TABLE persons ADD COLUMN nickname TEXT;
+
+-- +goose Down
+ALTER TABLE persons DROP COLUMN nickname;
+ALTER TABLE orders ALTER COLUMN note DROP NOT NULL;
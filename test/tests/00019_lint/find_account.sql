@@ -0,0 +1,9 @@
+-- :name FindAccount :in sqlio.Id :out sqlio.Id
+SELECT
+  id,
+  name
+FROM
+  accounts
+WHERE
+  id = :id
+;
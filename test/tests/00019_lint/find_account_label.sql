@@ -0,0 +1,9 @@
+-- :name FindAccountLabel :in sqlio.Id :out sqlio.Id
+SELECT
+  id,
+  label
+FROM
+  accounts
+WHERE
+  id = :id
+;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Tables created in the same migration can't have rows yet.
+CREATE TABLE accounts (
+  id TEXT PRIMARY KEY,
+  owner_id TEXT NOT NULL REFERENCES persons(id),
+  label VARCHAR(32)
+);
+
+CREATE INDEX accounts_owner_id_idx ON accounts (owner_id);
+ALTER TABLE accounts ADD COLUMN balance NUMERIC NOT NULL;
+ALTER TABLE accounts ALTER COLUMN balance TYPE NUMERIC(12, 2);
+
+-- +goose Down
+DROP TABLE accounts;
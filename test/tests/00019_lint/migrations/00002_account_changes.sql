@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE INDEX CONCURRENTLY accounts_label_idx ON accounts (label);
+
+ALTER TABLE accounts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE accounts ALTER COLUMN label TYPE VARCHAR(64);
+ALTER TABLE accounts ALTER COLUMN label SET NOT NULL;
+ALTER TABLE accounts RENAME COLUMN label TO name;
+
+-- The table is known to be small.
+-- norsu:ignore add-column-not-null, alter-column-type
+ALTER TABLE accounts
+  ADD COLUMN opened_at TIMESTAMPTZ NOT NULL,
+  ALTER COLUMN balance TYPE NUMERIC(14, 2);
+
+-- +goose Down
+ALTER TABLE accounts DROP COLUMN opened_at, DROP COLUMN currency;
+ALTER TABLE accounts ALTER COLUMN balance TYPE NUMERIC(12, 2);
+ALTER TABLE accounts RENAME COLUMN name TO label;
+ALTER TABLE accounts ALTER COLUMN label DROP NOT NULL;
+ALTER TABLE accounts ALTER COLUMN label TYPE VARCHAR(32);
+DROP INDEX accounts_label_idx;
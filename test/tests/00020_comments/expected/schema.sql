@@ -0,0 +1,49 @@
+CREATE TYPE public.money_amount AS (
+  amount pg_catalog.numeric,
+  currency text
+);
+COMMENT ON COLUMN public.money_amount.currency IS 'ISO 4217 currency code.';
+
+CREATE VIEW public.account_names (
+  id text NOT NULL,
+  name text NOT NULL
+);
+COMMENT ON VIEW public.account_names IS 'Names of the accounts.';
+
+CREATE TABLE public.accounts (
+  id text NOT NULL,
+  name text NOT NULL,
+  amount pg_catalog.numeric(14, 2) NOT NULL,
+  CONSTRAINT accounts_pkey PRIMARY KEY (id)
+);
+COMMENT ON TABLE public.accounts IS 'Customer accounts.';
+COMMENT ON COLUMN public.accounts.name IS 'Display name of the account.
+Shown on invoices.';
+COMMENT ON COLUMN public.accounts.amount IS 'Balance in euros. Can''t be negative.';
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
+
+CREATE TABLE public.premium_accounts (
+  id text NOT NULL,
+  name text NOT NULL,
+  amount pg_catalog.numeric(14, 2) NOT NULL,
+  level pg_catalog.int4 NOT NULL
+) INHERITS (public.accounts);
@@ -0,0 +1,12 @@
+-- :name FindAccount :in sqlio.Id :out sqlio.Id
+SELECT
+  a.id,
+  a.name,
+  a.amount,
+  p.first_name
+FROM
+  accounts a
+  JOIN persons p ON p.id = a.id
+WHERE
+  a.id = :id
+;
@@ -0,0 +1,8 @@
+-- :name FindPerson :in sqlio.Id :out sqlio.Id
+SELECT
+  id
+FROM
+  persons
+WHERE
+  id = :id
+;
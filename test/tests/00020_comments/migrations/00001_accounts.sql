@@ -0,0 +1,32 @@
+CREATE TABLE accounts (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  balance NUMERIC(14, 2) NOT NULL
+);
+
+COMMENT ON TABLE accounts IS 'Customer accounts.';
+COMMENT ON COLUMN accounts.id IS 'Temporary comment.';
+COMMENT ON COLUMN accounts.id IS NULL;
+COMMENT ON COLUMN accounts.name IS 'Display name of the account.
+Shown on invoices.';
+COMMENT ON COLUMN public.accounts.balance IS 'Balance in euros. Can''t be negative.';
+
+-- Child tables and views don't inherit comments.
+CREATE TABLE premium_accounts (
+  level INT NOT NULL
+) INHERITS (accounts);
+
+CREATE VIEW account_names AS
+SELECT id, name FROM accounts;
+
+COMMENT ON VIEW account_names IS 'Names of the accounts.';
+
+CREATE TYPE money_amount AS (
+  amount NUMERIC,
+  currency TEXT
+);
+
+COMMENT ON COLUMN money_amount.currency IS 'ISO 4217 currency code.';
+
+-- Comments on other objects are not tracked.
+COMMENT ON SCHEMA public IS 'Standard public schema';
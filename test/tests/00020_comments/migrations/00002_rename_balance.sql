@@ -0,0 +1 @@
+ALTER TABLE accounts RENAME COLUMN balance TO amount;
@@ -0,0 +1,51 @@
+CREATE SCHEMA billing;
+
+CREATE TYPE billing.account_status AS ENUM ('active', 'closed');
+
+CREATE TYPE billing.address AS (
+  street text
+);
+
+CREATE VIEW billing.account_emails (
+  id text NOT NULL,
+  email text NOT NULL
+);
+
+CREATE MATERIALIZED VIEW billing.account_names (
+  id text NOT NULL,
+  name text NOT NULL
+);
+
+CREATE TABLE billing.accounts (
+  id text NOT NULL,
+  name text NOT NULL,
+  email text NOT NULL,
+  CONSTRAINT accounts_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE billing.premium_accounts (
+  id text NOT NULL,
+  name text NOT NULL,
+  level pg_catalog.int4 NOT NULL,
+  email text NOT NULL
+) INHERITS (billing.accounts);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
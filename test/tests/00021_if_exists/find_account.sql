@@ -0,0 +1,10 @@
+-- :name FindAccount :in sqlio.Id :out sqlio.Id
+SELECT
+  id,
+  name,
+  email
+FROM
+  billing.accounts
+WHERE
+  id = :id
+;
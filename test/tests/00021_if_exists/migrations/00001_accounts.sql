@@ -0,0 +1,39 @@
+CREATE SCHEMA IF NOT EXISTS billing;
+
+CREATE TABLE IF NOT EXISTS billing.accounts (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS billing.premium_accounts (
+  level INT NOT NULL
+) INHERITS (billing.accounts);
+
+ALTER TABLE billing.accounts ADD COLUMN IF NOT EXISTS email TEXT NOT NULL;
+ALTER TABLE billing.accounts DROP COLUMN IF EXISTS legacy_id;
+ALTER TABLE billing.accounts DROP CONSTRAINT IF EXISTS accounts_legacy_id_key;
+
+ALTER TABLE IF EXISTS billing.old_accounts ADD COLUMN note TEXT;
+ALTER TABLE IF EXISTS billing.old_accounts RENAME COLUMN note TO notes;
+ALTER TABLE IF EXISTS billing.old_accounts RENAME TO older_accounts;
+ALTER TABLE IF EXISTS billing.old_accounts SET SCHEMA public;
+DROP TABLE IF EXISTS billing.old_accounts;
+
+DROP VIEW IF EXISTS billing.account_emails;
+CREATE VIEW billing.account_emails AS
+SELECT id, email FROM billing.accounts;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS billing.account_names AS
+SELECT id, name FROM billing.accounts;
+
+DROP TYPE IF EXISTS billing.account_status;
+CREATE TYPE billing.account_status AS ENUM ('active');
+ALTER TYPE billing.account_status ADD VALUE IF NOT EXISTS 'closed';
+
+CREATE TYPE billing.address AS (
+  street TEXT
+);
+ALTER TYPE billing.address DROP ATTRIBUTE IF EXISTS zip;
+
+DROP DOMAIN IF EXISTS billing.email_address;
+DROP FUNCTION IF EXISTS billing.account_count();
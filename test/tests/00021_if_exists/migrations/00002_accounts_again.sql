@@ -0,0 +1,26 @@
+-- Re-running the statements of the first migration doesn't change the schema.
+CREATE SCHEMA IF NOT EXISTS billing;
+
+CREATE TABLE IF NOT EXISTS billing.accounts (
+  id INT PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS billing.premium_accounts (
+  level INT NOT NULL
+) INHERITS (billing.accounts);
+
+ALTER TABLE billing.accounts ADD COLUMN IF NOT EXISTS email INT;
+ALTER TABLE billing.accounts DROP COLUMN IF EXISTS legacy_id;
+ALTER TABLE billing.accounts DROP CONSTRAINT IF EXISTS accounts_legacy_id_key;
+
+ALTER TABLE IF EXISTS billing.old_accounts ADD COLUMN note TEXT;
+DROP TABLE IF EXISTS billing.old_accounts;
+
+CREATE OR REPLACE VIEW billing.account_emails AS
+SELECT id, email FROM billing.accounts;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS billing.account_names AS
+SELECT id FROM billing.accounts;
+
+ALTER TYPE billing.account_status ADD VALUE IF NOT EXISTS 'closed';
+ALTER TYPE billing.address DROP ATTRIBUTE IF EXISTS zip;
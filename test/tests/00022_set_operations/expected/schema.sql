@@ -0,0 +1,58 @@
+CREATE TYPE public.pet_species AS ENUM ('dog', 'cat');
+
+CREATE VIEW public.contacts (
+  id text NOT NULL,
+  name text NOT NULL,
+  email text,
+  score pg_catalog.numeric NOT NULL,
+  kind text NOT NULL,
+  note text
+);
+
+CREATE VIEW public.customer_only_emails (
+  email text
+);
+
+CREATE TABLE public.customers (
+  id text NOT NULL,
+  name text NOT NULL,
+  email pg_catalog.varchar(100),
+  score pg_catalog.int4 NOT NULL,
+  CONSTRAINT customers_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
+
+CREATE VIEW public.shared_emails (
+  email text NOT NULL
+);
+
+CREATE VIEW public.species_list (
+  species public.pet_species
+);
+
+CREATE TABLE public.suppliers (
+  id text NOT NULL,
+  name pg_catalog.varchar(64) NOT NULL,
+  email text NOT NULL,
+  score pg_catalog.numeric(10, 2) NOT NULL,
+  CONSTRAINT suppliers_pkey PRIMARY KEY (id)
+);
@@ -0,0 +1,25 @@
+-- :name FindContactIds :in sqlio.Id :out sqlio.Id
+WITH all_contacts AS (
+  SELECT id FROM customers
+  UNION
+  SELECT id FROM suppliers
+)
+SELECT
+  c.id
+FROM
+  all_contacts c
+  JOIN (
+    SELECT id FROM customers WHERE id = :id
+    EXCEPT
+    SELECT owner_id FROM pets
+  ) s ON s.id = c.id
+UNION ALL
+SELECT
+  id
+FROM
+  persons
+WHERE
+  id = :id
+ORDER BY
+  id
+;
@@ -0,0 +1,41 @@
+CREATE TYPE pet_species AS ENUM ('dog', 'cat');
+
+CREATE TABLE customers (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  email VARCHAR(100),
+  score INT NOT NULL
+);
+
+CREATE TABLE suppliers (
+  id TEXT PRIMARY KEY,
+  name VARCHAR(64) NOT NULL,
+  email TEXT NOT NULL,
+  score NUMERIC(10, 2) NOT NULL
+);
+
+-- The columns are named after the first branch. Literals get the types
+-- of the other branches.
+CREATE VIEW contacts AS
+SELECT id, name, email, score, 'customer' AS kind, NULL AS note FROM customers
+UNION ALL
+SELECT id, name, email, score, 'supplier', 'preferred'::text FROM suppliers;
+
+-- A column is not null in an intersection if it's not null in either branch.
+CREATE VIEW shared_emails AS
+SELECT email FROM customers
+INTERSECT
+SELECT email FROM suppliers;
+
+-- Only the rows of the first branch are returned by EXCEPT.
+CREATE VIEW customer_only_emails AS
+SELECT email FROM customers
+EXCEPT
+SELECT email FROM suppliers;
+
+CREATE VIEW species_list AS
+SELECT species::pet_species FROM pets
+UNION
+SELECT 'dog'
+UNION
+SELECT NULL;
@@ -0,0 +1,19 @@
+-- :name FindPersonGroups :in sqlio.Id :out sqlio.Id
+WITH requested (id, priority) AS (
+  VALUES
+    (:id, 1),
+    ('root', 2)
+)
+SELECT
+  p.id,
+  g.label,
+  r.priority
+FROM
+  persons p
+  JOIN requested r ON r.id = p.id
+  JOIN (
+    VALUES
+      ('child', 0),
+      ('adult', 18)
+  ) AS g(label, min_age) ON p.age >= g.min_age
+;
@@ -0,0 +1,13 @@
+-- The columns of a VALUES list are named column1, column2 and so on.
+CREATE VIEW age_groups AS
+VALUES
+  ('child', 0, NULL),
+  ('adult', 18, NULL),
+  ('senior', 65, 'Retired'::varchar(32));
+
+-- A column alias list names the columns.
+CREATE VIEW pet_sizes AS
+SELECT
+  *
+FROM
+  (VALUES ('dog'::text, 'large'), ('cat', 'small')) AS s(species, size);
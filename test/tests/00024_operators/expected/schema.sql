@@ -0,0 +1,43 @@
+CREATE VIEW public.person_facts (
+  id text NOT NULL,
+  next_age pg_catalog.int4 NOT NULL,
+  scaled_age numeric NOT NULL,
+  negated_age pg_catalog.int4 NOT NULL,
+  full_name text,
+  greeting text NOT NULL,
+  adult bool NOT NULL,
+  same_names bool,
+  teenager bool NOT NULL,
+  j_name bool NOT NULL,
+  s_name bool,
+  young bool NOT NULL,
+  no_last_name bool NOT NULL,
+  not_smith bool NOT NULL,
+  has_pets bool NOT NULL,
+  owns_pets bool NOT NULL,
+  first_name_or_null text,
+  expires_at timestamptz,
+  since_2020 interval,
+  week_later date NOT NULL,
+  days_between int4 NOT NULL
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
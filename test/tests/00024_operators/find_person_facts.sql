@@ -0,0 +1,11 @@
+-- :name FindPersonFacts :in sqlio.Id :out sqlio.Id
+SELECT
+  p.id,
+  p.id = :id AS requested,
+  p.age * 2 AS double_age,
+  p.first_name || ' ' || p.last_name AS full_name
+FROM
+  persons p
+WHERE
+  p.id = :id
+;
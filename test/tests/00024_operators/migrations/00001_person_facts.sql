@@ -0,0 +1,30 @@
+CREATE VIEW person_facts AS
+SELECT
+  p.id,
+  -- Arithmetic promotes the operands to the wider numeric type.
+  p.age + 1 AS next_age,
+  p.age * 1.5 AS scaled_age,
+  -p.age AS negated_age,
+  -- Concatenation is null if any of the operands is null.
+  p.first_name || ' ' || p.last_name AS full_name,
+  p.first_name || '!' AS greeting,
+  -- Comparisons return bool.
+  p.age >= 18 AS adult,
+  p.last_name = p.first_name AS same_names,
+  p.age BETWEEN 13 AND 19 AS teenager,
+  p.first_name ILIKE 'j%' AS j_name,
+  p.last_name LIKE 'S%' AS s_name,
+  p.age IN (1, 2, 3) OR NOT p.age > 10 AS young,
+  -- These never return null.
+  p.last_name IS NULL AS no_last_name,
+  p.last_name IS DISTINCT FROM 'Smith' AS not_smith,
+  EXISTS (SELECT 1 FROM pets WHERE pets.owner_id = p.id) AS has_pets,
+  p.id IN (SELECT owner_id FROM pets) AS owns_pets,
+  NULLIF(p.first_name, '') AS first_name_or_null,
+  -- Date and interval arithmetic.
+  p.created_at + interval '1 day' AS expires_at,
+  p.created_at - '2020-01-01' AS since_2020,
+  '2024-01-01'::date + 7 AS week_later,
+  '2024-01-01'::date - '2023-01-01'::date AS days_between
+FROM
+  persons p;
@@ -0,0 +1,46 @@
+CREATE VIEW public.owner_stats (
+  owner_id text NOT NULL,
+  pet_count int8 NOT NULL,
+  name_lengths int8,
+  average_name_length numeric,
+  latest_pet_at pg_catalog.timestamptz,
+  species text[],
+  names text
+);
+
+CREATE VIEW public.person_details (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  trimmed_name text NOT NULL,
+  full_name text NOT NULL,
+  created_year numeric,
+  created_month pg_catalog.timestamptz,
+  decade numeric NOT NULL,
+  abs_age pg_catalog.int4 NOT NULL,
+  legal_age pg_catalog.int4 NOT NULL,
+  age_rank int8 NOT NULL,
+  queried_at timestamptz NOT NULL,
+  today date NOT NULL,
+  request_id uuid NOT NULL
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
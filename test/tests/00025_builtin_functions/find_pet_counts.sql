@@ -0,0 +1,14 @@
+-- :name FindPetCounts :in sqlio.Id :out sqlio.Id
+SELECT
+  p.id,
+  count(pet.id) AS pet_count,
+  max(pet.created_at) AS latest_pet_at,
+  lower(p.first_name) AS first_name
+FROM
+  persons p
+  LEFT JOIN pets pet ON pet.owner_id = p.id
+WHERE
+  p.id = :id
+GROUP BY
+  p.id
+;
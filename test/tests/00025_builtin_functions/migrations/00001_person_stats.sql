@@ -0,0 +1,34 @@
+-- Aggregates other than count return null for zero rows.
+CREATE VIEW owner_stats AS
+SELECT
+  p.owner_id,
+  count(*) AS pet_count,
+  sum(length(p.name)) AS name_lengths,
+  avg(length(p.name)) AS average_name_length,
+  max(p.created_at) AS latest_pet_at,
+  array_agg(p.species) AS species,
+  string_agg(p.name, ', ') AS names
+FROM
+  pets p
+GROUP BY
+  p.owner_id;
+
+-- Strict functions return null only for null arguments.
+CREATE VIEW person_details AS
+SELECT
+  p.id,
+  lower(p.first_name) AS first_name,
+  upper(p.last_name) AS last_name,
+  trim(p.first_name) AS trimmed_name,
+  concat(p.first_name, ' ', p.last_name) AS full_name,
+  extract(year FROM p.created_at) AS created_year,
+  date_trunc('month', p.created_at) AS created_month,
+  round(p.age::numeric / 10) AS decade,
+  abs(p.age) AS abs_age,
+  greatest(p.age, 18) AS legal_age,
+  row_number() OVER (ORDER BY p.age) AS age_rank,
+  now() AS queried_at,
+  current_date AS today,
+  gen_random_uuid() AS request_id
+FROM
+  persons p;
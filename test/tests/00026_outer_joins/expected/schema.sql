@@ -0,0 +1,52 @@
+CREATE VIEW public.all_persons_and_pets (
+  owner_id text,
+  pet_name text,
+  id text,
+  first_name text,
+  last_name text,
+  age pg_catalog.int4,
+  address jsonb,
+  created_at pg_catalog.timestamptz
+);
+
+CREATE VIEW public.person_pet_friends (
+  id text NOT NULL,
+  pet_name text,
+  friend_name text
+);
+
+CREATE VIEW public.person_pets (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  pet_id text,
+  pet_name text
+);
+
+CREATE TABLE public.persons (
+  id text NOT NULL,
+  first_name text NOT NULL,
+  last_name text,
+  age pg_catalog.int4 NOT NULL,
+  address jsonb NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT persons_pkey PRIMARY KEY (id)
+);
+
+CREATE VIEW public.pet_owners (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz,
+  owner_name text
+);
+
+CREATE TABLE public.pets (
+  id text NOT NULL,
+  name text NOT NULL,
+  species text NOT NULL,
+  owner_id text NOT NULL,
+  created_at pg_catalog.timestamptz DEFAULT current_timestamp,
+  CONSTRAINT pets_pkey PRIMARY KEY (id),
+  CONSTRAINT pets_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES public.persons (id)
+);
@@ -0,0 +1,10 @@
+-- :name FindPersonPets :in sqlio.Id :out sqlio.Id
+SELECT
+  p.id,
+  pet.*
+FROM
+  persons p
+  LEFT JOIN pets pet ON pet.owner_id = p.id
+WHERE
+  p.id = :id
+;
@@ -0,0 +1,46 @@
+-- The columns of the pets are null for persons without pets.
+CREATE VIEW person_pets AS
+SELECT
+  p.id,
+  p.first_name,
+  pet.id AS pet_id,
+  pet.name AS pet_name
+FROM
+  persons p
+  LEFT JOIN pets pet ON pet.owner_id = p.id;
+
+-- The columns of the persons are null for pets without owners.
+CREATE VIEW pet_owners AS
+SELECT
+  pet.*,
+  p.first_name AS owner_name
+FROM
+  persons p
+  RIGHT JOIN pets pet ON pet.owner_id = p.id;
+
+-- Both sides of a full join are nullable, including through `*`.
+CREATE VIEW all_persons_and_pets AS
+SELECT
+  *
+FROM
+  persons p
+  FULL JOIN (
+    SELECT
+      owner_id,
+      name AS pet_name
+    FROM
+      pets
+  ) pet ON pet.owner_id = p.id;
+
+-- Tables joined inside the nullable side of an outer join are nullable too.
+CREATE VIEW person_pet_friends AS
+SELECT
+  p.id,
+  pet.name AS pet_name,
+  friend.name AS friend_name
+FROM
+  persons p
+  LEFT JOIN (
+    pets pet
+    JOIN pets friend ON friend.owner_id = pet.owner_id
+  ) ON pet.owner_id = p.id;
@@ -0,0 +1,2 @@
+-- :name DeletePerson :in sqlio.Id
+DELETE FROM persons WHERE id = :id;
@@ -0,0 +1,6 @@
+-- :name FindPersonIds :out sqlio.Id
+SELECT
+  id
+FROM
+  persons
+;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE accounts (
+  id TEXT PRIMARY KEY,
+  owner_id TEXT NOT NULL REFERENCES persons (id)
+);
+
+-- Indexes, owners and grants don't affect the schema and are allowed.
+CREATE INDEX accounts_owner_id_idx ON accounts (owner_id);
+ALTER TABLE accounts OWNER TO postgres;
+GRANT SELECT ON accounts TO PUBLIC;
+
+CREATE TRIGGER accounts_audit AFTER INSERT ON accounts
+  FOR EACH ROW EXECUTE FUNCTION audit();
+
+ALTER TABLE accounts SET UNLOGGED, ADD COLUMN closed_at TIMESTAMPTZ;
+
+-- +goose Down
+DROP TABLE accounts;
@@ -0,0 +1,8 @@
+CREATE TYPE public.note_status AS ENUM ('draft', 'published');
+
+CREATE TABLE public.notes (
+  id text NOT NULL,
+  status public.note_status NOT NULL DEFAULT 'draft'::public.note_status,
+  title text,
+  CONSTRAINT notes_pkey PRIMARY KEY (id)
+);
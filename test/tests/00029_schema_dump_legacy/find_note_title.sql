@@ -0,0 +1,8 @@
+-- :name FindNoteTitle :in sqlio.Id :out sqlio.Id
+SELECT
+  title AS id
+FROM
+  notes
+WHERE
+  id = :id
+;
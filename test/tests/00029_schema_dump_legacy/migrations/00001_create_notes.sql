@@ -0,0 +1,10 @@
+CREATE TYPE note_status AS ENUM ('draft', 'published');
+
+CREATE TABLE notes (
+  id TEXT PRIMARY KEY,
+  status note_status NOT NULL DEFAULT 'draft'
+);
+
+-- +goose Down
+DROP TABLE notes;
+DROP TYPE note_status;
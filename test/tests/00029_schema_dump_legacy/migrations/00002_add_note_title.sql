@@ -0,0 +1,4 @@
+ALTER TABLE notes ADD COLUMN title TEXT;
+
+-- +goose Down
+ALTER TABLE notes DROP COLUMN title;
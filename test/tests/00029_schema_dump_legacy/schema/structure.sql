@@ -0,0 +1,37 @@
+--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SET client_encoding = 'UTF8';
+SET standard_conforming_strings = on;
+SELECT pg_catalog.set_config('search_path', '', false);
+
+--
+-- Name: note_status; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.note_status AS ENUM (
+    'draft',
+    'published'
+);
+
+--
+-- Name: notes; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notes (
+    id text NOT NULL,
+    status public.note_status DEFAULT 'draft'::public.note_status NOT NULL
+);
+
+--
+-- Name: notes notes_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notes
+    ADD CONSTRAINT notes_pkey PRIMARY KEY (id);
+
+--
+-- PostgreSQL database dump complete
+--
@@ -0,0 +1,8 @@
+-- :name FindAccount :in sqlio.Id :out sqlio.Id
+SELECT
+  id
+FROM
+  accounts
+WHERE
+  id = :id
+;
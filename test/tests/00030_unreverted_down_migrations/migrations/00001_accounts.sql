@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE accounts (
+  id TEXT PRIMARY KEY,
+  name TEXT
+);
+
+-- +goose Down
+DROP TABLE accounts;
@@ -0,0 +1,7 @@
+-- +goose Up
+CREATE TYPE account_kind AS ENUM ('personal', 'business');
+ALTER TABLE accounts ADD COLUMN balance NUMERIC;
+ALTER TABLE accounts ALTER COLUMN name SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE accounts ALTER COLUMN name DROP NOT NULL;
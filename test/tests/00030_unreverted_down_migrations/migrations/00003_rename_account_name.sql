@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE accounts RENAME COLUMN name TO title;
+
+-- +goose Down
+ALTER TABLE accounts RENAME COLUMN label TO name;
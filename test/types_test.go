@@ -0,0 +1,282 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/koskimas/norsu/internal/pg"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestOperatorTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE nums (i2 int2 NOT NULL, i4 int4 NOT NULL, i8 int8, n numeric NOT NULL, f4 float4 NOT NULL);
+
+		CREATE VIEW v AS
+		SELECT
+		  i4 + 1 AS a,
+		  i2 * 2 AS b,
+		  n * 2.5 AS c,
+		  f4 + n AS d,
+		  f4 * 2 AS e,
+		  f4 + f4 AS f,
+		  i4 + 2147483648 AS g,
+		  i8 - i2 AS h,
+		  i4 ^ 2 AS i,
+		  1 / 2 AS j,
+		  i8 % i4 AS k,
+		  n % 2 AS l
+		FROM nums;
+	`)
+
+	assert.Equal(t, []string{
+		"a int4 not null",
+		"b int4 not null",
+		"c numeric not null",
+		"d float8 not null",
+		"e float8 not null",
+		"f float4 not null",
+		"g int8 not null",
+		"h int8",
+		"i float8 not null",
+		"j int4 not null",
+		"k int8",
+		"l numeric not null",
+	}, columns(t, db, "v"))
+}
+
+func TestConstantTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE nums (i4 int4 NOT NULL);
+
+		CREATE VIEW v AS
+		SELECT
+		  1 AS a,
+		  2.5 AS b,
+		  2147483648 AS c,
+		  (SELECT 1 AS one) AS d,
+		  1 + 1 AS e,
+		  2.5 * 2 AS f,
+		  -1 AS g,
+		  greatest(i4, 1) AS h
+		FROM nums;
+
+		CREATE VIEW s AS
+		SELECT 1 AS a, 2.5 AS b
+		UNION ALL
+		SELECT i4, 1 FROM nums;
+	`)
+
+	// Constants have the same types whether they are selected as is or
+	// matched with other types.
+	assert.Equal(t, []string{
+		"a int4 not null",
+		"b numeric not null",
+		"c int8 not null",
+		"d int4 not null",
+		"e int4 not null",
+		"f numeric not null",
+		"g int4 not null",
+		"h int4 not null",
+	}, columns(t, db, "v"))
+
+	assert.Equal(t, []string{"a int4 not null", "b numeric not null"}, columns(t, db, "s"))
+}
+
+func TestOperatorErrors(t *testing.T) {
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"SELECT name - 1 FROM t", `unsupported operator "-" for types "text" and "int4"`},
+		{"SELECT i4 # 1 FROM t", `unsupported operator "#" for types "int4" and "int4"`},
+		{"SELECT i4 % 1.5::float8 FROM t", `unsupported operator "%" for types "int4" and "float8"`},
+		{"SELECT -name FROM t", `unsupported prefix operator "-" for type "text"`},
+	}
+
+	for _, test := range tests {
+		db := migrate(t, "CREATE TABLE t (i4 int4 NOT NULL, name text);")
+		_, err := pg.ParseMigration(db, "CREATE VIEW v AS "+test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestBuiltinFunctionTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE t (name text NOT NULL, d date NOT NULL, ts timestamp NOT NULL, tz timestamptz NOT NULL, i2 int2 NOT NULL, i8 int8, n numeric NOT NULL);
+
+		CREATE VIEW v AS
+		SELECT
+		  substring(name from '[0-9]+') AS digits,
+		  substring(name, 1, 2) AS prefix,
+		  substr(name, 1, 2) AS substr_prefix,
+		  date_trunc('day', d) AS d_day,
+		  date_trunc('day', ts) AS ts_day,
+		  date_trunc('day', tz) AS tz_day,
+		  mod(i2, i2) AS i2_mod,
+		  mod(i2, 10) AS i4_mod,
+		  mod(10, i8) AS i8_mod,
+		  mod(i2, n) AS n_mod
+		FROM t;
+	`)
+
+	assert.Equal(t, []string{
+		"digits text",
+		"prefix text",
+		"substr_prefix text not null",
+		"d_day timestamptz not null",
+		"ts_day timestamp not null",
+		"tz_day timestamptz not null",
+		"i2_mod int2 not null",
+		"i4_mod int4 not null",
+		"i8_mod int8",
+		"n_mod numeric not null",
+	}, columns(t, db, "v"))
+}
+
+func TestBuiltinFunctionErrors(t *testing.T) {
+	db := migrate(t, "CREATE TABLE t (name text NOT NULL);")
+
+	_, err := pg.ParseMigration(db, "CREATE VIEW v AS SELECT no_such_function(name) FROM t")
+	assert.ErrorContains(t, err, `failed to parse function "no_such_function"`)
+
+	_, err = pg.ParseMigration(db, "CREATE VIEW v AS SELECT sum(name) FROM t")
+	assert.ErrorContains(t, err, `unsupported argument type "text" for function "sum"`)
+
+	_, err = pg.ParseMigration(db, "CREATE VIEW v AS SELECT mod(1, name) AS m FROM t")
+	assert.ErrorContains(t, err, `unsupported argument types "int4" and "text" for function "mod"`)
+}
+
+func TestSetOperationTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE t (i2 int2 NOT NULL, i4 int4 NOT NULL, i8 int8, n numeric NOT NULL, name text NOT NULL, code varchar(8));
+
+		CREATE VIEW literals AS
+		SELECT i4, n FROM t
+		INTERSECT
+		SELECT 1, 1.5;
+
+		CREATE VIEW mixed AS
+		SELECT i2 AS a, i4 AS b, name AS c, code AS d FROM t
+		UNION ALL
+		SELECT i4, i8, code, code FROM t;
+	`)
+
+	assert.Equal(t, []string{
+		"i4 int4 not null",
+		"n numeric not null",
+	}, columns(t, db, "literals"))
+
+	assert.Equal(t, []string{
+		"a int4 not null",
+		"b int8",
+		"c text",
+		"d varchar(8)",
+	}, columns(t, db, "mixed"))
+}
+
+func TestSetOperationErrors(t *testing.T) {
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"SELECT i4 FROM t UNION SELECT i4, name FROM t", "each UNION query must have the same number of columns"},
+		{"SELECT i4 FROM t EXCEPT SELECT name FROM t", `EXCEPT types "int4" and "text" of column "i4" cannot be matched`},
+	}
+
+	for _, test := range tests {
+		db := migrate(t, "CREATE TABLE t (i4 int4 NOT NULL, name text);")
+		_, err := pg.ParseMigration(db, "CREATE VIEW v AS "+test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestValuesTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE VIEW pairs AS VALUES (1, 'a');
+		CREATE VIEW numbers AS VALUES (1, NULL), (2.5, 3000000000);
+		CREATE VIEW named AS SELECT * FROM (VALUES (1, 'a'::varchar(4)), (2, 'bb'::varchar(4))) AS v(id, name);
+	`)
+
+	assert.Equal(t, []string{
+		"column1 int4 not null",
+		"column2 text not null",
+	}, columns(t, db, "pairs"))
+
+	assert.Equal(t, []string{
+		"column1 numeric not null",
+		"column2 int8",
+	}, columns(t, db, "numbers"))
+
+	assert.Equal(t, []string{
+		"id int4 not null",
+		"name varchar(4) not null",
+	}, columns(t, db, "named"))
+}
+
+func TestValuesErrors(t *testing.T) {
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"VALUES (1, 'a'), (2)", "VALUES lists must all be the same length"},
+		{"VALUES (1), ('a'::text)", `VALUES types "int4" and "text" of column "column1" cannot be matched`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(pg.NewDB(), "CREATE VIEW v AS "+test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}
+
+func TestUserFunctionTypes(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE people (id text NOT NULL, name text NOT NULL, age int);
+
+		CREATE FUNCTION current_tenant() RETURNS text AS $$ SELECT 'a' $$ LANGUAGE sql;
+		CREATE FUNCTION age_in(p people, years int DEFAULT 1) RETURNS int AS $$ SELECT p.age + years $$ LANGUAGE sql;
+		CREATE FUNCTION search_people(q text) RETURNS TABLE (id text, full_name text) AS $$ SELECT id, name FROM people $$ LANGUAGE sql;
+		CREATE FUNCTION search_people(q text, min_age int) RETURNS SETOF people AS $$ SELECT * FROM people $$ LANGUAGE sql;
+		CREATE FUNCTION names(OUT first text, OUT last text) AS $$ SELECT 'a', 'b' $$ LANGUAGE sql;
+
+		CREATE VIEW v AS
+		SELECT
+		  current_tenant() AS tenant,
+		  age_in(p) AS next_age,
+		  age_in(p, 10) AS later_age
+		FROM people p;
+
+		CREATE VIEW found AS SELECT s.id, s.full_name FROM search_people('a') s;
+		CREATE VIEW found_people AS SELECT s.* FROM search_people('a', 18) s;
+		CREATE VIEW split_names AS SELECT n.first, n.last FROM names() n;
+	`)
+
+	assert.Equal(t, []string{"tenant text", "next_age int4", "later_age int4"}, columns(t, db, "v"))
+	assert.Equal(t, []string{"id text", "full_name text"}, columns(t, db, "found"))
+	assert.Equal(t, []string{"id text", "name text", "age int4"}, columns(t, db, "found_people"))
+	assert.Equal(t, []string{"first text", "last text"}, columns(t, db, "split_names"))
+}
+
+func TestUserFunctionErrors(t *testing.T) {
+	db := migrate(t, `
+		CREATE TABLE people (id text NOT NULL);
+		CREATE FUNCTION search_people(q text) RETURNS TABLE (id text) AS $$ SELECT id FROM people $$ LANGUAGE sql;
+		CREATE FUNCTION anything() RETURNS SETOF record AS $$ SELECT 1 $$ LANGUAGE sql;
+	`)
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{"SELECT search_people() AS x", `failed to parse function "search_people"`},
+		{"SELECT search_people('a', 'b') AS x", `failed to parse function "search_people"`},
+		{"SELECT s.id FROM search_people() s", `unsupported range function "search_people"`},
+		{"SELECT s.missing FROM search_people('a') s", `failed to resolve column reference "s.missing"`},
+		{"SELECT a.x FROM anything() a", `function "public.anything" returns a record and needs column definitions`},
+	}
+
+	for _, test := range tests {
+		_, err := pg.ParseMigration(db.Clone(), "CREATE VIEW v AS "+test.sql)
+		assert.ErrorContains(t, err, test.err, test.sql)
+	}
+}